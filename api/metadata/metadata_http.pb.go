@@ -4,6 +4,8 @@ package metadata
 
 import (
 	context "context"
+	recovery "github.com/go-kratos/kratos/v2/middleware/recovery"
+	registry "github.com/go-kratos/kratos/v2/registry"
 	http1 "github.com/go-kratos/kratos/v2/transport/http"
 	binding "github.com/go-kratos/kratos/v2/transport/http/binding"
 	mux "github.com/gorilla/mux"
@@ -102,6 +104,21 @@ func NewMetadataHTTPClient(client *http1.Client) MetadataHTTPClient {
 	return &MetadataHTTPClientImpl{client}
 }
 
+// NewMetadataHTTPClientWithDiscovery returns the MetadataHTTPClient of the service name resolved by the discovery,
+// with the recovery middleware by default, the options, e.g. the middleware and the balancer, override the defaults.
+func NewMetadataHTTPClientWithDiscovery(ctx context.Context, d registry.Discovery, name string, opts ...http1.ClientOption) (MetadataHTTPClient, error) {
+	opts = append([]http1.ClientOption{
+		http1.WithEndpoint("discovery:///" + name),
+		http1.WithDiscovery(d),
+		http1.WithMiddleware(recovery.Recovery()),
+	}, opts...)
+	client, err := http1.NewClient(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return NewMetadataHTTPClient(client), nil
+}
+
 func (c *MetadataHTTPClientImpl) GetServiceDesc(ctx context.Context, in *GetServiceDescRequest, opts ...http1.CallOption) (out *GetServiceDescReply, err error) {
 	path := binding.EncodePath("GET", "/services/{name}", in)
 	out = &GetServiceDescReply{}
@@ -3,16 +3,19 @@ package kratos
 import (
 	"context"
 	"errors"
+	"fmt"
 	"os"
 	"os/signal"
 	"sync"
+	"sync/atomic"
 	"syscall"
+	"time"
 
+	"github.com/go-kratos/kratos/v2/id"
 	"github.com/go-kratos/kratos/v2/log"
 	"github.com/go-kratos/kratos/v2/registry"
 	"github.com/go-kratos/kratos/v2/transport"
 
-	"github.com/google/uuid"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -21,8 +24,10 @@ type App struct {
 	opts     options
 	ctx      context.Context
 	cancel   func()
+	mu       sync.Mutex
 	instance *registry.ServiceInstance
 	log      *log.Helper
+	ready    int32
 }
 
 // New create an application lifecycle manager.
@@ -31,13 +36,14 @@ func New(opts ...Option) *App {
 		ctx:    context.Background(),
 		logger: log.DefaultLogger,
 		sigs:   []os.Signal{syscall.SIGTERM, syscall.SIGQUIT, syscall.SIGINT},
-	}
-	if id, err := uuid.NewUUID(); err == nil {
-		options.id = id.String()
+		idGen:  id.UUID(),
 	}
 	for _, o := range opts {
 		o(&options)
 	}
+	if options.id == "" {
+		options.id = options.idGen.Generate()
+	}
 	ctx, cancel := context.WithCancel(options.ctx)
 	return &App{
 		ctx:    ctx,
@@ -54,6 +60,14 @@ func (a *App) Run() error {
 		"service_name", a.opts.name,
 		"service_version", a.opts.version,
 	)
+	// binds the listeners before starting the servers, so a bind failure starts none of them
+	for _, srv := range a.opts.servers {
+		if r, ok := srv.(transport.Endpointer); ok {
+			if _, err := r.Endpoint(); err != nil {
+				return &ServerError{Server: srv, Err: err}
+			}
+		}
+	}
 	instance, err := a.buildInstance()
 	if err != nil {
 		return err
@@ -74,15 +88,19 @@ func (a *App) Run() error {
 		wg.Add(1)
 		eg.Go(func() error {
 			wg.Done()
-			return srv.Start(ctx)
+			if err := srv.Start(ctx); err != nil {
+				// cancels the siblings by the errgroup
+				return &ServerError{Server: srv, Err: err}
+			}
+			return nil
 		})
 	}
 	wg.Wait()
-	if a.opts.registrar != nil {
-		if err := a.opts.registrar.Register(a.opts.ctx, instance); err != nil {
-			return err
-		}
-		a.instance = instance
+	if err := a.register(ctx, instance); err != nil {
+		// stops the started servers
+		a.cancel()
+		eg.Wait()
+		return err
 	}
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, a.opts.sigs...)
@@ -97,17 +115,40 @@ func (a *App) Run() error {
 		}
 	})
 	if err := eg.Wait(); err != nil && !errors.Is(err, context.Canceled) {
+		// a server failed after the instance is registered
+		if derr := a.deregister(); derr != nil {
+			a.log.Errorf("failed to deregister: %v", derr)
+		}
 		return err
 	}
 	return nil
 }
 
-// Stop gracefully stops the application.
-func (a *App) Stop() error {
-	if a.opts.registrar != nil && a.instance != nil {
-		if err := a.opts.registrar.Deregister(a.opts.ctx, a.instance); err != nil {
+// register registers the instance and marks the app ready, unless a server
+// failed to start, which cancels the ctx.
+func (a *App) register(ctx context.Context, instance *registry.ServiceInstance) error {
+	select {
+	case <-ctx.Done():
+		return nil
+	default:
+	}
+	if a.opts.registrar != nil {
+		if err := a.opts.registrar.Register(a.opts.ctx, instance); err != nil {
 			return err
 		}
+		a.mu.Lock()
+		a.instance = instance
+		a.mu.Unlock()
+	}
+	atomic.StoreInt32(&a.ready, 1)
+	a.summarize()
+	return nil
+}
+
+// Stop gracefully stops the application.
+func (a *App) Stop() error {
+	if err := a.deregister(); err != nil {
+		return err
 	}
 	if a.cancel != nil {
 		a.cancel()
@@ -115,6 +156,108 @@ func (a *App) Stop() error {
 	return nil
 }
 
+// Drain deregisters the service and drains the servers without stopping them,
+// then waits for the DrainWait or the ctx done, so the existing requests finish
+// while the new ones go to other instances, e.g. before a rolling update.
+func (a *App) Drain(ctx context.Context) error {
+	if err := a.deregister(); err != nil {
+		return err
+	}
+	for _, srv := range a.opts.servers {
+		if d, ok := srv.(transport.Drainer); ok {
+			if err := d.Drain(ctx); err != nil {
+				return &DrainError{Server: srv, Err: err}
+			}
+		}
+	}
+	if a.opts.drainWait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(a.opts.drainWait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Reregister registers the instance with the current endpoints of the servers, e.g. after
+// a server migrates to a new address, the registry replaces the instance of the same ID,
+// so the clients move to the new endpoints without a gap.
+func (a *App) Reregister(ctx context.Context) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.opts.registrar == nil || a.instance == nil {
+		return nil
+	}
+	instance, err := a.buildInstance()
+	if err != nil {
+		return err
+	}
+	if err := a.opts.registrar.Register(ctx, instance); err != nil {
+		return err
+	}
+	a.instance = instance
+	return nil
+}
+
+// Ready returns nil once the servers are started and the instance is registered,
+// and an error before it or once the app is stopped or drained, e.g. the readiness
+// check of the HTTP server.
+func (a *App) Ready(ctx context.Context) error {
+	if atomic.LoadInt32(&a.ready) == 0 {
+		return errors.New("app is not ready")
+	}
+	return nil
+}
+
+func (a *App) deregister() error {
+	atomic.StoreInt32(&a.ready, 0)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.opts.registrar == nil || a.instance == nil {
+		return nil
+	}
+	if err := a.opts.registrar.Deregister(a.opts.ctx, a.instance); err != nil {
+		return err
+	}
+	a.instance = nil
+	return nil
+}
+
+// summarize logs the servers described by themselves and the registry, and the warnings
+// of their self-checks, as the auditable record of the startup.
+func (a *App) summarize() {
+	registrar := "none"
+	if a.opts.registrar != nil {
+		registrar = fmt.Sprintf("%T", a.opts.registrar)
+	}
+	a.log.Infow(
+		"service_name", a.opts.name,
+		"service_version", a.opts.version,
+		"registry", registrar,
+	)
+	for _, srv := range a.opts.servers {
+		d, ok := srv.(transport.Describer)
+		if !ok {
+			a.log.Infow("server", fmt.Sprintf("%T", srv))
+			continue
+		}
+		desc := d.Describe()
+		a.log.Infow(
+			"server", desc.Kind,
+			"endpoint", desc.Endpoint,
+			"operations", desc.Operations,
+			"middlewares", desc.Middlewares,
+		)
+		for _, w := range desc.Warnings {
+			a.log.Warnw("server", desc.Kind, "endpoint", desc.Endpoint, "warning", w)
+		}
+	}
+}
+
 func (a *App) buildInstance() (*registry.ServiceInstance, error) {
 	var endpoints []string
 	for _, e := range a.opts.endpoints {
@@ -125,7 +268,7 @@ func (a *App) buildInstance() (*registry.ServiceInstance, error) {
 			if r, ok := srv.(transport.Endpointer); ok {
 				e, err := r.Endpoint()
 				if err != nil {
-					return nil, err
+					return nil, &ServerError{Server: srv, Err: err}
 				}
 				endpoints = append(endpoints, e.String())
 			}
@@ -140,3 +283,33 @@ func (a *App) buildInstance() (*registry.ServiceInstance, error) {
 		Endpoints: endpoints,
 	}, nil
 }
+
+// ServerError is the error of a server failed to start.
+type ServerError struct {
+	Server transport.Server
+	Err    error
+}
+
+func (e *ServerError) Error() string {
+	return fmt.Sprintf("kratos: server %T failed to start: %v", e.Server, e.Err)
+}
+
+// Unwrap returns the original error.
+func (e *ServerError) Unwrap() error {
+	return e.Err
+}
+
+// DrainError is the error of a server failed to drain.
+type DrainError struct {
+	Server transport.Server
+	Err    error
+}
+
+func (e *DrainError) Error() string {
+	return fmt.Sprintf("kratos: server %T failed to drain: %v", e.Server, e.Err)
+}
+
+// Unwrap returns the original error.
+func (e *DrainError) Unwrap() error {
+	return e.Err
+}
@@ -1,9 +1,14 @@
 package kratos
 
 import (
+	"context"
+	"errors"
+	"net"
+	"net/url"
 	"testing"
 	"time"
 
+	"github.com/go-kratos/kratos/v2/registry"
 	"github.com/go-kratos/kratos/v2/transport/grpc"
 	"github.com/go-kratos/kratos/v2/transport/http"
 )
@@ -23,3 +28,165 @@ func TestApp(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestAppServerError(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lis.Close()
+	hs := http.NewServer()
+	gs := grpc.NewServer(grpc.Address(lis.Addr().String()))
+	app := New(
+		Name("kratos"),
+		Version("v1.0.0"),
+		Server(hs, gs),
+	)
+	err = app.Run()
+	var se *ServerError
+	if !errors.As(err, &se) {
+		t.Fatalf("expected server error got %v", err)
+	}
+	if se.Server != gs {
+		t.Fatalf("expected the gRPC server got %T", se.Server)
+	}
+}
+
+type testServer struct {
+	err     error
+	stopped chan struct{}
+}
+
+func (s *testServer) Start(ctx context.Context) error {
+	if s.err != nil {
+		return s.err
+	}
+	<-s.stopped
+	return nil
+}
+
+func (s *testServer) Stop(ctx context.Context) error {
+	close(s.stopped)
+	return nil
+}
+
+func TestAppServerErrorStop(t *testing.T) {
+	first := &testServer{stopped: make(chan struct{})}
+	second := &testServer{err: errors.New("start"), stopped: make(chan struct{})}
+	app := New(
+		Name("kratos"),
+		Version("v1.0.0"),
+		Server(first, second),
+	)
+	err := app.Run()
+	var se *ServerError
+	if !errors.As(err, &se) || se.Server != second {
+		t.Fatalf("expected the second server error got %v", err)
+	}
+	select {
+	case <-first.stopped:
+	default:
+		t.Fatal("expected the first server to be stopped")
+	}
+}
+
+func TestAppServerErrorRegister(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lis.Close()
+	r := &testRegistrar{}
+	hs := http.NewServer()
+	gs := grpc.NewServer(grpc.Address(lis.Addr().String()))
+	app := New(
+		Name("kratos"),
+		Version("v1.0.0"),
+		Endpoint(&url.URL{Scheme: "grpc", Host: "10.0.0.1:9000"}),
+		Server(hs, gs),
+		Registrar(r),
+	)
+	err = app.Run()
+	var se *ServerError
+	if !errors.As(err, &se) || se.Server != gs {
+		t.Fatalf("expected the gRPC server error got %v", err)
+	}
+	if r.registered != 0 {
+		t.Fatalf("expected no registration got %d", r.registered)
+	}
+	if err := app.Ready(context.Background()); err == nil {
+		t.Fatal("expected not ready")
+	}
+}
+
+type testRegistrar struct {
+	registered   int
+	deregistered int
+}
+
+func (r *testRegistrar) Register(ctx context.Context, service *registry.ServiceInstance) error {
+	r.registered++
+	return nil
+}
+
+func (r *testRegistrar) Deregister(ctx context.Context, service *registry.ServiceInstance) error {
+	r.deregistered++
+	return nil
+}
+
+func TestAppDrain(t *testing.T) {
+	r := &testRegistrar{}
+	gs := grpc.NewServer()
+	app := New(
+		Name("kratos"),
+		Version("v1.0.0"),
+		Server(gs),
+		Registrar(r),
+		DrainWait(100*time.Millisecond),
+	)
+	if err := app.Ready(context.Background()); err == nil {
+		t.Fatal("expected not ready before run")
+	}
+	time.AfterFunc(time.Second, func() {
+		if err := app.Ready(context.Background()); err != nil {
+			t.Error(err)
+		}
+		if err := app.Drain(context.Background()); err != nil {
+			t.Error(err)
+		}
+		if err := app.Ready(context.Background()); err == nil {
+			t.Error("expected not ready after drain")
+		}
+		app.Stop()
+	})
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if r.deregistered != 1 {
+		t.Fatalf("expected deregistered once got %d", r.deregistered)
+	}
+}
+
+type testDrainServer struct {
+	err error
+}
+
+func (s *testDrainServer) Start(ctx context.Context) error { return nil }
+func (s *testDrainServer) Stop(ctx context.Context) error  { return nil }
+func (s *testDrainServer) Drain(ctx context.Context) error { return s.err }
+
+func TestAppDrainError(t *testing.T) {
+	srv := &testDrainServer{err: errors.New("drain")}
+	app := New(Name("kratos"), Server(srv))
+	err := app.Drain(context.Background())
+	var de *DrainError
+	if !errors.As(err, &de) {
+		t.Fatalf("expected drain error got %v", err)
+	}
+	if de.Server != srv || !errors.Is(err, srv.err) {
+		t.Fatalf("unexpected drain error %v", err)
+	}
+	if err.Error() != "kratos: server *kratos.testDrainServer failed to drain: drain" {
+		t.Fatalf("unexpected message %q", err.Error())
+	}
+}
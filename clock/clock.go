@@ -0,0 +1,27 @@
+// Package clock provides the clock abstraction of the time-based components,
+// e.g. the rate limiters and the caches, so they can be tested with the fake clock
+// deterministically instead of sleeping.
+package clock
+
+import "time"
+
+// Clock tells the time.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// New returns the real clock of the time package.
+func New() Clock {
+	return realClock{}
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
@@ -0,0 +1,66 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+var _ Clock = (*Fake)(nil)
+
+// Fake is a fake clock for tests, which only moves by Advance and Set.
+type Fake struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []waiter
+}
+
+type waiter struct {
+	deadline time.Time
+	ch       chan time.Time
+}
+
+// NewFake new a fake clock at the unix epoch.
+func NewFake() *Fake {
+	return &Fake{now: time.Unix(0, 0)}
+}
+
+// Now returns the time of the fake clock.
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// After returns a channel which receives the time once the fake clock is advanced by d.
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- f.now
+		return ch
+	}
+	f.waiters = append(f.waiters, waiter{deadline: f.now.Add(d), ch: ch})
+	return ch
+}
+
+// Advance moves the fake clock forward by d, and fires the expired After channels.
+func (f *Fake) Advance(d time.Duration) {
+	f.Set(f.Now().Add(d))
+}
+
+// Set sets the time of the fake clock, and fires the expired After channels.
+func (f *Fake) Set(t time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = t
+	waiters := f.waiters[:0]
+	for _, w := range f.waiters {
+		if w.deadline.After(t) {
+			waiters = append(waiters, w)
+			continue
+		}
+		w.ch <- t
+	}
+	f.waiters = waiters
+}
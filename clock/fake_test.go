@@ -0,0 +1,30 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFake(t *testing.T) {
+	f := NewFake()
+	start := f.Now()
+	ch := f.After(time.Second)
+	f.Advance(500 * time.Millisecond)
+	select {
+	case <-ch:
+		t.Fatal("expected not fired before the deadline")
+	default:
+	}
+	f.Advance(500 * time.Millisecond)
+	select {
+	case now := <-ch:
+		if now.Sub(start) != time.Second {
+			t.Fatalf("expected fired at 1s got %s", now.Sub(start))
+		}
+	default:
+		t.Fatal("expected fired at the deadline")
+	}
+	if f.Now().Sub(start) != time.Second {
+		t.Fatalf("expected advanced by 1s got %s", f.Now().Sub(start))
+	}
+}
@@ -0,0 +1,145 @@
+package base
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// ConfigName is the name of the project config file.
+const ConfigName = ".kratos.yaml"
+
+// Config is the project config of the kratos tool.
+type Config struct {
+	Proto ProtoConfig `yaml:"proto"`
+}
+
+// ProtoConfig is the config of the proto generation.
+type ProtoConfig struct {
+	// Paths are the extra proto include paths.
+	Paths []string `yaml:"paths"`
+	// Output is the output directory of the generated code.
+	Output string `yaml:"output"`
+	// Options are the plugin options, e.g. go-http: omitempty=false
+	Options map[string]string `yaml:"options"`
+	// Args are the extra protoc arguments.
+	Args []string `yaml:"args"`
+}
+
+// LoadConfig loads the project config specified by the --config argument,
+// or looks up the .kratos.yaml from the current directory to its parents.
+// It returns the remaining arguments without the --config argument.
+func LoadConfig(args []string) (*Config, []string, error) {
+	var (
+		path string
+		rest = make([]string, 0, len(args))
+	)
+	for i := 0; i < len(args); i++ {
+		switch a := args[i]; {
+		case strings.HasPrefix(a, "--config="):
+			path = strings.TrimPrefix(a, "--config=")
+		case a == "--config" && i+1 < len(args):
+			path = args[i+1]
+			i++
+		default:
+			rest = append(rest, a)
+		}
+	}
+	if path == "" {
+		path = lookupConfig()
+	}
+	conf := new(Config)
+	if path == "" {
+		return conf, rest, nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := yaml.Unmarshal(data, conf); err != nil {
+		return nil, nil, err
+	}
+	// the paths are relative to the config file
+	dir, err := filepath.Abs(filepath.Dir(path))
+	if err != nil {
+		return nil, nil, err
+	}
+	for i, p := range conf.Proto.Paths {
+		conf.Proto.Paths[i] = absPath(dir, p)
+	}
+	if conf.Proto.Output != "" {
+		conf.Proto.Output = absPath(dir, conf.Proto.Output)
+	}
+	return conf, rest, nil
+}
+
+// ProtocArgs returns the protoc arguments of the plugins merged with the config and
+// the command line arguments, the command line arguments win over the config.
+func (c *ProtoConfig) ProtocArgs(plugins []string, args []string) []string {
+	output := "."
+	if c.Output != "" {
+		output = c.Output
+	}
+	input := []string{"--proto_path=."}
+	for _, p := range c.Paths {
+		input = append(input, "--proto_path="+p)
+	}
+	input = append(input, "--proto_path="+filepath.Join(KratosMod(), "third_party"))
+	for _, p := range plugins {
+		input = append(input, "--"+p+"_out=paths=source_relative:"+output)
+		if opt, ok := c.Options[p]; ok {
+			input = append(input, "--"+p+"_opt="+opt)
+		}
+	}
+	input = append(input, c.Args...)
+	for _, a := range args {
+		if !strings.HasPrefix(a, "-") {
+			continue
+		}
+		name := strings.SplitN(a, "=", 2)[0]
+		if name != "--proto_path" && name != "-I" {
+			// the flag from the command line overrides the one from the config
+			input = removeFlag(input, name)
+		}
+		input = append(input, a)
+	}
+	return input
+}
+
+func lookupConfig() string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	for {
+		path := filepath.Join(dir, ConfigName)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+func absPath(dir, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
+
+func removeFlag(args []string, name string) []string {
+	res := args[:0]
+	for _, a := range args {
+		if strings.SplitN(a, "=", 2)[0] != name {
+			res = append(res, a)
+		}
+	}
+	return res
+}
@@ -0,0 +1,25 @@
+package base
+
+import "testing"
+
+func TestProtocArgs(t *testing.T) {
+	conf, args, err := LoadConfig([]string{"--config", "testdata/.kratos.yaml", "api/hello.proto", "--go-http_opt=omitempty=true"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(args) != 2 || args[0] != "api/hello.proto" {
+		t.Fatalf("unexpected args: %v", args)
+	}
+	var opts []string
+	for _, a := range conf.Proto.ProtocArgs([]string{"go", "go-http"}, args) {
+		if a == "--go-http_opt=omitempty=false" {
+			t.Errorf("expected the config option to be overridden")
+		}
+		if a == "--go-http_opt=omitempty=true" {
+			opts = append(opts, a)
+		}
+	}
+	if len(opts) != 1 {
+		t.Errorf("expected the command line option got %v", opts)
+	}
+}
@@ -110,3 +110,19 @@ func Tree(path string, dir string) {
 		return nil
 	})
 }
+
+// WalkProto walks the proto files in the directory, calling fn for each proto file.
+func WalkProto(dir string, fn func(path string) error) error {
+	if dir == "" {
+		dir = "."
+	}
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if ext := filepath.Ext(path); ext != ".proto" {
+			return nil
+		}
+		return fn(path)
+	})
+}
@@ -0,0 +1,33 @@
+package base
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWalkProto(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "v1"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a.proto", "b.txt", "v1/c.proto"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var protos []string
+	if err := WalkProto(dir, func(path string) error {
+		protos = append(protos, path)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(protos) != 2 {
+		t.Fatalf("expected 2 protos got %v", protos)
+	}
+	if err := WalkProto(filepath.Join(dir, "not-found"), func(string) error { return nil }); err == nil {
+		t.Fatal("expected the error of the unreadable directory")
+	}
+}
@@ -0,0 +1,34 @@
+package base
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// LookPlugins returns the error of the first plugin not found in the PATH.
+func LookPlugins(name ...string) error {
+	for _, n := range name {
+		if _, err := exec.LookPath(n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Protoc runs protoc with the plugins for the proto file in its directory.
+func Protoc(proto string, plugins []string, conf *Config, args []string) error {
+	path, name := filepath.Split(proto)
+	input := conf.Proto.ProtocArgs(plugins, args)
+	input = append(input, name)
+	fd := exec.Command("protoc", input...)
+	fd.Stdout = os.Stdout
+	fd.Stderr = os.Stderr
+	fd.Dir = path
+	if err := fd.Run(); err != nil {
+		return err
+	}
+	fmt.Printf("proto: %s\n", proto)
+	return nil
+}
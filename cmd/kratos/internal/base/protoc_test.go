@@ -0,0 +1,12 @@
+package base
+
+import "testing"
+
+func TestLookPlugins(t *testing.T) {
+	if err := LookPlugins("go"); err != nil {
+		t.Fatal(err)
+	}
+	if err := LookPlugins("go", "protoc-gen-not-found"); err == nil {
+		t.Fatal("expected the error of the missing plugin")
+	}
+}
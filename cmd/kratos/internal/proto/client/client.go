@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
-	"path/filepath"
 	"strings"
 
 	"github.com/go-kratos/kratos/cmd/kratos/v2/internal/base"
@@ -24,15 +23,17 @@ var (
 )
 
 func run(cmd *cobra.Command, args []string) {
+	conf, args, err := base.LoadConfig(args)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
 	if len(args) == 0 {
 		fmt.Println("Please enter the proto file or directory")
 		return
 	}
-	var (
-		err   error
-		proto = strings.TrimSpace(args[0])
-	)
-	if err = look("protoc-gen-go", "protoc-gen-go-grpc", "protoc-gen-go-http"); err != nil {
+	proto := strings.TrimSpace(args[0])
+	if err = base.LookPlugins("protoc-gen-go", "protoc-gen-go-grpc", "protoc-gen-go-http"); err != nil {
 		// update the kratos plugins
 		cmd := exec.Command("kratos", "upgrade")
 		cmd.Stdout = os.Stdout
@@ -43,59 +44,21 @@ func run(cmd *cobra.Command, args []string) {
 		}
 	}
 	if strings.HasSuffix(proto, ".proto") {
-		err = generate(proto, args)
+		err = generate(proto, conf, args)
 	} else {
-		err = walk(proto, args)
+		err = walk(proto, conf, args)
 	}
 	if err != nil {
 		fmt.Println(err)
 	}
 }
 
-func look(name ...string) error {
-	for _, n := range name {
-		if _, err := exec.LookPath(n); err != nil {
-			return err
-		}
-	}
-	return nil
-}
-
-func walk(dir string, args []string) error {
-	if dir == "" {
-		dir = "."
-	}
-	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
-		if ext := filepath.Ext(path); ext != ".proto" {
-			return nil
-		}
-		return generate(path, args)
+func walk(dir string, conf *base.Config, args []string) error {
+	return base.WalkProto(dir, func(path string) error {
+		return generate(path, conf, args)
 	})
 }
 
-// generate is used to execute the generate command for the specified proto file
-func generate(proto string, args []string) error {
-	path, name := filepath.Split(proto)
-	input := []string{
-		"--proto_path=.",
-		"--proto_path=" + filepath.Join(base.KratosMod(), "third_party"),
-		"--go_out=paths=source_relative:.",
-		"--go-grpc_out=paths=source_relative:.",
-		"--go-http_out=paths=source_relative:.",
-		name,
-	}
-	for _, a := range args {
-		if strings.HasPrefix(a, "-") {
-			input = append(input, a)
-		}
-	}
-	fd := exec.Command("protoc", input...)
-	fd.Stdout = os.Stdout
-	fd.Stderr = os.Stderr
-	fd.Dir = path
-	if err := fd.Run(); err != nil {
-		return err
-	}
-	fmt.Printf("proto: %s\n", proto)
-	return nil
+func generate(proto string, conf *base.Config, args []string) error {
+	return base.Protoc(proto, []string{"go", "go-grpc", "go-http"}, conf, args)
 }
@@ -0,0 +1,65 @@
+package errors
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/go-kratos/kratos/cmd/kratos/v2/internal/base"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	// CmdError represents the error command.
+	CmdError = &cobra.Command{
+		Use:                "error",
+		Short:              "Generate the proto error code",
+		Long:               "Generate the proto error code. Example: kratos proto error helloworld.proto",
+		DisableFlagParsing: true,
+		Run:                run,
+	}
+)
+
+func run(cmd *cobra.Command, args []string) {
+	conf, args, err := base.LoadConfig(args)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if len(args) == 0 {
+		fmt.Println("Please enter the proto file or directory")
+		return
+	}
+	proto := strings.TrimSpace(args[0])
+	if err = base.LookPlugins("protoc-gen-go"); err != nil {
+		// update the kratos plugins
+		cmd := exec.Command("kratos", "upgrade")
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		if err = cmd.Run(); err != nil {
+			fmt.Println(err)
+			return
+		}
+	}
+	// the errors plugin is not a module of kratos yet, so it is not installed by the upgrade
+	if err = base.LookPlugins("protoc-gen-go-errors"); err != nil {
+		fmt.Printf("Please install the protoc-gen-go-errors plugin: %v\n", err)
+		return
+	}
+	if strings.HasSuffix(proto, ".proto") {
+		err = generate(proto, conf, args)
+	} else {
+		err = base.WalkProto(proto, func(path string) error {
+			return generate(path, conf, args)
+		})
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+}
+
+func generate(proto string, conf *base.Config, args []string) error {
+	return base.Protoc(proto, []string{"go", "go-errors"}, conf, args)
+}
@@ -0,0 +1,189 @@
+package lint
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/go-kratos/kratos/cmd/kratos/v2/internal/base"
+
+	"github.com/emicklei/proto"
+	"github.com/spf13/cobra"
+)
+
+// CmdLint represents the lint command.
+var CmdLint = &cobra.Command{
+	Use:   "lint",
+	Short: "Lint the proto files against Kratos conventions",
+	Long:  "Lint the proto files against Kratos conventions. Example: kratos proto lint api",
+	Run:   run,
+}
+
+// Level is the level of the lint issue.
+type Level string
+
+const (
+	// LevelWarning is a non-fatal issue.
+	LevelWarning Level = "warning"
+	// LevelError is a fatal issue.
+	LevelError Level = "error"
+)
+
+// Issue is a lint issue found in the proto file.
+type Issue struct {
+	File    string
+	Line    int
+	Level   Level
+	Message string
+}
+
+func (i *Issue) String() string {
+	return fmt.Sprintf("%s:%d: %s: %s", i.File, i.Line, i.Level, i.Message)
+}
+
+var (
+	camelCase = regexp.MustCompile(`^[A-Z][a-zA-Z0-9]*$`)
+	snakeCase = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+	upperCase = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+	versioned = regexp.MustCompile(`^v[0-9]+((alpha|beta)[0-9]*)?$`)
+)
+
+func run(cmd *cobra.Command, args []string) {
+	var dir string
+	if len(args) > 0 {
+		dir = strings.TrimSpace(args[0])
+	}
+	var issues []*Issue
+	lint := func(path string) error {
+		res, err := Lint(path)
+		if err != nil {
+			return err
+		}
+		issues = append(issues, res...)
+		return nil
+	}
+	var err error
+	if strings.HasSuffix(dir, ".proto") {
+		err = lint(dir)
+	} else {
+		err = base.WalkProto(dir, lint)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	var failed bool
+	for _, i := range issues {
+		if i.Level == LevelError {
+			failed = true
+		}
+		fmt.Fprintln(os.Stderr, i)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// Lint checks the proto file and returns the issues found.
+func Lint(path string) ([]*Issue, error) {
+	reader, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	definition, err := proto.NewParser(reader).Parse()
+	if err != nil {
+		return nil, err
+	}
+	var (
+		issues    []*Issue
+		goPackage bool
+		pkg       *proto.Package
+	)
+	report := func(line int, level Level, format string, a ...interface{}) {
+		issues = append(issues, &Issue{
+			File:    path,
+			Line:    line,
+			Level:   level,
+			Message: fmt.Sprintf(format, a...),
+		})
+	}
+	proto.Walk(definition, func(v proto.Visitee) {
+		switch e := v.(type) {
+		case *proto.Package:
+			pkg = e
+		case *proto.Option:
+			if e.Name == "go_package" && e.Constant.Source != "" {
+				goPackage = true
+			}
+		case *proto.Service:
+			if !camelCase.MatchString(e.Name) {
+				report(e.Position.Line, LevelWarning, "service %q should be CamelCase", e.Name)
+			}
+		case *proto.RPC:
+			if !camelCase.MatchString(e.Name) {
+				report(e.Position.Line, LevelWarning, "rpc %q should be CamelCase", e.Name)
+			}
+			if !hasHTTPRule(e) {
+				report(e.Position.Line, LevelWarning, "rpc %q has no google.api.http annotation", e.Name)
+			}
+		case *proto.Message:
+			if !camelCase.MatchString(e.Name) {
+				report(e.Position.Line, LevelWarning, "message %q should be CamelCase", e.Name)
+			}
+		case *proto.Enum:
+			if !camelCase.MatchString(e.Name) {
+				report(e.Position.Line, LevelWarning, "enum %q should be CamelCase", e.Name)
+			}
+		case *proto.EnumField:
+			if !upperCase.MatchString(e.Name) {
+				report(e.Position.Line, LevelWarning, "enum value %q should be UPPER_SNAKE_CASE", e.Name)
+			}
+		case *proto.NormalField:
+			lintField(e.Field, report)
+		case *proto.MapField:
+			lintField(e.Field, report)
+		case *proto.OneOfField:
+			lintField(e.Field, report)
+		}
+	})
+	if !goPackage {
+		report(1, LevelError, "missing option go_package")
+	}
+	if pkg == nil {
+		report(1, LevelError, "missing package declaration")
+	} else {
+		lintPackage(path, pkg, report)
+	}
+	return issues, nil
+}
+
+func lintField(f *proto.Field, report func(int, Level, string, ...interface{})) {
+	if !snakeCase.MatchString(f.Name) {
+		report(f.Position.Line, LevelWarning, "field %q should be lower_snake_case", f.Name)
+	}
+}
+
+func lintPackage(path string, pkg *proto.Package, report func(int, Level, string, ...interface{})) {
+	parts := strings.Split(pkg.Name, ".")
+	if !versioned.MatchString(parts[len(parts)-1]) {
+		report(pkg.Position.Line, LevelWarning, "package %q should end with a version, e.g. %s.v1", pkg.Name, pkg.Name)
+		return
+	}
+	dir := filepath.ToSlash(filepath.Dir(path))
+	if !strings.HasSuffix(dir, strings.Join(parts, "/")) {
+		report(pkg.Position.Line, LevelWarning, "package %q does not match the directory %q", pkg.Name, dir)
+	}
+}
+
+func hasHTTPRule(rpc *proto.RPC) bool {
+	for _, e := range rpc.Elements {
+		if o, ok := e.(*proto.Option); ok && o.Name == "(google.api.http)" {
+			return true
+		}
+	}
+	return false
+}
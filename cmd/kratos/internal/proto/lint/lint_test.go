@@ -0,0 +1,59 @@
+package lint
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testProto = `syntax = "proto3";
+
+package helloworld;
+
+service greeter {
+  rpc SayHello (HelloRequest) returns (HelloReply);
+}
+
+message HelloRequest {
+  string userName = 1;
+}
+
+message HelloReply {
+  string message = 1;
+}
+`
+
+func TestLint(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "helloworld.proto")
+	if err := ioutil.WriteFile(path, []byte(testProto), 0644); err != nil {
+		t.Fatal(err)
+	}
+	issues, err := Lint(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var errs, warns int
+	for _, i := range issues {
+		t.Log(i)
+		switch i.Level {
+		case LevelError:
+			errs++
+		case LevelWarning:
+			warns++
+		}
+	}
+	// missing go_package
+	if errs != 1 {
+		t.Errorf("expected 1 error got %d", errs)
+	}
+	// service name, http annotation, field name and package version
+	if warns != 4 {
+		t.Errorf("expected 4 warnings got %d", warns)
+	}
+}
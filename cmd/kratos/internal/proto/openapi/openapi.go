@@ -0,0 +1,100 @@
+package openapi
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/go-kratos/kratos/cmd/kratos/v2/internal/base"
+
+	"github.com/spf13/cobra"
+)
+
+// CmdOpenAPI represents the openapi command.
+var CmdOpenAPI = &cobra.Command{
+	Use:                "openapi",
+	Short:              "Generate the OpenAPI v3 spec of the protos",
+	Long:               "Generate the OpenAPI v3 spec merged into openapi.yaml of the protos. Example: kratos proto openapi api --output=docs",
+	DisableFlagParsing: true,
+	Run:                run,
+}
+
+func run(cmd *cobra.Command, args []string) {
+	conf, args, err := base.LoadConfig(args)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	var (
+		protos []string
+		flags  []string
+	)
+	for i := 0; i < len(args); i++ {
+		switch a := args[i]; {
+		case strings.HasPrefix(a, "--output="):
+			conf.Proto.Output = strings.TrimPrefix(a, "--output=")
+		case (a == "--output" || a == "-o") && i+1 < len(args):
+			conf.Proto.Output = args[i+1]
+			i++
+		case strings.HasPrefix(a, "-"):
+			flags = append(flags, a)
+		default:
+			protos = append(protos, strings.TrimSpace(a))
+		}
+	}
+	if len(protos) == 0 {
+		fmt.Println("Please enter the proto files or directories")
+		return
+	}
+	if _, err = exec.LookPath("protoc-gen-openapi"); err != nil {
+		// update the kratos plugins
+		cmd := exec.Command("kratos", "upgrade")
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		if err = cmd.Run(); err != nil {
+			fmt.Println(err)
+			return
+		}
+	}
+	var files []string
+	for _, p := range protos {
+		if strings.HasSuffix(p, ".proto") {
+			files = append(files, p)
+			continue
+		}
+		if err = base.WalkProto(p, func(path string) error {
+			files = append(files, path)
+			return nil
+		}); err != nil {
+			fmt.Println(err)
+			return
+		}
+	}
+	if err = generate(files, conf, flags); err != nil {
+		fmt.Println(err)
+	}
+}
+
+// generate runs the plugin once over all the protos, so the spec is merged into one openapi.yaml.
+func generate(files []string, conf *base.Config, args []string) error {
+	if conf.Proto.Output != "" {
+		if err := os.MkdirAll(conf.Proto.Output, 0755); err != nil {
+			return err
+		}
+	}
+	input := conf.Proto.ProtocArgs([]string{"openapi"}, args)
+	input = append(input, files...)
+	fd := exec.Command("protoc", input...)
+	fd.Stdout = os.Stdout
+	fd.Stderr = os.Stderr
+	if err := fd.Run(); err != nil {
+		return err
+	}
+	output := conf.Proto.Output
+	if output == "" {
+		output = "."
+	}
+	fmt.Printf("openapi: %s/openapi.yaml\n", strings.TrimSuffix(output, "/"))
+	return nil
+}
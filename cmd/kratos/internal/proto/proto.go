@@ -3,6 +3,9 @@ package proto
 import (
 	"github.com/go-kratos/kratos/cmd/kratos/v2/internal/proto/add"
 	"github.com/go-kratos/kratos/cmd/kratos/v2/internal/proto/client"
+	"github.com/go-kratos/kratos/cmd/kratos/v2/internal/proto/errors"
+	"github.com/go-kratos/kratos/cmd/kratos/v2/internal/proto/lint"
+	"github.com/go-kratos/kratos/cmd/kratos/v2/internal/proto/openapi"
 	"github.com/go-kratos/kratos/cmd/kratos/v2/internal/proto/server"
 
 	"github.com/spf13/cobra"
@@ -19,6 +22,9 @@ var CmdProto = &cobra.Command{
 func init() {
 	CmdProto.AddCommand(add.CmdAdd)
 	CmdProto.AddCommand(client.CmdClient)
+	CmdProto.AddCommand(errors.CmdError)
+	CmdProto.AddCommand(lint.CmdLint)
+	CmdProto.AddCommand(openapi.CmdOpenAPI)
 	CmdProto.AddCommand(server.CmdServer)
 }
 
@@ -23,6 +23,7 @@ func Run(cmd *cobra.Command, args []string) {
 		"github.com/go-kratos/kratos/cmd/protoc-gen-go-http/v2",
 		"google.golang.org/protobuf/cmd/protoc-gen-go",
 		"google.golang.org/grpc/cmd/protoc-gen-go-grpc",
+		"github.com/google/gnostic/cmd/protoc-gen-openapi",
 	)
 	if err != nil {
 		fmt.Println(err)
@@ -11,13 +11,28 @@ import (
 )
 
 const (
-	contextPackage   = protogen.GoImportPath("context")
-	httpPackage      = protogen.GoImportPath("net/http")
-	muxPackage       = protogen.GoImportPath("github.com/gorilla/mux")
-	transportPackage = protogen.GoImportPath("github.com/go-kratos/kratos/v2/transport/http")
-	bindingPackage   = protogen.GoImportPath("github.com/go-kratos/kratos/v2/transport/http/binding")
+	contextPackage       = protogen.GoImportPath("context")
+	httpPackage          = protogen.GoImportPath("net/http")
+	muxPackage           = protogen.GoImportPath("github.com/gorilla/mux")
+	grpcPackage          = protogen.GoImportPath("google.golang.org/grpc")
+	registryPackage      = protogen.GoImportPath("github.com/go-kratos/kratos/v2/registry")
+	recoveryPackage      = protogen.GoImportPath("github.com/go-kratos/kratos/v2/middleware/recovery")
+	transportPackage     = protogen.GoImportPath("github.com/go-kratos/kratos/v2/transport/http")
+	grpcTransportPackage = protogen.GoImportPath("github.com/go-kratos/kratos/v2/transport/grpc")
+	bindingPackage       = protogen.GoImportPath("github.com/go-kratos/kratos/v2/transport/http/binding")
 )
 
+// templatePackages are the packages of the qualified idents in the template, e.g. registry.Discovery,
+// which are imported only if the generated code uses them.
+var templatePackages = map[string]protogen.GoImportPath{
+	"context":   contextPackage,
+	"grpc":      grpcPackage,
+	"registry":  registryPackage,
+	"recovery":  recoveryPackage,
+	"transhttp": transportPackage,
+	"transgrpc": grpcTransportPackage,
+}
+
 var methodSets = make(map[string]int)
 
 // generateFile generates a _http.pb.go file containing kratos errors definitions.
@@ -64,6 +79,7 @@ func genService(gen *protogen.Plugin, file *protogen.File, g *protogen.Generated
 		ServiceType: service.GoName,
 		ServiceName: string(service.Desc.FullName()),
 		Metadata:    file.Desc.Path(),
+		GRPCClient:  grpcClient,
 	}
 	for _, method := range service.Methods {
 		if method.Desc.IsStreamingClient() || method.Desc.IsStreamingServer() {
@@ -80,7 +96,23 @@ func genService(gen *protogen.Plugin, file *protogen.File, g *protogen.Generated
 			sd.Methods = append(sd.Methods, buildMethodDesc(g, method, "POST", path))
 		}
 	}
-	g.P(sd.execute())
+	g.P(sd.execute(g))
+}
+
+// qualify returns the template func which qualifies the ident of the template packages,
+// e.g. registry.Discovery, and imports the package into the generated file.
+func qualify(g *protogen.GeneratedFile) func(string) string {
+	return func(ident string) string {
+		i := strings.LastIndex(ident, ".")
+		if i < 0 {
+			panic(fmt.Sprintf("unqualified ident %s in the template", ident))
+		}
+		pkg, ok := templatePackages[ident[:i]]
+		if !ok {
+			panic(fmt.Sprintf("unknown package of the ident %s in the template", ident))
+		}
+		return g.QualifiedGoIdent(pkg.Ident(ident[i+1:]))
+	}
 }
 
 func buildHTTPRule(g *protogen.GeneratedFile, m *protogen.Method, rule *annotations.HttpRule) *methodDesc {
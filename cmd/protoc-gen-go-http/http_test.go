@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+// helloworld returns the request of the helloworld.proto of the examples.
+func helloworld() *pluginpb.CodeGeneratorRequest {
+	options := &descriptorpb.MethodOptions{}
+	proto.SetExtension(options, annotations.E_Http, &annotations.HttpRule{
+		Pattern: &annotations.HttpRule_Get{Get: "/helloworld/{name}"},
+	})
+	message := func(name, field string) *descriptorpb.DescriptorProto {
+		return &descriptorpb.DescriptorProto{
+			Name: proto.String(name),
+			Field: []*descriptorpb.FieldDescriptorProto{{
+				Name:     proto.String(field),
+				JsonName: proto.String(field),
+				Number:   proto.Int32(1),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			}},
+		}
+	}
+	file := &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("helloworld/helloworld.proto"),
+		Package:    proto.String("helloworld"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"google/api/annotations.proto"},
+		Options: &descriptorpb.FileOptions{
+			GoPackage: proto.String("github.com/go-kratos/kratos/examples/helloworld/helloworld"),
+		},
+		MessageType: []*descriptorpb.DescriptorProto{
+			message("HelloRequest", "name"),
+			message("HelloReply", "message"),
+		},
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name: proto.String("Greeter"),
+			Method: []*descriptorpb.MethodDescriptorProto{{
+				Name:       proto.String("SayHello"),
+				InputType:  proto.String(".helloworld.HelloRequest"),
+				OutputType: proto.String(".helloworld.HelloReply"),
+				Options:    options,
+			}},
+		}},
+	}
+	return &pluginpb.CodeGeneratorRequest{
+		FileToGenerate: []string{file.GetName()},
+		ProtoFile: []*descriptorpb.FileDescriptorProto{
+			protodesc.ToFileDescriptorProto(descriptorpb.File_google_protobuf_descriptor_proto),
+			protodesc.ToFileDescriptorProto(annotations.File_google_api_http_proto),
+			protodesc.ToFileDescriptorProto(annotations.File_google_api_annotations_proto),
+			file,
+		},
+	}
+}
+
+func generate(t *testing.T, req *pluginpb.CodeGeneratorRequest) []byte {
+	gen, err := protogen.Options{}.New(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range gen.Files {
+		if f.Generate {
+			generateFile(gen, f)
+		}
+	}
+	res := gen.Response()
+	if res.Error != nil {
+		t.Fatal(res.GetError())
+	}
+	if len(res.File) != 1 {
+		t.Fatalf("expected one generated file got %d", len(res.File))
+	}
+	return []byte(res.File[0].GetContent())
+}
+
+func TestGenerateFile(t *testing.T) {
+	grpcClient = true
+	defer func() { grpcClient = false }()
+	content := generate(t, helloworld())
+	golden := filepath.Join("testdata", "helloworld_http.pb.go.golden")
+	if *update {
+		if err := ioutil.WriteFile(golden, content, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expected, err := ioutil.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(content, expected) {
+		t.Fatalf("the generated file differs from %s, run go test -update to update it:\n%s", golden, content)
+	}
+}
+
+func TestGenerateFileWithoutGRPCClient(t *testing.T) {
+	content := string(generate(t, helloworld()))
+	if !strings.Contains(content, "func NewGreeterHTTPClientWithDiscovery(") {
+		t.Error("expected the HTTP client with discovery")
+	}
+	if strings.Contains(content, "func NewGreeterClientWithDiscovery(") {
+		t.Error("expected no gRPC client with discovery")
+	}
+	for _, pkg := range []string{`"google.golang.org/grpc"`, `"github.com/go-kratos/kratos/v2/transport/grpc"`} {
+		if strings.Contains(content, pkg) {
+			t.Errorf("expected no import of %s", pkg)
+		}
+	}
+}
@@ -10,6 +10,9 @@ import (
 
 const version = "v2.0.0-rc1"
 
+// grpcClient generates the gRPC clients with discovery, which requires the protoc-gen-go-grpc code.
+var grpcClient bool
+
 func main() {
 	showVersion := flag.Bool("version", false, "print the version and exit")
 	flag.Parse()
@@ -19,6 +22,7 @@ func main() {
 	}
 
 	var flags flag.FlagSet
+	flags.BoolVar(&grpcClient, "grpc_client", false, "generate the gRPC clients with discovery")
 
 	protogen.Options{
 		ParamFunc: flags.Set,
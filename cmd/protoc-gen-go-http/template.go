@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"strings"
 	"text/template"
+
+	"google.golang.org/protobuf/compiler/protogen"
 )
 
 var httpTemplate = `
@@ -65,7 +67,38 @@ type {{.ServiceType}}HTTPClientImpl struct{
 func New{{.ServiceType}}HTTPClient (client *http1.Client) {{.ServiceType}}HTTPClient {
 	return &{{.ServiceType}}HTTPClientImpl{client}
 }
-	
+
+// New{{.ServiceType}}HTTPClientWithDiscovery returns the {{.ServiceType}}HTTPClient of the service name resolved by the discovery,
+// with the recovery middleware by default, the options, e.g. the middleware and the balancer, override the defaults.
+func New{{.ServiceType}}HTTPClientWithDiscovery(ctx {{ident "context.Context"}}, d {{ident "registry.Discovery"}}, name string, opts ...{{ident "transhttp.ClientOption"}}) ({{.ServiceType}}HTTPClient, error) {
+	opts = append([]{{ident "transhttp.ClientOption"}}{
+		{{ident "transhttp.WithEndpoint"}}("discovery:///" + name),
+		{{ident "transhttp.WithDiscovery"}}(d),
+		{{ident "transhttp.WithMiddleware"}}({{ident "recovery.Recovery"}}()),
+	}, opts...)
+	client, err := {{ident "transhttp.NewClient"}}(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return New{{.ServiceType}}HTTPClient(client), nil
+}
+{{if .GRPCClient}}
+// New{{.ServiceType}}ClientWithDiscovery returns the {{.ServiceType}}Client of the service name resolved by the discovery
+// and the connection to close, with the same defaults as New{{.ServiceType}}HTTPClientWithDiscovery,
+// the transport credentials, e.g. the insecure ones by WithOptions(grpc.WithInsecure()), are set by the options.
+func New{{.ServiceType}}ClientWithDiscovery(ctx {{ident "context.Context"}}, d {{ident "registry.Discovery"}}, name string, opts ...{{ident "transgrpc.ClientOption"}}) ({{.ServiceType}}Client, *{{ident "grpc.ClientConn"}}, error) {
+	opts = append([]{{ident "transgrpc.ClientOption"}}{
+		{{ident "transgrpc.WithEndpoint"}}("discovery:///" + name),
+		{{ident "transgrpc.WithDiscovery"}}(d),
+		{{ident "transgrpc.WithMiddleware"}}({{ident "recovery.Recovery"}}()),
+	}, opts...)
+	conn, err := {{ident "transgrpc.Dial"}}(ctx, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return New{{.ServiceType}}Client(conn), conn, nil
+}
+{{end}}
 {{$svrType := .ServiceType}}
 {{$svrName := .ServiceName}}
 {{range .MethodSets}}
@@ -88,6 +121,7 @@ type serviceDesc struct {
 	Metadata    string // api/helloworld/helloworld.proto
 	Methods     []*methodDesc
 	MethodSets  map[string]*methodDesc
+	GRPCClient  bool
 }
 
 type methodDesc struct {
@@ -105,13 +139,13 @@ type methodDesc struct {
 	ResponseBody string
 }
 
-func (s *serviceDesc) execute() string {
+func (s *serviceDesc) execute(g *protogen.GeneratedFile) string {
 	s.MethodSets = make(map[string]*methodDesc)
 	for _, m := range s.Methods {
 		s.MethodSets[m.Name] = m
 	}
 	buf := new(bytes.Buffer)
-	tmpl, err := template.New("http").Parse(strings.TrimSpace(httpTemplate))
+	tmpl, err := template.New("http").Funcs(template.FuncMap{"ident": qualify(g)}).Parse(strings.TrimSpace(httpTemplate))
 	if err != nil {
 		panic(err)
 	}
@@ -0,0 +1,101 @@
+package consul
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/hashicorp/consul/api"
+)
+
+var _ config.Source = (*source)(nil)
+
+// Option is consul config option.
+type Option func(o *options)
+
+type options struct {
+	ctx  context.Context
+	path string
+}
+
+// WithContext with config context.
+func WithContext(ctx context.Context) Option {
+	return func(o *options) {
+		o.ctx = ctx
+	}
+}
+
+// WithPath is config path prefix, e.g. app/cart/config
+func WithPath(p string) Option {
+	return func(o *options) {
+		o.path = p
+	}
+}
+
+type source struct {
+	client  *api.Client
+	options *options
+}
+
+// New new a consul KV config source.
+func New(client *api.Client, opts ...Option) (config.Source, error) {
+	options := &options{
+		ctx: context.Background(),
+	}
+	for _, o := range opts {
+		o(options)
+	}
+	if options.path == "" {
+		return nil, errors.New("path invalid")
+	}
+	return &source{
+		client:  client,
+		options: options,
+	}, nil
+}
+
+// Load return the config values.
+func (s *source) Load() ([]*config.KeyValue, error) {
+	kv, _, err := s.client.KV().List(s.prefix(), (&api.QueryOptions{}).WithContext(s.options.ctx))
+	if err != nil {
+		return nil, err
+	}
+	return s.convert(kv), nil
+}
+
+// Watch return the watcher.
+func (s *source) Watch() (config.Watcher, error) {
+	return newWatcher(s), nil
+}
+
+// prefix is the path with the trailing slash, so the sibling keys of the same
+// string prefix are not listed, e.g. app2/config.yaml of the path app.
+func (s *source) prefix() string {
+	return strings.TrimSuffix(s.options.path, "/") + "/"
+}
+
+func (s *source) convert(kv api.KVPairs) []*config.KeyValue {
+	prefix := s.prefix()
+	kvs := make([]*config.KeyValue, 0, len(kv))
+	for _, item := range kv {
+		k := strings.TrimPrefix(item.Key, prefix)
+		// ignore the folder keys
+		if k == "" || strings.HasSuffix(k, "/") {
+			continue
+		}
+		kvs = append(kvs, &config.KeyValue{
+			Key:    k,
+			Value:  item.Value,
+			Format: format(k),
+		})
+	}
+	return kvs
+}
+
+func format(name string) string {
+	if p := strings.Split(name, "."); len(p) > 1 {
+		return p[len(p)-1]
+	}
+	return ""
+}
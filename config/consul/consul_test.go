@@ -0,0 +1,116 @@
+package consul
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+)
+
+// kvServer is a fake of the consul KV API which lists the keys by the string prefix.
+type kvServer struct {
+	mu    sync.Mutex
+	index uint64
+	kv    map[string]string
+}
+
+func (s *kvServer) set(key, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.kv[key] = value
+	s.index++
+}
+
+func (s *kvServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	wait, _ := strconv.ParseUint(r.URL.Query().Get("index"), 10, 64)
+	for i := 0; i < 10; i++ {
+		s.mu.Lock()
+		changed := s.index > wait
+		s.mu.Unlock()
+		if changed {
+			break
+		}
+		// the blocking query
+		time.Sleep(10 * time.Millisecond)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prefix := strings.TrimPrefix(r.URL.Path, "/v1/kv/")
+	pairs := api.KVPairs{}
+	for k, v := range s.kv {
+		if strings.HasPrefix(k, prefix) {
+			pairs = append(pairs, &api.KVPair{Key: k, Value: []byte(v)})
+		}
+	}
+	w.Header().Set("X-Consul-Index", strconv.FormatUint(s.index, 10))
+	_ = json.NewEncoder(w).Encode(pairs)
+}
+
+func newSource(t *testing.T, kv map[string]string) (*kvServer, *source) {
+	fake := &kvServer{index: 1, kv: kv}
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+	client, err := api.NewClient(&api.Config{Address: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := New(client, WithPath("app"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fake, s.(*source)
+}
+
+func TestLoad(t *testing.T) {
+	_, s := newSource(t, map[string]string{
+		"app/config.yaml":  "a: 1",
+		"app/db/":          "",
+		"app/db/pg.json":   "{}",
+		"app2/config.yaml": "b: 2",
+	})
+	kvs, err := s.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]string)
+	for _, kv := range kvs {
+		got[kv.Key] = kv.Format
+	}
+	expected := map[string]string{"config.yaml": "yaml", "db/pg.json": "json"}
+	if len(got) != len(expected) {
+		t.Fatalf("expected %v got %v", expected, got)
+	}
+	for k, f := range expected {
+		if got[k] != f {
+			t.Errorf("expected %s of the format %s got %q", k, f, got[k])
+		}
+	}
+}
+
+func TestWatch(t *testing.T) {
+	fake, s := newSource(t, map[string]string{"app/config.yaml": "a: 1"})
+	w, err := s.Watch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+	if kvs, err := w.Next(); err != nil || len(kvs) != 1 || string(kvs[0].Value) != "a: 1" {
+		t.Fatalf("expected the initial config got %v %v", kvs, err)
+	}
+	fake.set("app/config.yaml", "a: 2")
+	if kvs, err := w.Next(); err != nil || len(kvs) != 1 || string(kvs[0].Value) != "a: 2" {
+		t.Fatalf("expected the changed config got %v %v", kvs, err)
+	}
+	if err := w.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Next(); err == nil {
+		t.Fatal("expected the error of the stopped watcher")
+	}
+}
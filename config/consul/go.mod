@@ -0,0 +1,10 @@
+module github.com/go-kratos/kratos/config/consul/v2
+
+go 1.15
+
+require (
+	github.com/go-kratos/kratos/v2 v2.0.0-rc1
+	github.com/hashicorp/consul/api v1.8.1
+)
+
+replace github.com/go-kratos/kratos/v2 => ../../
@@ -0,0 +1,76 @@
+package consul
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/hashicorp/consul/api"
+)
+
+type watcher struct {
+	source *source
+	ch     chan []*config.KeyValue
+	index  uint64
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func newWatcher(s *source) *watcher {
+	ctx, cancel := context.WithCancel(s.options.ctx)
+	w := &watcher{
+		source: s,
+		ch:     make(chan []*config.KeyValue),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+	go w.watch()
+	return w
+}
+
+func (w *watcher) watch() {
+	for {
+		opts := &api.QueryOptions{WaitIndex: w.index}
+		kv, meta, err := w.source.client.KV().List(w.source.prefix(), opts.WithContext(w.ctx))
+		if err != nil {
+			// the agent is unavailable or the session is lost, retry after a while
+			select {
+			case <-w.ctx.Done():
+				return
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+		if meta.LastIndex < w.index {
+			// the index went backwards, e.g. the raft store was restored, reset it.
+			w.index = 0
+			continue
+		}
+		if meta.LastIndex == w.index {
+			// the blocking query timed out without changes.
+			continue
+		}
+		w.index = meta.LastIndex
+		// an empty list means the prefix was deleted
+		select {
+		case w.ch <- w.source.convert(kv):
+		case <-w.ctx.Done():
+			return
+		}
+	}
+}
+
+func (w *watcher) Next() ([]*config.KeyValue, error) {
+	select {
+	case kvs := <-w.ch:
+		return kvs, nil
+	case <-w.ctx.Done():
+		return nil, w.ctx.Err()
+	}
+}
+
+func (w *watcher) Stop() error {
+	w.cancel()
+	return nil
+}
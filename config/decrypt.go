@@ -0,0 +1,51 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// EncryptedPrefix is the prefix of the encrypted config values.
+const EncryptedPrefix = "enc:"
+
+// Decryptor decrypts the encrypted config values, e.g. by age, KMS or sops.
+type Decryptor interface {
+	// Decrypt returns the plaintext of the ciphertext without the prefix.
+	Decrypt(ciphertext string) (string, error)
+}
+
+// decrypt decrypts the string values with the encrypted prefix in place.
+func decrypt(d Decryptor, path string, v interface{}) (interface{}, error) {
+	switch x := v.(type) {
+	case map[string]interface{}:
+		for k, v := range x {
+			key := k
+			if path != "" {
+				key = path + "." + k
+			}
+			res, err := decrypt(d, key, v)
+			if err != nil {
+				return nil, err
+			}
+			x[k] = res
+		}
+	case []interface{}:
+		for i, v := range x {
+			res, err := decrypt(d, fmt.Sprintf("%s[%d]", path, i), v)
+			if err != nil {
+				return nil, err
+			}
+			x[i] = res
+		}
+	case string:
+		if !strings.HasPrefix(x, EncryptedPrefix) {
+			return x, nil
+		}
+		plaintext, err := d.Decrypt(strings.TrimPrefix(x, EncryptedPrefix))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt key: %s error: %w", path, err)
+		}
+		return plaintext, nil
+	}
+	return v, nil
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type testDecryptor struct{}
+
+func (testDecryptor) Decrypt(ciphertext string) (string, error) {
+	if ciphertext == "invalid" {
+		return "", errors.New("invalid ciphertext")
+	}
+	return strings.ToUpper(ciphertext), nil
+}
+
+func TestDecrypt(t *testing.T) {
+	v := map[string]interface{}{
+		"database": map[string]interface{}{
+			"user":     "kratos",
+			"password": "enc:secret",
+		},
+	}
+	res, err := decrypt(testDecryptor{}, "", v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := res.(map[string]interface{})["database"].(map[string]interface{})
+	if db["password"] != "SECRET" || db["user"] != "kratos" {
+		t.Fatalf("unexpected values %v", db)
+	}
+
+	v = map[string]interface{}{
+		"database": map[string]interface{}{"password": "enc:invalid"},
+	}
+	if _, err = decrypt(testDecryptor{}, "", v); err == nil || !strings.Contains(err.Error(), "database.password") {
+		t.Fatalf("expected the failed key in error got %v", err)
+	}
+}
@@ -11,9 +11,10 @@ type Decoder func(*KeyValue, map[string]interface{}) error
 type Option func(*options)
 
 type options struct {
-	sources []Source
-	decoder Decoder
-	logger  log.Logger
+	sources   []Source
+	decoder   Decoder
+	decryptor Decryptor
+	logger    log.Logger
 }
 
 // WithSource with config source.
@@ -30,6 +31,14 @@ func WithDecoder(d Decoder) Option {
 	}
 }
 
+// WithDecryptor with config decryptor, which decrypts the values
+// with the encrypted prefix during load.
+func WithDecryptor(d Decryptor) Option {
+	return func(o *options) {
+		o.decryptor = d
+	}
+}
+
 // WithLogger with config loogger.
 func WithLogger(l log.Logger) Option {
 	return func(o *options) {
@@ -39,7 +39,13 @@ func (r *reader) Merge(kvs ...*KeyValue) error {
 		if err := r.opts.decoder(kv, next); err != nil {
 			return err
 		}
-		if err := mergo.Map(&merged, convertMap(next), mergo.WithOverride); err != nil {
+		values := convertMap(next)
+		if r.opts.decryptor != nil {
+			if values, err = decrypt(r.opts.decryptor, "", values); err != nil {
+				return err
+			}
+		}
+		if err := mergo.Map(&merged, values, mergo.WithOverride); err != nil {
 			return err
 		}
 	}
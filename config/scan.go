@@ -0,0 +1,250 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DecodeHook converts the config value before it is decoded into the type,
+// it returns the value as it is if the conversion does not apply.
+type DecodeHook func(from interface{}, to reflect.Type) (interface{}, error)
+
+// ScanOption is the option of ScanWith.
+type ScanOption func(*scanOptions)
+
+type scanOptions struct {
+	tag   string
+	hooks []DecodeHook
+}
+
+// WithTagName with the struct tag of the field names, e.g. yaml or mapstructure, the default
+// is json. The fields without the tag match the keys by the field names case-insensitively.
+func WithTagName(tag string) ScanOption {
+	return func(o *scanOptions) {
+		o.tag = tag
+	}
+}
+
+// WithDecodeHooks with the hooks which convert the values in order before decoding.
+func WithDecodeHooks(hooks ...DecodeHook) ScanOption {
+	return func(o *scanOptions) {
+		o.hooks = append(o.hooks, hooks...)
+	}
+}
+
+// ScanWith decodes the config of c into v by the struct tags and the decode hooks of the options,
+// e.g. ScanWith(c, &v, WithTagName("mapstructure"), WithDecodeHooks(StringToDurationHook())).
+// The config is the one of c.Scan, which decodes by the json tags only.
+func ScanWith(c Config, v interface{}, opts ...ScanOption) error {
+	var data json.RawMessage
+	if err := c.Scan(&data); err != nil {
+		return err
+	}
+	return scan(data, v, opts)
+}
+
+// StringToDurationHook converts the strings such as 1s to time.Duration.
+func StringToDurationHook() DecodeHook {
+	durationType := reflect.TypeOf(time.Duration(0))
+	return func(from interface{}, to reflect.Type) (interface{}, error) {
+		if s, ok := from.(string); ok && to == durationType {
+			return time.ParseDuration(s)
+		}
+		return from, nil
+	}
+}
+
+// StringToSliceHook converts the strings separated by sep such as a,b,c to slices.
+func StringToSliceHook(sep string) DecodeHook {
+	return func(from interface{}, to reflect.Type) (interface{}, error) {
+		s, ok := from.(string)
+		if !ok || to.Kind() != reflect.Slice || to.Elem().Kind() == reflect.Uint8 {
+			return from, nil
+		}
+		if s == "" {
+			return []interface{}{}, nil
+		}
+		parts := strings.Split(s, sep)
+		values := make([]interface{}, len(parts))
+		for i, p := range parts {
+			values[i] = strings.TrimSpace(p)
+		}
+		return values, nil
+	}
+}
+
+func scan(data []byte, v interface{}, opts []ScanOption) error {
+	options := scanOptions{tag: "json"}
+	for _, o := range opts {
+		o(&options)
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("config: scan into non-pointer %T", v)
+	}
+	var src interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&src); err != nil {
+		return err
+	}
+	return decode(src, rv.Elem(), "", &options)
+}
+
+func decode(from interface{}, to reflect.Value, path string, o *scanOptions) (err error) {
+	for _, hook := range o.hooks {
+		if from, err = hook(from, to.Type()); err != nil {
+			return fmt.Errorf("config: decode %s: %v", path, err)
+		}
+	}
+	if from == nil {
+		return nil
+	}
+	if v := reflect.ValueOf(from); v.Type().AssignableTo(to.Type()) {
+		to.Set(v)
+		return nil
+	}
+	switch to.Kind() {
+	case reflect.Ptr:
+		if to.IsNil() {
+			to.Set(reflect.New(to.Type().Elem()))
+		}
+		return decode(from, to.Elem(), path, o)
+	case reflect.Struct:
+		m, ok := from.(map[string]interface{})
+		if !ok {
+			break
+		}
+		return decodeStruct(m, to, path, o)
+	case reflect.Map:
+		m, ok := from.(map[string]interface{})
+		if !ok || to.Type().Key().Kind() != reflect.String {
+			break
+		}
+		if to.IsNil() {
+			to.Set(reflect.MakeMapWithSize(to.Type(), len(m)))
+		}
+		for k, v := range m {
+			elem := reflect.New(to.Type().Elem()).Elem()
+			if err := decode(v, elem, join(path, k), o); err != nil {
+				return err
+			}
+			to.SetMapIndex(reflect.ValueOf(k).Convert(to.Type().Key()), elem)
+		}
+		return nil
+	case reflect.Slice:
+		s, ok := from.([]interface{})
+		if !ok {
+			break
+		}
+		slice := reflect.MakeSlice(to.Type(), len(s), len(s))
+		for i, v := range s {
+			if err := decode(v, slice.Index(i), join(path, strconv.Itoa(i)), o); err != nil {
+				return err
+			}
+		}
+		to.Set(slice)
+		return nil
+	case reflect.String:
+		switch v := from.(type) {
+		case string:
+			to.SetString(v)
+			return nil
+		case json.Number:
+			to.SetString(v.String())
+			return nil
+		}
+	case reflect.Bool:
+		if b, err := strconv.ParseBool(fmt.Sprint(from)); err == nil {
+			to.SetBool(b)
+			return nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if i, err := strconv.ParseInt(fmt.Sprint(from), 10, 64); err == nil && !to.OverflowInt(i) {
+			to.SetInt(i)
+			return nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if i, err := strconv.ParseUint(fmt.Sprint(from), 10, 64); err == nil && !to.OverflowUint(i) {
+			to.SetUint(i)
+			return nil
+		}
+	case reflect.Float32, reflect.Float64:
+		if f, err := strconv.ParseFloat(fmt.Sprint(from), 64); err == nil {
+			to.SetFloat(f)
+			return nil
+		}
+	}
+	return fmt.Errorf("config: decode %s: cannot convert %T to %s", path, from, to.Type())
+}
+
+func decodeStruct(m map[string]interface{}, to reflect.Value, path string, o *scanOptions) error {
+	t := to.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" && !f.Anonymous {
+			continue
+		}
+		name, opts := f.Name, ""
+		tag, tagged := f.Tag.Lookup(o.tag)
+		if tagged {
+			parts := strings.SplitN(tag, ",", 2)
+			if parts[0] == "-" {
+				continue
+			}
+			if parts[0] != "" {
+				name = parts[0]
+			}
+			if len(parts) > 1 {
+				opts = parts[1]
+			}
+		}
+		field := to.Field(i)
+		// the embedded structs without a name are squashed like json
+		if f.Anonymous && (!tagged || strings.Contains(opts, "squash") || strings.Contains(opts, "inline")) {
+			if field.Kind() == reflect.Ptr && field.IsNil() && field.CanSet() {
+				field.Set(reflect.New(f.Type.Elem()))
+			}
+			if field.Kind() == reflect.Ptr {
+				field = field.Elem()
+			}
+			if field.Kind() == reflect.Struct {
+				if err := decodeStruct(m, field, path, o); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if !field.CanSet() {
+			continue
+		}
+		v, ok := m[name]
+		if !ok {
+			for k, vv := range m {
+				if strings.EqualFold(k, name) {
+					v, ok = vv, true
+					break
+				}
+			}
+		}
+		if !ok {
+			continue
+		}
+		if err := decode(v, field, join(path, name), o); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testBase struct {
+	Name string `mapstructure:"name"`
+}
+
+type testScan struct {
+	testBase `mapstructure:",squash"`
+	Timeout  time.Duration     `mapstructure:"timeout"`
+	Hosts    []string          `mapstructure:"hosts"`
+	Port     int               `mapstructure:"port"`
+	Labels   map[string]string `mapstructure:"labels"`
+	Enabled  *bool
+}
+
+func TestScan(t *testing.T) {
+	data := []byte(`{"name":"kratos","timeout":"1.5s","hosts":"a, b","port":8000,"labels":{"env":"dev"},"enabled":true}`)
+	var v testScan
+	err := scan(data, &v, []ScanOption{
+		WithTagName("mapstructure"),
+		WithDecodeHooks(StringToDurationHook(), StringToSliceHook(",")),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	enabled := true
+	want := testScan{
+		testBase: testBase{Name: "kratos"},
+		Timeout:  1500 * time.Millisecond,
+		Hosts:    []string{"a", "b"},
+		Port:     8000,
+		Labels:   map[string]string{"env": "dev"},
+		Enabled:  &enabled,
+	}
+	if !reflect.DeepEqual(v, want) {
+		t.Fatalf("expected %+v got %+v", want, v)
+	}
+	if err := scan([]byte(`{"port":"http"}`), &v, []ScanOption{WithTagName("mapstructure")}); err == nil {
+		t.Fatal("expected the conversion error")
+	}
+}
+
+type testSource struct {
+	data string
+}
+
+func (s *testSource) Load() ([]*KeyValue, error) {
+	return []*KeyValue{{Key: "config.json", Value: []byte(s.data), Format: "json"}}, nil
+}
+
+func (s *testSource) Watch() (Watcher, error) {
+	return &testWatcher{done: make(chan struct{})}, nil
+}
+
+type testWatcher struct {
+	done chan struct{}
+}
+
+func (w *testWatcher) Next() ([]*KeyValue, error) {
+	<-w.done
+	return nil, nil
+}
+
+func (w *testWatcher) Stop() error {
+	close(w.done)
+	return nil
+}
+
+func TestScanWith(t *testing.T) {
+	c := New(WithSource(&testSource{data: `{"name":"kratos","timeout":"1.5s","port":1000000}`}))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	var v testScan
+	if err := ScanWith(c, &v, WithTagName("mapstructure"), WithDecodeHooks(StringToDurationHook())); err != nil {
+		t.Fatal(err)
+	}
+	if v.Name != "kratos" || v.Timeout != 1500*time.Millisecond || v.Port != 1000000 {
+		t.Fatalf("unexpected config %+v", v)
+	}
+	var base struct {
+		Name string `json:"name"`
+	}
+	if err := c.Scan(&base); err != nil || base.Name != "kratos" {
+		t.Fatalf("expected the json tags scanned got %+v %v", base, err)
+	}
+}
@@ -0,0 +1,82 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	// BatchReason is the reason of the batch error.
+	BatchReason = "BATCH_ERROR"
+	// batchItemsKey is the metadata key of the encoded items.
+	batchItemsKey = "items"
+)
+
+// ItemError is the error of an item in the batch, Index is the position of the item in the request.
+type ItemError struct {
+	Index int
+	Error *Error
+}
+
+type batchItem struct {
+	Index    int               `json:"index"`
+	Code     int32             `json:"code"`
+	Reason   string            `json:"reason,omitempty"`
+	Message  string            `json:"message,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// BatchError returns an error of a batch call with the failed items, the other items succeeded.
+// The items are encoded in the metadata, so they are preserved by both the gRPC status
+// and the HTTP body, use BatchItems to decode them on the client.
+// The code is the code of the items if they are the same, 400 if they are all client errors, or 500.
+func BatchError(items []ItemError) *Error {
+	code := UnknownCode
+	encoded := make([]batchItem, 0, len(items))
+	for i, item := range items {
+		e := item.Error
+		if e == nil {
+			e = New(UnknownCode, UnknownReason, "")
+		}
+		switch {
+		case i == 0:
+			code = int(e.Code)
+		case code == int(e.Code):
+		case code/100 == 4 && e.Code/100 == 4:
+			code = 400
+		default:
+			code = UnknownCode
+		}
+		encoded = append(encoded, batchItem{
+			Index:    item.Index,
+			Code:     e.Code,
+			Reason:   e.Reason,
+			Message:  e.Message,
+			Metadata: e.Metadata,
+		})
+	}
+	data, _ := json.Marshal(encoded)
+	return New(code, BatchReason, fmt.Sprintf("%d items failed", len(items))).
+		WithMetadata(map[string]string{batchItemsKey: string(data)})
+}
+
+// BatchItems returns the failed items of the batch error.
+// It supports wrapped errors.
+func BatchItems(err error) ([]ItemError, bool) {
+	se := FromError(err)
+	if se == nil || se.Reason != BatchReason {
+		return nil, false
+	}
+	var encoded []batchItem
+	if err := json.Unmarshal([]byte(se.Metadata[batchItemsKey]), &encoded); err != nil {
+		return nil, false
+	}
+	items := make([]ItemError, 0, len(encoded))
+	for _, e := range encoded {
+		items = append(items, ItemError{
+			Index: e.Index,
+			Error: New(int(e.Code), e.Reason, e.Message).WithMetadata(e.Metadata),
+		})
+	}
+	return items, true
+}
@@ -0,0 +1,24 @@
+package errors
+
+import "testing"
+
+func TestBatchError(t *testing.T) {
+	err := BatchError([]ItemError{
+		{Index: 1, Error: NotFound("USER_NOT_FOUND", "user 1 not found")},
+		{Index: 3, Error: BadRequest("INVALID_NAME", "invalid name")},
+	})
+	if !IsBadRequest(err) {
+		t.Fatalf("expected bad request got %v", err)
+	}
+	// the items are preserved by the gRPC status
+	items, ok := BatchItems(err.GRPCStatus().Err())
+	if !ok || len(items) != 2 {
+		t.Fatalf("expected 2 items got %v", items)
+	}
+	if items[1].Index != 3 || !IsBadRequest(items[1].Error) || items[1].Error.Reason != "INVALID_NAME" {
+		t.Fatalf("unexpected item: %v", items[1])
+	}
+	if _, ok := BatchItems(NotFound("USER_NOT_FOUND", "")); ok {
+		t.Fatal("expected not a batch error")
+	}
+}
@@ -1,13 +1,17 @@
 package errors
 
 import (
+	"context"
 	"errors"
 	"fmt"
 
 	"github.com/go-kratos/kratos/v2/internal/httputil"
+	protov1 "github.com/golang/protobuf/proto"
 	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
 )
 
 const (
@@ -30,11 +34,15 @@ func (e *Error) StatusCode() int {
 
 // GRPCStatus returns the Status represented by se.
 func (e *Error) GRPCStatus() *status.Status {
-	s, _ := status.New(httputil.GRPCCodeFromStatus(e.StatusCode()), e.Message).
-		WithDetails(&errdetails.ErrorInfo{
-			Reason:   e.Reason,
-			Metadata: e.Metadata,
-		})
+	// the details of the grpc status are of the golang/protobuf API
+	details := []protov1.Message{&errdetails.ErrorInfo{
+		Reason:   e.Reason,
+		Metadata: e.Metadata,
+	}}
+	if info, ok := retryInfo(e.Metadata); ok {
+		details = append(details, info)
+	}
+	s, _ := status.New(httputil.GRPCCodeFromStatus(e.StatusCode()), e.Message).WithDetails(details...)
 	return s
 }
 
@@ -104,16 +112,36 @@ func FromError(err error) *Error {
 	}
 	gs, ok := status.FromError(err)
 	if ok {
+		var retryDelay *durationpb.Duration
+		for _, detail := range gs.Details() {
+			if d, ok := detail.(*errdetails.RetryInfo); ok {
+				retryDelay = d.RetryDelay
+			}
+		}
 		for _, detail := range gs.Details() {
 			switch d := detail.(type) {
 			case *errdetails.ErrorInfo:
-				return New(
+				se := New(
 					httputil.StatusFromGRPCCode(gs.Code()),
 					d.Reason,
 					gs.Message(),
 				).WithMetadata(d.Metadata)
+				if _, ok := se.Metadata[RetryAfterKey]; !ok && retryDelay != nil {
+					se = se.WithRetryAfter(retryDelay.AsDuration())
+				}
+				return se
 			}
 		}
+		// the timeouts are distinct from the errors of the server
+		if c := gs.Code(); c == codes.DeadlineExceeded || c == codes.Canceled {
+			return New(httputil.StatusFromGRPCCode(c), UnknownReason, gs.Message())
+		}
+	}
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return GatewayTimeout(UnknownReason, err.Error())
+	case errors.Is(err, context.Canceled):
+		return ClientClosed(UnknownReason, err.Error())
 	}
 	return New(UnknownCode, UnknownReason, err.Error())
 }
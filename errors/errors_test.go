@@ -1,10 +1,17 @@
 package errors
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
 	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
 )
 
 func TestError(t *testing.T) {
@@ -49,3 +56,39 @@ func TestError(t *testing.T) {
 		t.Errorf("got %+v want %+v", se, err)
 	}
 }
+
+func TestContextError(t *testing.T) {
+	if err := fmt.Errorf("wrap %w", context.DeadlineExceeded); !IsGatewayTimeout(err) {
+		t.Errorf("expected gateway timeout got %v", FromError(err))
+	}
+	if !IsClientClosed(context.Canceled) {
+		t.Errorf("expected client closed got %v", FromError(context.Canceled))
+	}
+	if err := status.Error(codes.DeadlineExceeded, "deadline"); !IsGatewayTimeout(err) {
+		t.Errorf("expected gateway timeout got %v", FromError(err))
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	err := New(http.StatusTooManyRequests, "RATE_LIMITED", "rate limited").WithRetryAfter(3 * time.Second)
+	if d, ok := RetryAfter(fmt.Errorf("wrap %w", err)); !ok || d != 3*time.Second {
+		t.Fatalf("got retry after %v %v", d, ok)
+	}
+	if _, ok := RetryAfter(New(http.StatusTooManyRequests, "RATE_LIMITED", "rate limited")); ok {
+		t.Fatal("unexpected retry after")
+	}
+
+	se := FromError(err.GRPCStatus().Err())
+	if d, ok := RetryAfter(se); !ok || d != 3*time.Second || se.Code != http.StatusTooManyRequests {
+		t.Fatalf("got %+v retry after %v %v", se, d, ok)
+	}
+
+	// the RetryInfo detail alone is understood as well, e.g. from the non-kratos servers
+	gs, _ := status.New(codes.ResourceExhausted, "rate limited").WithDetails(
+		&errdetails.ErrorInfo{Reason: "RATE_LIMITED"},
+		&errdetails.RetryInfo{RetryDelay: durationpb.New(time.Second)},
+	)
+	if d, ok := RetryAfter(gs.Err()); !ok || d != time.Second {
+		t.Fatalf("got retry after %v %v", d, ok)
+	}
+}
@@ -0,0 +1,54 @@
+package errors
+
+import (
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// RetryAfterKey is the metadata key of the retry delay, which is sent as the Retry-After
+// header of HTTP and the RetryInfo detail of gRPC.
+const RetryAfterKey = "retry-after"
+
+// WithRetryAfter with the delay the client should wait before retrying, e.g. of the
+// rate limited or overloaded requests.
+func (e *Error) WithRetryAfter(d time.Duration) *Error {
+	md := make(map[string]string, len(e.Metadata)+1)
+	for k, v := range e.Metadata {
+		md[k] = v
+	}
+	md[RetryAfterKey] = d.String()
+	return e.WithMetadata(md)
+}
+
+// RetryAfter returns the retry delay of the error, if any.
+// It supports wrapped errors.
+func RetryAfter(err error) (time.Duration, bool) {
+	se := FromError(err)
+	if se == nil {
+		return 0, false
+	}
+	v, ok := se.Metadata[RetryAfterKey]
+	if !ok {
+		return 0, false
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		return 0, false
+	}
+	return d, true
+}
+
+// retryInfo returns the RetryInfo detail of the retry delay in the metadata.
+func retryInfo(md map[string]string) (*errdetails.RetryInfo, bool) {
+	v, ok := md[RetryAfterKey]
+	if !ok {
+		return nil, false
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return nil, false
+	}
+	return &errdetails.RetryInfo{RetryDelay: durationpb.New(d)}, true
+}
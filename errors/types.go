@@ -55,6 +55,17 @@ func IsConflict(err error) bool {
 	return Code(err) == 409
 }
 
+// PreconditionFailed new PreconditionFailed error that is mapped to a 412 response.
+func PreconditionFailed(reason, message string) *Error {
+	return Newf(412, reason, message)
+}
+
+// IsPreconditionFailed determines if err is an error which indicates a PreconditionFailed error.
+// It supports wrapped errors.
+func IsPreconditionFailed(err error) bool {
+	return Code(err) == 412
+}
+
 // InternalServer new InternalServer error that is mapped to a 500 response.
 func InternalServer(reason, message string) *Error {
 	return Newf(500, reason, message)
@@ -93,7 +104,8 @@ func ClientClosed(reason, message string) *Error {
 	return Newf(499, reason, message)
 }
 
-// IsClientClosed determines if err is an error which indicates a IsClientClosed error.
+// IsClientClosed determines if err is an error which indicates a ClientClosed error,
+// e.g. the request is cancelled by the client.
 // It supports wrapped errors.
 func IsClientClosed(err error) bool {
 	return Code(err) == 499
@@ -10,8 +10,11 @@ func TestTypes(t *testing.T) {
 			Forbidden("reason_403", "message_403"),
 			NotFound("reason_404", "message_404"),
 			Conflict("reason_409", "message_409"),
+			PreconditionFailed("reason_412", "message_412"),
 			InternalServer("reason_500", "message_500"),
 			ServiceUnavailable("reason_503", "message_503"),
+			GatewayTimeout("reason_504", "message_504"),
+			ClientClosed("reason_499", "message_499"),
 		}
 		output = []func(error) bool{
 			IsBadRequest,
@@ -19,8 +22,11 @@ func TestTypes(t *testing.T) {
 			IsForbidden,
 			IsNotFound,
 			IsConflict,
+			IsPreconditionFailed,
 			IsInternalServer,
 			IsServiceUnavailable,
+			IsGatewayTimeout,
+			IsClientClosed,
 		}
 	)
 
@@ -4,6 +4,8 @@ package helloworld
 
 import (
 	context "context"
+	recovery "github.com/go-kratos/kratos/v2/middleware/recovery"
+	registry "github.com/go-kratos/kratos/v2/registry"
 	http1 "github.com/go-kratos/kratos/v2/transport/http"
 	binding "github.com/go-kratos/kratos/v2/transport/http/binding"
 	mux "github.com/gorilla/mux"
@@ -74,6 +76,21 @@ func NewGreeterHTTPClient(client *http1.Client) GreeterHTTPClient {
 	return &GreeterHTTPClientImpl{client}
 }
 
+// NewGreeterHTTPClientWithDiscovery returns the GreeterHTTPClient of the service name resolved by the discovery,
+// with the recovery middleware by default, the options, e.g. the middleware and the balancer, override the defaults.
+func NewGreeterHTTPClientWithDiscovery(ctx context.Context, d registry.Discovery, name string, opts ...http1.ClientOption) (GreeterHTTPClient, error) {
+	opts = append([]http1.ClientOption{
+		http1.WithEndpoint("discovery:///" + name),
+		http1.WithDiscovery(d),
+		http1.WithMiddleware(recovery.Recovery()),
+	}, opts...)
+	client, err := http1.NewClient(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return NewGreeterHTTPClient(client), nil
+}
+
 func (c *GreeterHTTPClientImpl) SayHello(ctx context.Context, in *HelloRequest, opts ...http1.CallOption) (out *HelloReply, err error) {
 	path := binding.EncodePath("GET", "/helloworld/{name}", in)
 	out = &HelloReply{}
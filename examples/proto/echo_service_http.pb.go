@@ -4,6 +4,8 @@ package testproto
 
 import (
 	context "context"
+	recovery "github.com/go-kratos/kratos/v2/middleware/recovery"
+	registry "github.com/go-kratos/kratos/v2/registry"
 	http1 "github.com/go-kratos/kratos/v2/transport/http"
 	binding "github.com/go-kratos/kratos/v2/transport/http/binding"
 	mux "github.com/gorilla/mux"
@@ -307,6 +309,21 @@ func NewEchoServiceHTTPClient(client *http1.Client) EchoServiceHTTPClient {
 	return &EchoServiceHTTPClientImpl{client}
 }
 
+// NewEchoServiceHTTPClientWithDiscovery returns the EchoServiceHTTPClient of the service name resolved by the discovery,
+// with the recovery middleware by default, the options, e.g. the middleware and the balancer, override the defaults.
+func NewEchoServiceHTTPClientWithDiscovery(ctx context.Context, d registry.Discovery, name string, opts ...http1.ClientOption) (EchoServiceHTTPClient, error) {
+	opts = append([]http1.ClientOption{
+		http1.WithEndpoint("discovery:///" + name),
+		http1.WithDiscovery(d),
+		http1.WithMiddleware(recovery.Recovery()),
+	}, opts...)
+	client, err := http1.NewClient(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return NewEchoServiceHTTPClient(client), nil
+}
+
 func (c *EchoServiceHTTPClientImpl) Echo(ctx context.Context, in *SimpleMessage, opts ...http1.CallOption) (out *SimpleMessage, err error) {
 	path := binding.EncodePath("POST", "/v1/example/echo/{id}", in)
 	out = &SimpleMessage{}
@@ -4,6 +4,8 @@ package testproto
 
 import (
 	context "context"
+	recovery "github.com/go-kratos/kratos/v2/middleware/recovery"
+	registry "github.com/go-kratos/kratos/v2/registry"
 	http1 "github.com/go-kratos/kratos/v2/transport/http"
 	binding "github.com/go-kratos/kratos/v2/transport/http/binding"
 	mux "github.com/gorilla/mux"
@@ -42,3 +44,18 @@ type StreamServiceHTTPClientImpl struct {
 func NewStreamServiceHTTPClient(client *http1.Client) StreamServiceHTTPClient {
 	return &StreamServiceHTTPClientImpl{client}
 }
+
+// NewStreamServiceHTTPClientWithDiscovery returns the StreamServiceHTTPClient of the service name resolved by the discovery,
+// with the recovery middleware by default, the options, e.g. the middleware and the balancer, override the defaults.
+func NewStreamServiceHTTPClientWithDiscovery(ctx context.Context, d registry.Discovery, name string, opts ...http1.ClientOption) (StreamServiceHTTPClient, error) {
+	opts = append([]http1.ClientOption{
+		http1.WithEndpoint("discovery:///" + name),
+		http1.WithDiscovery(d),
+		http1.WithMiddleware(recovery.Recovery()),
+	}, opts...)
+	client, err := http1.NewClient(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return NewStreamServiceHTTPClient(client), nil
+}
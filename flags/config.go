@@ -0,0 +1,102 @@
+package flags
+
+import (
+	"context"
+	"encoding/json"
+	"sync/atomic"
+
+	"github.com/go-kratos/kratos/v2/config"
+)
+
+var _ Provider = (*Config)(nil)
+
+// ConfigOption is config provider option.
+type ConfigOption func(*Config)
+
+// WithDefault with the default of the flags not in the config, the default is false.
+func WithDefault(enabled bool) ConfigOption {
+	return func(c *Config) {
+		c.def = enabled
+	}
+}
+
+// WithTenant with the func which returns the tenant of the request,
+// the flags can be overridden per tenant in the config.
+func WithTenant(f func(ctx context.Context) (string, bool)) ConfigOption {
+	return func(c *Config) {
+		c.tenant = f
+	}
+}
+
+// Config is a feature flag provider resolved from the config, which is reloaded
+// when the config changes. Each flag is either a bool or an object with the
+// per-tenant overrides, e.g.
+//
+//	flags:
+//	  new-checkout:
+//	    enabled: false
+//	    tenants:
+//	      acme: true
+//	  dark-mode: true
+type Config struct {
+	flags  atomic.Value // map[string]flag
+	def    bool
+	tenant func(ctx context.Context) (string, bool)
+}
+
+type flag struct {
+	Enabled bool            `json:"enabled"`
+	Tenants map[string]bool `json:"tenants"`
+}
+
+func (f *flag) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &f.Enabled); err == nil {
+		return nil
+	}
+	type plain flag
+	return json.Unmarshal(data, (*plain)(f))
+}
+
+// NewConfig new a config provider with the flags of the config key, e.g. flags.
+func NewConfig(c config.Config, key string, opts ...ConfigOption) (*Config, error) {
+	p := &Config{}
+	for _, o := range opts {
+		o(p)
+	}
+	if err := p.load(c.Value(key)); err != nil {
+		return nil, err
+	}
+	if err := c.Watch(key, func(_ string, v config.Value) {
+		// keeps the last flags if the new ones are invalid
+		_ = p.load(v)
+	}); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *Config) load(v config.Value) error {
+	var flags map[string]flag
+	if err := v.Scan(&flags); err != nil {
+		return err
+	}
+	p.flags.Store(flags)
+	return nil
+}
+
+// Enabled reports whether the flag is enabled for the tenant of the request.
+func (p *Config) Enabled(ctx context.Context, key string) bool {
+	flags, _ := p.flags.Load().(map[string]flag)
+	f, ok := flags[key]
+	if !ok {
+		return p.def
+	}
+	if p.tenant != nil {
+		if tenant, ok := p.tenant(ctx); ok {
+			if enabled, ok := f.Tenants[tenant]; ok {
+				return enabled
+			}
+		}
+	}
+	return f.Enabled
+}
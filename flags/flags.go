@@ -0,0 +1,85 @@
+package flags
+
+import (
+	"context"
+	"sync"
+)
+
+// Provider is feature flag provider.
+type Provider interface {
+	// Enabled reports whether the flag is enabled, the provider can evaluate
+	// the flag by the request metadata in ctx, such as tenant and user.
+	Enabled(ctx context.Context, key string) bool
+}
+
+type providerKey struct{}
+
+// NewContext returns a new Context that carries the provider.
+func NewContext(ctx context.Context, p Provider) context.Context {
+	return context.WithValue(ctx, providerKey{}, p)
+}
+
+// FromContext returns the Provider value stored in ctx, if any.
+func FromContext(ctx context.Context) (p Provider, ok bool) {
+	p, ok = ctx.Value(providerKey{}).(Provider)
+	return
+}
+
+type overridesKey struct{}
+
+// NewOverridesContext returns a new Context that carries the per-request overrides of the flags,
+// which take precedence over the provider.
+func NewOverridesContext(ctx context.Context, overrides map[string]bool) context.Context {
+	return context.WithValue(ctx, overridesKey{}, overrides)
+}
+
+// OverridesFromContext returns the per-request overrides stored in ctx, if any.
+func OverridesFromContext(ctx context.Context) (overrides map[string]bool, ok bool) {
+	overrides, ok = ctx.Value(overridesKey{}).(map[string]bool)
+	return
+}
+
+// Enabled reports whether the flag is enabled by the overrides or the provider in ctx,
+// it returns false if there is neither.
+func Enabled(ctx context.Context, key string) bool {
+	if overrides, ok := OverridesFromContext(ctx); ok {
+		if enabled, ok := overrides[key]; ok {
+			return enabled
+		}
+	}
+	if p, ok := FromContext(ctx); ok {
+		return p.Enabled(ctx, key)
+	}
+	return false
+}
+
+var _ Provider = (*Static)(nil)
+
+// Static is an in-memory feature flag provider.
+type Static struct {
+	mu    sync.RWMutex
+	flags map[string]bool
+}
+
+// NewStatic new a static provider with the flags.
+func NewStatic(flags map[string]bool) *Static {
+	s := &Static{flags: make(map[string]bool, len(flags))}
+	for k, v := range flags {
+		s.flags[k] = v
+	}
+	return s
+}
+
+// Enabled reports whether the flag is enabled.
+func (s *Static) Enabled(ctx context.Context, key string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.flags[key]
+}
+
+// Set sets the flag.
+func (s *Static) Set(key string, enabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.flags[key] = enabled
+}
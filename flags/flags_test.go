@@ -0,0 +1,56 @@
+package flags
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestEnabled(t *testing.T) {
+	ctx := context.Background()
+	if Enabled(ctx, "feature") {
+		t.Fatal("expected disabled without provider")
+	}
+	p := NewStatic(map[string]bool{"feature": true})
+	ctx = NewContext(ctx, p)
+	if !Enabled(ctx, "feature") {
+		t.Fatal("expected enabled")
+	}
+	p.Set("feature", false)
+	if Enabled(ctx, "feature") {
+		t.Fatal("expected disabled")
+	}
+}
+
+func TestOverrides(t *testing.T) {
+	ctx := NewContext(context.Background(), NewStatic(map[string]bool{"feature": true}))
+	ctx = NewOverridesContext(ctx, map[string]bool{"feature": false, "other": true})
+	if Enabled(ctx, "feature") {
+		t.Fatal("expected disabled by the overrides")
+	}
+	if !Enabled(ctx, "other") {
+		t.Fatal("expected enabled by the overrides")
+	}
+}
+
+func TestFlagUnmarshal(t *testing.T) {
+	var flags map[string]flag
+	data := `{"dark-mode":true,"new-checkout":{"enabled":false,"tenants":{"acme":true}}}`
+	if err := json.Unmarshal([]byte(data), &flags); err != nil {
+		t.Fatal(err)
+	}
+	if !flags["dark-mode"].Enabled {
+		t.Fatal("expected dark-mode enabled")
+	}
+	if f := flags["new-checkout"]; f.Enabled || !f.Tenants["acme"] {
+		t.Fatalf("unexpected new-checkout %+v", f)
+	}
+	p := &Config{tenant: func(ctx context.Context) (string, bool) { return "acme", true }}
+	p.flags.Store(flags)
+	if !p.Enabled(context.Background(), "new-checkout") {
+		t.Fatal("expected new-checkout enabled for acme")
+	}
+	if p.Enabled(context.Background(), "missing") {
+		t.Fatal("expected the default of the missing flag")
+	}
+}
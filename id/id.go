@@ -0,0 +1,30 @@
+package id
+
+import (
+	"github.com/google/uuid"
+)
+
+// Generator generates the unique IDs, which is shared by the app instance ID
+// and the request ID to keep the same scheme.
+type Generator interface {
+	Generate() string
+}
+
+// GeneratorFunc is an adapter to use the ordinary function as the Generator.
+type GeneratorFunc func() string
+
+// Generate calls f().
+func (f GeneratorFunc) Generate() string {
+	return f()
+}
+
+// UUID returns a generator of the time-based UUIDs, which is the default generator.
+func UUID() Generator {
+	return GeneratorFunc(func() string {
+		id, err := uuid.NewUUID()
+		if err != nil {
+			return ""
+		}
+		return id.String()
+	})
+}
@@ -0,0 +1,11 @@
+package id
+
+import "testing"
+
+func TestUUID(t *testing.T) {
+	g := UUID()
+	a, b := g.Generate(), g.Generate()
+	if len(a) != 36 || a == b {
+		t.Fatalf("unexpected ids %s %s", a, b)
+	}
+}
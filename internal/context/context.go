@@ -113,3 +113,33 @@ func (mc *mergeCtx) Value(key interface{}) interface{} {
 	}
 	return mc.parent2.Value(key)
 }
+
+type detachedCtx struct {
+	parent context.Context
+}
+
+// Detach returns a context with the values of ctx, which is neither canceled with ctx
+// nor has its deadline, e.g. for the work shared by the requests or after them.
+func Detach(ctx context.Context) context.Context {
+	return detachedCtx{parent: ctx}
+}
+
+// Deadline implements context.Context.
+func (detachedCtx) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+// Done implements context.Context.
+func (detachedCtx) Done() <-chan struct{} {
+	return nil
+}
+
+// Err implements context.Context.
+func (detachedCtx) Err() error {
+	return nil
+}
+
+// Value implements context.Context.
+func (c detachedCtx) Value(key interface{}) interface{} {
+	return c.parent.Value(key)
+}
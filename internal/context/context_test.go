@@ -0,0 +1,24 @@
+package context
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type testKey struct{}
+
+func TestDetach(t *testing.T) {
+	parent, cancel := context.WithTimeout(context.WithValue(context.Background(), testKey{}, "value"), time.Minute)
+	ctx := Detach(parent)
+	cancel()
+	if ctx.Err() != nil {
+		t.Fatalf("expected not canceled with the parent got %v", ctx.Err())
+	}
+	if _, ok := ctx.Deadline(); ok {
+		t.Fatal("expected no deadline")
+	}
+	if v := ctx.Value(testKey{}); v != "value" {
+		t.Fatalf("expected the value of the parent got %v", v)
+	}
+}
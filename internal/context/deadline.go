@@ -0,0 +1,115 @@
+package context
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ExtendableContext is a context with the deadline which can be extended or removed
+// after it is created, but never beyond the deadline of the parent.
+type ExtendableContext struct {
+	context.Context
+
+	mu       sync.Mutex
+	deadline time.Time
+	timer    *time.Timer
+	// gen invalidates the timers stopped too late by Extend.
+	gen  uint64
+	done chan struct{}
+	err  error
+}
+
+// WithExtendableDeadline returns a copy of parent with the deadline, which is capped
+// by the deadline of the parent, and can be replaced by Extend later.
+func WithExtendableDeadline(parent context.Context, deadline time.Time) (*ExtendableContext, context.CancelFunc) {
+	c := &ExtendableContext{Context: parent, done: make(chan struct{})}
+	c.mu.Lock()
+	c.reset(deadline)
+	c.mu.Unlock()
+	stop := make(chan struct{})
+	go func() {
+		select {
+		case <-parent.Done():
+			c.finish(0, parent.Err(), true)
+		case <-stop:
+		case <-c.done:
+		}
+	}()
+	var once sync.Once
+	return c, func() {
+		once.Do(func() {
+			close(stop)
+			c.finish(0, context.Canceled, true)
+		})
+	}
+}
+
+// reset replaces the deadline and the timer, the zero deadline removes it.
+func (c *ExtendableContext) reset(deadline time.Time) {
+	if d, ok := c.Context.Deadline(); ok && (deadline.IsZero() || d.Before(deadline)) {
+		// the deadline of the parent fires the parent
+		deadline = time.Time{}
+	}
+	if c.timer != nil {
+		c.timer.Stop()
+		c.timer = nil
+	}
+	c.gen++
+	c.deadline = deadline
+	if deadline.IsZero() {
+		return
+	}
+	gen := c.gen
+	c.timer = time.AfterFunc(time.Until(deadline), func() {
+		c.finish(gen, context.DeadlineExceeded, false)
+	})
+}
+
+// finish closes the context unless it is a stale timer of the replaced deadline.
+func (c *ExtendableContext) finish(gen uint64, err error, force bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil || (!force && gen != c.gen) {
+		return
+	}
+	if c.timer != nil {
+		c.timer.Stop()
+	}
+	c.err = err
+	close(c.done)
+}
+
+// Extend replaces the deadline, the zero deadline removes it, so the deadline of the
+// parent applies only. It reports false if the context is already done.
+func (c *ExtendableContext) Extend(deadline time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return false
+	}
+	c.reset(deadline)
+	return true
+}
+
+// Deadline implements context.Context.
+func (c *ExtendableContext) Deadline() (time.Time, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.deadline.IsZero() {
+		return c.deadline, true
+	}
+	return c.Context.Deadline()
+}
+
+// Done implements context.Context.
+func (c *ExtendableContext) Done() <-chan struct{} {
+	return c.done
+}
+
+// Err implements context.Context.
+func (c *ExtendableContext) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
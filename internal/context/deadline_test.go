@@ -0,0 +1,54 @@
+package context
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestExtendableDeadline(t *testing.T) {
+	ctx, cancel := WithExtendableDeadline(context.Background(), time.Now().Add(20*time.Millisecond))
+	defer cancel()
+	if !ctx.Extend(time.Now().Add(time.Hour)) {
+		t.Fatal("expected extended")
+	}
+	select {
+	case <-ctx.Done():
+		t.Fatal("expected the extended deadline")
+	case <-time.After(50 * time.Millisecond):
+	}
+	// removes the deadline
+	ctx.Extend(time.Time{})
+	if _, ok := ctx.Deadline(); ok {
+		t.Fatal("expected no deadline")
+	}
+	ctx.Extend(time.Now().Add(10 * time.Millisecond))
+	<-ctx.Done()
+	if ctx.Err() != context.DeadlineExceeded || ctx.Extend(time.Now().Add(time.Hour)) {
+		t.Fatalf("expected the deadline exceeded got %v", ctx.Err())
+	}
+}
+
+func TestExtendableDeadlineParent(t *testing.T) {
+	parent, cancelParent := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancelParent()
+	ctx, cancel := WithExtendableDeadline(parent, time.Now().Add(10*time.Millisecond))
+	defer cancel()
+	// never exceeds the deadline of the parent
+	ctx.Extend(time.Now().Add(time.Hour))
+	pd, _ := parent.Deadline()
+	if d, ok := ctx.Deadline(); !ok || !d.Equal(pd) {
+		t.Fatalf("got deadline %v want %v", d, pd)
+	}
+	<-ctx.Done()
+	if ctx.Err() != context.DeadlineExceeded {
+		t.Fatalf("expected the deadline exceeded got %v", ctx.Err())
+	}
+
+	ctx, cancel = WithExtendableDeadline(context.Background(), time.Now().Add(time.Hour))
+	cancel()
+	<-ctx.Done()
+	if ctx.Err() != context.Canceled {
+		t.Fatalf("expected canceled got %v", ctx.Err())
+	}
+}
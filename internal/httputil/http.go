@@ -57,7 +57,9 @@ func GRPCCodeFromStatus(code int) codes.Code {
 		return codes.NotFound
 	case http.StatusConflict:
 		return codes.Aborted
-	case http.StatusTooManyRequests:
+	case http.StatusPreconditionFailed:
+		return codes.FailedPrecondition
+	case http.StatusTooManyRequests, http.StatusRequestHeaderFieldsTooLarge:
 		return codes.ResourceExhausted
 	case http.StatusInternalServerError:
 		return codes.Internal
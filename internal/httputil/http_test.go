@@ -1,6 +1,11 @@
 package httputil
 
-import "testing"
+import (
+	"net/http"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+)
 
 func TestContentSubtype(t *testing.T) {
 	tests := []struct {
@@ -25,3 +30,22 @@ func TestContentSubtype(t *testing.T) {
 		})
 	}
 }
+
+func TestGRPCCodeFromStatus(t *testing.T) {
+	tests := []struct {
+		status int
+		want   codes.Code
+	}{
+		{http.StatusOK, codes.OK},
+		{http.StatusTooManyRequests, codes.ResourceExhausted},
+		{http.StatusRequestHeaderFieldsTooLarge, codes.ResourceExhausted},
+		{http.StatusServiceUnavailable, codes.Unavailable},
+		{StatusClientClosed, codes.Canceled},
+		{http.StatusTeapot, codes.Unknown},
+	}
+	for _, test := range tests {
+		if got := GRPCCodeFromStatus(test.status); got != test.want {
+			t.Errorf("%d: want %v got %v", test.status, test.want, got)
+		}
+	}
+}
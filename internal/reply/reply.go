@@ -0,0 +1,32 @@
+package reply
+
+import (
+	"fmt"
+	"reflect"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// Set sets the reply returned by the middleware instead of the invoker into the reply
+// of the call, e.g. a cached or batched reply. The messages are merged into the reset
+// reply rather than copied, and the reply of another type is an error.
+func Set(reply, out interface{}) error {
+	if out == nil || reply == out {
+		return nil
+	}
+	if dst, ok := reply.(proto.Message); ok {
+		src, ok := out.(proto.Message)
+		if !ok || dst.ProtoReflect().Descriptor() != src.ProtoReflect().Descriptor() {
+			return fmt.Errorf("reply: %T is not the reply type %T", out, reply)
+		}
+		proto.Reset(dst)
+		proto.Merge(dst, src)
+		return nil
+	}
+	dst, src := reflect.ValueOf(reply), reflect.ValueOf(out)
+	if dst.Kind() != reflect.Ptr || dst.Type() != src.Type() || dst.IsNil() || src.IsNil() {
+		return fmt.Errorf("reply: %T is not the reply type %T", out, reply)
+	}
+	dst.Elem().Set(src.Elem())
+	return nil
+}
@@ -0,0 +1,33 @@
+package reply
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestSet(t *testing.T) {
+	reply := wrapperspb.String("stale")
+	if err := Set(reply, wrapperspb.String("cached")); err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(reply, wrapperspb.String("cached")) {
+		t.Fatalf("expected the cached reply got %v", reply)
+	}
+	if err := Set(reply, wrapperspb.Int32(1)); err == nil {
+		t.Fatal("expected the error of the mismatched message")
+	}
+
+	type user struct{ Name string }
+	u := &user{}
+	if err := Set(u, &user{Name: "kratos"}); err != nil || u.Name != "kratos" {
+		t.Fatalf("expected the copied reply got %v %v", u, err)
+	}
+	if err := Set(u, "kratos"); err == nil {
+		t.Fatal("expected the error of the mismatched type")
+	}
+	if err := Set(u, nil); err != nil {
+		t.Fatal(err)
+	}
+}
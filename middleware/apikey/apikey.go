@@ -0,0 +1,207 @@
+package apikey
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/clock"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	"golang.org/x/sync/singleflight"
+)
+
+const defaultHeader = "x-api-key"
+
+// Quota is the quota of an API key, which allows Limit requests per Period.
+type Quota struct {
+	Limit  int
+	Period time.Duration
+}
+
+// Store looks up the quota of the API key, it returns nil if the key is invalid.
+type Store interface {
+	Lookup(ctx context.Context, key string) (*Quota, error)
+}
+
+type apiKey struct{}
+
+// NewContext returns a new Context that carries the API key.
+func NewContext(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, apiKey{}, key)
+}
+
+// FromContext returns the API key stored in ctx, if any.
+func FromContext(ctx context.Context) (key string, ok bool) {
+	key, ok = ctx.Value(apiKey{}).(string)
+	return
+}
+
+// Option is API key option.
+type Option func(*options)
+
+type options struct {
+	header   string
+	cacheTTL time.Duration
+	clock    clock.Clock
+}
+
+// WithHeader with the API key header or metadata key, the default is x-api-key.
+func WithHeader(key string) Option {
+	return func(o *options) {
+		o.header = key
+	}
+}
+
+// WithCacheTTL with the TTL of the cached lookups of the valid keys, the invalid keys
+// are not cached, the default is 1 minute.
+func WithCacheTTL(ttl time.Duration) Option {
+	return func(o *options) {
+		o.cacheTTL = ttl
+	}
+}
+
+// WithClock with the clock of the cache and the rate limit, the default is the real clock.
+func WithClock(c clock.Clock) Option {
+	return func(o *options) {
+		o.clock = c
+	}
+}
+
+type entry struct {
+	quota   *Quota
+	expires time.Time
+	// the fixed window of the rate limit
+	start time.Time
+	count int
+}
+
+// cache is the cache of the valid keys, the keys expired after the TTL
+// and out of their rate limit window are swept once per TTL.
+type cache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]*entry
+	swept   time.Time
+}
+
+// get returns the entry of the key if it is not expired.
+func (c *cache) get(key string, now time.Time) (*entry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok || now.After(e.expires) {
+		return nil, false
+	}
+	return e, true
+}
+
+// set caches the quota of the key, the rate limit window of the cached key is kept.
+func (c *cache) set(key string, quota *Quota, now time.Time) *entry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sweep(now)
+	e, ok := c.entries[key]
+	if !ok {
+		e = &entry{}
+		c.entries[key] = e
+	}
+	e.quota = quota
+	e.expires = now.Add(c.ttl)
+	return e
+}
+
+func (c *cache) delete(key string) {
+	c.mu.Lock()
+	delete(c.entries, key)
+	c.mu.Unlock()
+}
+
+func (c *cache) sweep(now time.Time) {
+	if now.Sub(c.swept) < c.ttl {
+		return
+	}
+	c.swept = now
+	for key, e := range c.entries {
+		if now.After(e.expires) && now.Sub(e.start) >= e.quota.Period {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// Server is a server middleware which authenticates the requests by the API key,
+// and limits the rate of each key by its quota, the responses have the
+// X-RateLimit-Limit, X-RateLimit-Remaining and X-RateLimit-Reset headers.
+// The concurrent lookups of the same key are collapsed into one.
+func Server(store Store, opts ...Option) middleware.Middleware {
+	options := options{
+		header:   defaultHeader,
+		cacheTTL: time.Minute,
+		clock:    clock.New(),
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+	var (
+		c = &cache{ttl: options.cacheTTL, entries: make(map[string]*entry)}
+		g singleflight.Group
+	)
+	lookup := func(ctx context.Context, key string) (*Quota, error) {
+		var executed bool
+		v, err, _ := g.Do(key, func() (interface{}, error) {
+			executed = true
+			return store.Lookup(ctx, key)
+		})
+		if err != nil && !executed {
+			// the shared lookup may fail by the context of another request
+			return store.Lookup(ctx, key)
+		}
+		quota, _ := v.(*Quota)
+		return quota, err
+	}
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			key := transport.RequestHeader(ctx, options.header)
+			if key == "" {
+				return nil, errors.Unauthorized("MISSING_API_KEY", "missing api key")
+			}
+			now := options.clock.Now()
+			e, ok := c.get(key, now)
+			if !ok {
+				quota, err := lookup(ctx, key)
+				if err != nil {
+					return nil, errors.ServiceUnavailable("API_KEY_LOOKUP", err.Error())
+				}
+				if quota == nil {
+					c.delete(key)
+					return nil, errors.Unauthorized("INVALID_API_KEY", "invalid api key")
+				}
+				e = c.set(key, quota, now)
+			}
+
+			c.mu.Lock()
+			quota := e.quota
+			if now.Sub(e.start) >= quota.Period {
+				e.start = now
+				e.count = 0
+			}
+			allowed := e.count < quota.Limit
+			if allowed {
+				e.count++
+			}
+			remaining := quota.Limit - e.count
+			reset := e.start.Add(quota.Period).Sub(now)
+			c.mu.Unlock()
+
+			_ = transport.SetHeader(ctx, "X-RateLimit-Limit", strconv.Itoa(quota.Limit))
+			_ = transport.SetHeader(ctx, "X-RateLimit-Remaining", strconv.Itoa(remaining))
+			_ = transport.SetHeader(ctx, "X-RateLimit-Reset", strconv.Itoa(int((reset+time.Second-1)/time.Second)))
+			if !allowed {
+				return nil, errors.New(429, "QUOTA_EXCEEDED", "api key quota exceeded")
+			}
+			return handler(NewContext(ctx, key), req)
+		}
+	}
+}
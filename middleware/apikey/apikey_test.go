@@ -0,0 +1,152 @@
+package apikey
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/clock"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/transport/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+type testStore struct {
+	lookups int
+}
+
+func (s *testStore) Lookup(ctx context.Context, key string) (*Quota, error) {
+	s.lookups++
+	if key != "valid" {
+		return nil, nil
+	}
+	return &Quota{Limit: 2, Period: time.Minute}, nil
+}
+
+func TestServer(t *testing.T) {
+	store := &testStore{}
+	var key string
+	next := Server(store)(func(ctx context.Context, req interface{}) (interface{}, error) {
+		key, _ = FromContext(ctx)
+		return nil, nil
+	})
+	call := func(key string) error {
+		ctx := grpc.NewServerContext(context.Background(), grpc.ServerInfo{FullMethod: "/test.Test/Test"})
+		if key != "" {
+			ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(defaultHeader, key))
+		}
+		_, err := next(ctx, nil)
+		return err
+	}
+	if err := call(""); !errors.IsUnauthorized(err) {
+		t.Fatalf("expected unauthorized got %v", err)
+	}
+	if err := call("invalid"); !errors.IsUnauthorized(err) {
+		t.Fatalf("expected unauthorized got %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := call("valid"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if key != "valid" {
+		t.Fatalf("expected valid got %s", key)
+	}
+	if err := call("valid"); errors.Code(err) != 429 {
+		t.Fatalf("expected too many requests got %v", err)
+	}
+	if store.lookups != 2 {
+		t.Fatalf("expected the lookups cached got %d", store.lookups)
+	}
+	// the invalid keys are looked up again
+	if err := call("invalid"); !errors.IsUnauthorized(err) {
+		t.Fatalf("expected unauthorized got %v", err)
+	}
+	if store.lookups != 3 {
+		t.Fatalf("expected the invalid keys not cached got %d", store.lookups)
+	}
+}
+
+func TestServerWindow(t *testing.T) {
+	c := clock.NewFake()
+	next := Server(&testStore{}, WithClock(c))(func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	})
+	ctx := grpc.NewServerContext(context.Background(), grpc.ServerInfo{FullMethod: "/test.Test/Test"})
+	ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(defaultHeader, "valid"))
+	for i := 0; i < 2; i++ {
+		if _, err := next(ctx, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := next(ctx, nil); errors.Code(err) != 429 {
+		t.Fatalf("expected too many requests got %v", err)
+	}
+	c.Advance(time.Minute)
+	if _, err := next(ctx, nil); err != nil {
+		t.Fatalf("expected allowed in the next window got %v", err)
+	}
+}
+
+// blockingStore blocks the lookups until released.
+type blockingStore struct {
+	lookups int32
+	release chan struct{}
+}
+
+func (s *blockingStore) Lookup(ctx context.Context, key string) (*Quota, error) {
+	atomic.AddInt32(&s.lookups, 1)
+	<-s.release
+	return &Quota{Limit: 10, Period: time.Minute}, nil
+}
+
+func TestServerConcurrentLookup(t *testing.T) {
+	store := &blockingStore{release: make(chan struct{})}
+	next := Server(store)(func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	})
+	ctx := grpc.NewServerContext(context.Background(), grpc.ServerInfo{FullMethod: "/test.Test/Test"})
+	ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(defaultHeader, "valid"))
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := next(ctx, nil); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	for atomic.LoadInt32(&store.lookups) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(store.release)
+	wg.Wait()
+	if n := atomic.LoadInt32(&store.lookups); n != 1 {
+		t.Fatalf("expected the key looked up once got %d", n)
+	}
+}
+
+func TestCacheSweep(t *testing.T) {
+	var (
+		now   = time.Now()
+		quota = &Quota{Limit: 1, Period: time.Minute}
+		c     = &cache{ttl: time.Minute, entries: make(map[string]*entry)}
+	)
+	c.set("expired", quota, now)
+	c.set("limited", quota, now)
+	c.entries["limited"].start = now.Add(2 * time.Minute)
+	c.set("valid", quota, now.Add(2*time.Minute))
+	if _, ok := c.entries["expired"]; ok {
+		t.Error("expected the expired key swept")
+	}
+	if _, ok := c.entries["limited"]; !ok {
+		t.Error("expected the key in the rate limit window kept")
+	}
+	if _, ok := c.get("valid", now.Add(2*time.Minute)); !ok {
+		t.Error("expected the valid key cached")
+	}
+}
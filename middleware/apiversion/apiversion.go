@@ -0,0 +1,209 @@
+package apiversion
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+const defaultHeader = "x-api-version"
+
+// Range is the supported version range of an operation, both bounds are inclusive,
+// and the empty bound means unbounded, e.g. {Min: "1.2"} supports 1.2 and later.
+type Range struct {
+	Min string
+	Max string
+}
+
+func (r Range) String() string {
+	min, max := r.Min, r.Max
+	if min == "" {
+		min = "*"
+	}
+	if max == "" {
+		max = "*"
+	}
+	return "[" + min + ", " + max + "]"
+}
+
+// Policy is the supported version ranges by operation, the operation is the full method
+// for gRPC and the path template for HTTP, the operations not in the policy are not checked.
+type Policy map[string]Range
+
+type versionKey struct{}
+
+// NewContext returns a new Context that carries the version of the request.
+func NewContext(ctx context.Context, version string) context.Context {
+	return context.WithValue(ctx, versionKey{}, version)
+}
+
+// FromContext returns the version of the request stored in ctx, if any.
+func FromContext(ctx context.Context) (version string, ok bool) {
+	version, ok = ctx.Value(versionKey{}).(string)
+	return
+}
+
+// Option is apiversion option.
+type Option func(*options)
+
+type options struct {
+	header     string
+	required   bool
+	code       int
+	fallback   *Range
+	deprecated *Range
+}
+
+// WithHeader with the version header or metadata key, the default is x-api-version.
+func WithHeader(key string) Option {
+	return func(o *options) {
+		o.header = key
+	}
+}
+
+// WithRequired with whether the version is required, the request without the version
+// is rejected if required, otherwise it is treated as the latest version by default.
+func WithRequired(required bool) Option {
+	return func(o *options) {
+		o.required = required
+	}
+}
+
+// WithCode with the error code of the unsupported versions,
+// the default is 412 which is mapped to FailedPrecondition of gRPC.
+func WithCode(code int) Option {
+	return func(o *options) {
+		o.code = code
+	}
+}
+
+// WithDefaultRange with the supported range of the operations not in the policy.
+func WithDefaultRange(r Range) Option {
+	return func(o *options) {
+		o.fallback = &r
+	}
+}
+
+// WithDeprecated with the range of the deprecated versions, the responses of which
+// have the Deprecation and Warning headers.
+func WithDeprecated(r Range) Option {
+	return func(o *options) {
+		o.deprecated = &r
+	}
+}
+
+// Server is a server middleware which rejects the requests whose version is out of
+// the supported range of the operation, and stores the version in the context.
+func Server(policy Policy, opts ...Option) middleware.Middleware {
+	options := options{
+		header: defaultHeader,
+		code:   http.StatusPreconditionFailed,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			version := transport.RequestHeader(ctx, options.header)
+			operation := transport.Operation(ctx)
+			rng, checked := policy[operation]
+			if !checked && options.fallback != nil {
+				rng, checked = *options.fallback, true
+			}
+			if version == "" {
+				if checked && options.required {
+					return nil, errors.New(options.code, "API_VERSION_REQUIRED",
+						fmt.Sprintf("missing %s, supported versions: %s", options.header, rng)).
+						WithMetadata(rng.metadata())
+				}
+				return handler(ctx, req)
+			}
+			if _, err := parse(version); err != nil {
+				return nil, errors.BadRequest("API_VERSION_INVALID", err.Error())
+			}
+			if checked {
+				if ok, _ := rng.Contains(version); !ok {
+					return nil, errors.New(options.code, "API_VERSION_UNSUPPORTED",
+						fmt.Sprintf("unsupported version %s, supported versions: %s", version, rng)).
+						WithMetadata(rng.metadata())
+				}
+			}
+			if options.deprecated != nil {
+				if ok, _ := options.deprecated.Contains(version); ok {
+					_ = transport.SetHeader(ctx, "Deprecation", "true")
+					_ = transport.SetHeader(ctx, "Warning", fmt.Sprintf("299 - \"API version %s is deprecated\"", version))
+				}
+			}
+			return handler(NewContext(ctx, version), req)
+		}
+	}
+}
+
+// Contains reports whether the version is in the range.
+func (r Range) Contains(version string) (bool, error) {
+	if r.Min != "" {
+		c, err := Compare(version, r.Min)
+		if err != nil || c < 0 {
+			return false, err
+		}
+	}
+	if r.Max != "" {
+		c, err := Compare(version, r.Max)
+		if err != nil || c > 0 {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func (r Range) metadata() map[string]string {
+	return map[string]string{"min": r.Min, "max": r.Max}
+}
+
+// Compare compares the versions such as v1, 1.2 and 1.2.3, the missing parts are
+// treated as zero, it returns -1 if a < b, 0 if a == b and +1 if a > b.
+func Compare(a, b string) (int, error) {
+	x, err := parse(a)
+	if err != nil {
+		return 0, err
+	}
+	y, err := parse(b)
+	if err != nil {
+		return 0, err
+	}
+	for len(x) < len(y) {
+		x = append(x, 0)
+	}
+	for len(y) < len(x) {
+		y = append(y, 0)
+	}
+	for i := range x {
+		if x[i] < y[i] {
+			return -1, nil
+		}
+		if x[i] > y[i] {
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+func parse(version string) ([]int, error) {
+	s := strings.TrimPrefix(strings.TrimSpace(version), "v")
+	parts := strings.Split(s, ".")
+	res := make([]int, 0, len(parts))
+	for _, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid version: %q", version)
+		}
+		res = append(res, n)
+	}
+	return res, nil
+}
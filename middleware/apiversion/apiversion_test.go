@@ -0,0 +1,79 @@
+package apiversion
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/transport/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1", "1.0.0", 0},
+		{"v1.2", "1.10", -1},
+		{"2", "1.9.9", 1},
+	}
+	for _, test := range tests {
+		got, err := Compare(test.a, test.b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != test.want {
+			t.Errorf("%s %s: expected %d got %d", test.a, test.b, test.want, got)
+		}
+	}
+	if _, err := Compare("1.x", "1"); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestServer(t *testing.T) {
+	policy := Policy{"/test.Test/Test": {Min: "1.2", Max: "2"}}
+	tests := []struct {
+		version  string
+		required bool
+		check    func(error) bool
+	}{
+		{"1.5", false, func(err error) bool { return err == nil }},
+		{"", false, func(err error) bool { return err == nil }},
+		{"", true, errors.IsPreconditionFailed},
+		{"1.1", false, errors.IsPreconditionFailed},
+		{"2.1", false, errors.IsPreconditionFailed},
+		{"latest", false, errors.IsBadRequest},
+	}
+	for _, test := range tests {
+		next := Server(policy, WithRequired(test.required))(func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, nil
+		})
+		ctx := grpc.NewServerContext(context.Background(), grpc.ServerInfo{FullMethod: "/test.Test/Test"})
+		if test.version != "" {
+			ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("x-api-version", test.version))
+		}
+		if _, err := next(ctx, nil); !test.check(err) {
+			t.Errorf("%q: unexpected error %v", test.version, err)
+		}
+	}
+}
+
+func TestDefaultRange(t *testing.T) {
+	var version string
+	next := Server(nil, WithDefaultRange(Range{Min: "2"}), WithCode(400))(func(ctx context.Context, req interface{}) (interface{}, error) {
+		version, _ = FromContext(ctx)
+		return nil, nil
+	})
+	ctx := grpc.NewServerContext(context.Background(), grpc.ServerInfo{FullMethod: "/test.Test/Other"})
+	if _, err := next(metadata.NewIncomingContext(ctx, metadata.Pairs("x-api-version", "1")), nil); !errors.IsBadRequest(err) {
+		t.Fatalf("expected bad request got %v", err)
+	}
+	if _, err := next(metadata.NewIncomingContext(ctx, metadata.Pairs("x-api-version", "2.1")), nil); err != nil {
+		t.Fatal(err)
+	}
+	if version != "2.1" {
+		t.Fatalf("expected 2.1 got %s", version)
+	}
+}
@@ -0,0 +1,214 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	nethttp "net/http"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/encoding"
+	"github.com/go-kratos/kratos/v2/errors"
+	ic "github.com/go-kratos/kratos/v2/internal/context"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/middleware/spiffe"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/go-kratos/kratos/v2/transport/grpc"
+	"github.com/go-kratos/kratos/v2/transport/http"
+	"google.golang.org/grpc/peer"
+)
+
+// Record is an audit record of an operation.
+type Record struct {
+	Actor     string
+	Operation string
+	Time      time.Time
+	// Peer is the remote IP of the request.
+	Peer string
+	// Request is the JSON of the request payload, which is empty unless WithPayload.
+	Request string
+	// Code is the HTTP status code of the outcome, 200 if succeeded.
+	Code   int
+	Reason string
+	// Prev and Hash chain the records, which are set by the ChainSink.
+	Prev string
+	Hash string
+}
+
+// Sink is the audit record sink.
+type Sink interface {
+	Write(ctx context.Context, r *Record) error
+}
+
+// ActorFunc returns the actor of the request, e.g. the subject of the JWT,
+// the default is the SPIFFE ID verified by the spiffe middleware.
+type ActorFunc func(ctx context.Context) string
+
+func defaultActor(ctx context.Context) string {
+	id, _ := spiffe.FromContext(ctx)
+	return id
+}
+
+// Option is audit option.
+type Option func(*options)
+
+type options struct {
+	actor      ActorFunc
+	operations map[string]struct{}
+	payload    bool
+	redacted   map[string]struct{}
+	timeout    time.Duration
+	logger     log.Logger
+}
+
+// WithActor with the actor func, the audit middleware should run after
+// the auth middleware so that the actor is known.
+func WithActor(f ActorFunc) Option {
+	return func(o *options) {
+		o.actor = f
+	}
+}
+
+// WithOperations with the audited operations, all operations are audited if not specified.
+// The operation is the full method for gRPC and the path template for HTTP.
+func WithOperations(ops ...string) Option {
+	return func(o *options) {
+		o.operations = make(map[string]struct{}, len(ops))
+		for _, op := range ops {
+			o.operations[op] = struct{}{}
+		}
+	}
+}
+
+// WithPayload with whether the request payload is written to the record, it is left out
+// by default since the payload may carry the secrets, e.g. the passwords and the tokens.
+func WithPayload(include bool) Option {
+	return func(o *options) {
+		o.payload = include
+	}
+}
+
+// WithRedacted with the redacted field names of the request payload written by WithPayload.
+func WithRedacted(fields ...string) Option {
+	return func(o *options) {
+		o.redacted = make(map[string]struct{}, len(fields))
+		for _, f := range fields {
+			o.redacted[f] = struct{}{}
+		}
+	}
+}
+
+// WithTimeout with the timeout of writing a record to the sink, the default is 5 seconds.
+func WithTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.timeout = d
+	}
+}
+
+// WithLogger with the logger of the failures of writing the records to the sink.
+func WithLogger(logger log.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// Server is a server middleware which writes the audit records to the sink. The record is
+// written with the values of the request context but not its cancellation, as the request
+// may be canceled or timed out, bounded by the timeout, and the failures are logged.
+func Server(sink Sink, opts ...Option) middleware.Middleware {
+	options := options{
+		actor:   defaultActor,
+		timeout: 5 * time.Second,
+		logger:  log.DefaultLogger,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			var addr string
+			if _, ok := grpc.FromServerContext(ctx); ok {
+				if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+					addr = p.Addr.String()
+				}
+			} else if info, ok := http.FromServerContext(ctx); ok {
+				addr = info.Request.RemoteAddr
+			}
+			operation := transport.Operation(ctx)
+			if options.operations != nil {
+				if _, ok := options.operations[operation]; !ok {
+					return handler(ctx, req)
+				}
+			}
+			if host, _, err := net.SplitHostPort(addr); err == nil {
+				addr = host
+			}
+			record := &Record{
+				Actor:     options.actor(ctx),
+				Operation: operation,
+				Time:      time.Now(),
+				Peer:      addr,
+				Code:      errors.UnknownCode,
+			}
+			if options.payload {
+				record.Request = summary(req, options.redacted)
+			}
+			// the record is written even if the handler panics
+			defer func() {
+				wctx, cancel := context.WithTimeout(ic.Detach(ctx), options.timeout)
+				defer cancel()
+				if err := sink.Write(wctx, record); err != nil {
+					log.WithContext(ctx, options.logger).Log(log.LevelError,
+						"kind", "server",
+						"component", "audit",
+						"operation", record.Operation,
+						"actor", record.Actor,
+						"error", err.Error(),
+					)
+				}
+			}()
+			reply, err := handler(ctx, req)
+			if err != nil {
+				record.Code = errors.Code(err)
+				record.Reason = errors.Reason(err)
+			} else {
+				record.Code = nethttp.StatusOK
+			}
+			return reply, err
+		}
+	}
+}
+
+func summary(req interface{}, redacted map[string]struct{}) string {
+	data, err := encoding.GetCodec("json").Marshal(req)
+	if err != nil || len(redacted) == 0 {
+		return string(data)
+	}
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return string(data)
+	}
+	if data, err = json.Marshal(redact(v, redacted)); err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+func redact(v interface{}, redacted map[string]struct{}) interface{} {
+	switch x := v.(type) {
+	case map[string]interface{}:
+		for k, v := range x {
+			if _, ok := redacted[k]; ok {
+				x[k] = "***"
+				continue
+			}
+			x[k] = redact(v, redacted)
+		}
+	case []interface{}:
+		for i, v := range x {
+			x[i] = redact(v, redacted)
+		}
+	}
+	return v
+}
@@ -0,0 +1,160 @@
+package audit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/transport/grpc"
+	transhttp "github.com/go-kratos/kratos/v2/transport/http"
+	"github.com/gorilla/mux"
+	"google.golang.org/grpc/peer"
+)
+
+type testRequest struct {
+	Name     string `json:"name"`
+	Password string `json:"password"`
+}
+
+func TestServer(t *testing.T) {
+	sink := NewMemorySink()
+	actor := func(ctx context.Context) string { return "kratos" }
+	next := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	}
+	h := Server(sink, WithActor(actor), WithOperations("/test.Test/Login"), WithRedacted("password"))(next)
+	for _, method := range []string{"/test.Test/Login", "/test.Test/Other"} {
+		ctx := grpc.NewServerContext(context.Background(), grpc.ServerInfo{FullMethod: method})
+		if _, err := h(ctx, &testRequest{Name: "name", Password: "secret"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	records := sink.Records()
+	if len(records) != 1 {
+		t.Fatalf("expected 1 record got %d", len(records))
+	}
+	if r := records[0]; r.Actor != "kratos" || r.Code != 200 || r.Request != "" {
+		t.Fatalf("unexpected record %+v", r)
+	}
+}
+
+func TestServerPayload(t *testing.T) {
+	sink := NewMemorySink()
+	next := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	}
+	h := Server(sink, WithPayload(true), WithRedacted("password"))(next)
+	ctx := grpc.NewServerContext(context.Background(), grpc.ServerInfo{FullMethod: "/test.Test/Login"})
+	if _, err := h(ctx, &testRequest{Name: "name", Password: "secret"}); err != nil {
+		t.Fatal(err)
+	}
+	r := sink.Records()[0]
+	if !strings.Contains(r.Request, "name") || strings.Contains(r.Request, "secret") {
+		t.Fatalf("expected the redacted payload got %s", r.Request)
+	}
+}
+
+func TestServerHTTPOperation(t *testing.T) {
+	sink := NewMemorySink()
+	h := Server(sink, WithOperations("/v1/users/{id}"))(func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	})
+	r := mux.NewRouter()
+	r.HandleFunc("/v1/users/{id}", func(w http.ResponseWriter, req *http.Request) {
+		ctx := transhttp.NewServerContext(req.Context(), transhttp.ServerInfo{Request: req, Response: w})
+		if _, err := h(ctx, nil); err != nil {
+			t.Error(err)
+		}
+	})
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodDelete, "/v1/users/1", nil))
+	records := sink.Records()
+	if len(records) != 1 || records[0].Operation != "/v1/users/{id}" {
+		t.Fatalf("expected the path template got %+v", records)
+	}
+}
+
+func TestChainSink(t *testing.T) {
+	memory := NewMemorySink()
+	next := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, errors.Forbidden("FORBIDDEN", "forbidden")
+	}
+	h := Server(NewChainSink(memory))(next)
+	ctx := grpc.NewServerContext(context.Background(), grpc.ServerInfo{FullMethod: "/test.Test/Login"})
+	for i := 0; i < 2; i++ {
+		if _, err := h(ctx, &testRequest{Name: "name"}); !errors.IsForbidden(err) {
+			t.Fatalf("expected forbidden got %v", err)
+		}
+	}
+	records := memory.Records()
+	if len(records) != 2 || records[0].Code != 403 {
+		t.Fatalf("unexpected records %+v", records)
+	}
+	if records[1].Prev != records[0].Hash || records[1].Hash != Hash(records[1]) {
+		t.Fatal("expected the records chained")
+	}
+	records[0].Actor = "tampered"
+	if Hash(records[0]) == records[1].Prev {
+		t.Fatal("expected the tampered record evident")
+	}
+}
+
+func TestServerNilPeerAddr(t *testing.T) {
+	sink := NewMemorySink()
+	h := Server(sink)(func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	})
+	ctx := grpc.NewServerContext(context.Background(), grpc.ServerInfo{FullMethod: "/test.Test/Login"})
+	ctx = peer.NewContext(ctx, &peer.Peer{})
+	if _, err := h(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+	if records := sink.Records(); len(records) != 1 || records[0].Peer != "" {
+		t.Fatalf("unexpected records %+v", records)
+	}
+}
+
+type testSink struct {
+	err      error
+	ctxErr   error
+	deadline bool
+}
+
+func (s *testSink) Write(ctx context.Context, r *Record) error {
+	s.ctxErr = ctx.Err()
+	_, s.deadline = ctx.Deadline()
+	return s.err
+}
+
+type testLogger struct {
+	logs int
+}
+
+func (l *testLogger) Log(level log.Level, keyvals ...interface{}) error {
+	l.logs++
+	return nil
+}
+
+func TestServerWrite(t *testing.T) {
+	sink := &testSink{err: errors.ServiceUnavailable("SINK_DOWN", "sink down")}
+	logger := &testLogger{}
+	h := Server(sink, WithLogger(logger), WithTimeout(time.Second))(func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx = grpc.NewServerContext(ctx, grpc.ServerInfo{FullMethod: "/test.Test/Login"})
+	cancel()
+	if _, err := h(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+	if logger.logs != 1 {
+		t.Fatalf("expected the write failure logged got %d", logger.logs)
+	}
+	if sink.ctxErr != nil || !sink.deadline {
+		t.Fatalf("expected the write detached from the canceled request and bounded got %v", sink.ctxErr)
+	}
+}
@@ -0,0 +1,101 @@
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+var (
+	_ Sink = (*MemorySink)(nil)
+	_ Sink = (*logSink)(nil)
+	_ Sink = (*chainSink)(nil)
+)
+
+// MemorySink is an in-memory audit sink.
+type MemorySink struct {
+	mu      sync.Mutex
+	records []*Record
+}
+
+// NewMemorySink new an in-memory audit sink.
+func NewMemorySink() *MemorySink {
+	return &MemorySink{}
+}
+
+// Write appends the record.
+func (s *MemorySink) Write(ctx context.Context, r *Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = append(s.records, r)
+	return nil
+}
+
+// Records returns the written records.
+func (s *MemorySink) Records() []*Record {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*Record(nil), s.records...)
+}
+
+type logSink struct {
+	logger log.Logger
+}
+
+// NewLogSink new an audit sink which writes the records to the logger.
+func NewLogSink(logger log.Logger) Sink {
+	return &logSink{logger: logger}
+}
+
+func (s *logSink) Write(ctx context.Context, r *Record) error {
+	return log.WithContext(ctx, s.logger).Log(log.LevelInfo,
+		"kind", "server",
+		"component", "audit",
+		"actor", r.Actor,
+		"operation", r.Operation,
+		"time", r.Time,
+		"peer", r.Peer,
+		"request", r.Request,
+		"code", r.Code,
+		"reason", r.Reason,
+		"hash", r.Hash,
+	)
+}
+
+type chainSink struct {
+	mu   sync.Mutex
+	sink Sink
+	prev string
+}
+
+// NewChainSink new an audit sink which chains the records by the hash of the previous
+// record before writing them to the sink, so that a modified or deleted record is evident.
+func NewChainSink(sink Sink) Sink {
+	return &chainSink{sink: sink}
+}
+
+func (s *chainSink) Write(ctx context.Context, r *Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r.Prev = s.prev
+	r.Hash = Hash(r)
+	if err := s.sink.Write(ctx, r); err != nil {
+		return err
+	}
+	s.prev = r.Hash
+	return nil
+}
+
+// Hash returns the hash of the record including the hash of the previous record,
+// which verifies the chain of the records.
+func Hash(r *Record) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n%s\n%s\n%s\n%s\n%d\n%s\n%s",
+		r.Actor, r.Operation, r.Time.UTC().Format(time.RFC3339Nano), r.Peer, r.Request, r.Code, r.Reason, r.Prev)
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,169 @@
+package batch
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport/grpc"
+	"github.com/go-kratos/kratos/v2/transport/http"
+)
+
+// Batcher merges the requests of an operation into a single underlying request,
+// and splits its reply into the replies of each request.
+type Batcher interface {
+	// Batchable reports whether the operation is batchable.
+	Batchable(operation string) bool
+	// Invoke invokes the merged request of the requests, and returns the replies
+	// and the errors of each request in the same order, e.g. for partial failures,
+	// or the error of the whole batch.
+	Invoke(ctx context.Context, operation string, reqs []interface{}) (replies []interface{}, errs []error, err error)
+}
+
+// Option is batch option.
+type Option func(*options)
+
+type options struct {
+	maxSize int
+}
+
+// WithMaxSize with the max size of a batch, the batch is invoked once it is full
+// without waiting for the window, the default zero means unlimited.
+func WithMaxSize(size int) Option {
+	return func(o *options) {
+		o.maxSize = size
+	}
+}
+
+type call struct {
+	ctx   context.Context
+	req   interface{}
+	done  chan struct{}
+	reply interface{}
+	err   error
+}
+
+type batch struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	calls   []*call
+	pending int
+}
+
+// Client is a client middleware which groups the calls to the same batchable operation
+// within the window into a single underlying request invoked by the batcher.
+// The operation is the full method for gRPC and the path pattern for HTTP.
+func Client(window time.Duration, batcher Batcher, opts ...Option) middleware.Middleware {
+	options := options{}
+	for _, o := range opts {
+		o(&options)
+	}
+	var (
+		mu      sync.Mutex
+		batches = make(map[string]*batch)
+	)
+	flush := func(operation string, b *batch) {
+		mu.Lock()
+		if batches[operation] != b {
+			// flushed already when it is full
+			mu.Unlock()
+			return
+		}
+		delete(batches, operation)
+		mu.Unlock()
+		invoke(b, operation, batcher)
+	}
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			var operation string
+			if info, ok := grpc.FromClientContext(ctx); ok {
+				operation = info.FullMethod
+			} else if info, ok := http.FromClientContext(ctx); ok {
+				operation = info.PathPattern
+			}
+			if !batcher.Batchable(operation) {
+				return handler(ctx, req)
+			}
+			c := &call{ctx: ctx, req: req, done: make(chan struct{})}
+			mu.Lock()
+			b, ok := batches[operation]
+			if !ok {
+				// the batch is not canceled by the first call, but by all the calls
+				bctx, cancel := context.WithCancel(detached{ctx})
+				b = &batch{ctx: bctx, cancel: cancel}
+				batches[operation] = b
+				time.AfterFunc(window, func() { flush(operation, b) })
+			}
+			b.calls = append(b.calls, c)
+			b.pending++
+			if options.maxSize > 0 && len(b.calls) >= options.maxSize {
+				delete(batches, operation)
+				go invoke(b, operation, batcher)
+			}
+			mu.Unlock()
+
+			select {
+			case <-ctx.Done():
+				mu.Lock()
+				b.pending--
+				if b.pending == 0 {
+					b.cancel()
+				}
+				mu.Unlock()
+				return nil, ctx.Err()
+			case <-c.done:
+				return c.reply, c.err
+			}
+		}
+	}
+}
+
+func invoke(b *batch, operation string, batcher Batcher) {
+	defer b.cancel()
+	calls := make([]*call, 0, len(b.calls))
+	reqs := make([]interface{}, 0, len(b.calls))
+	for _, c := range b.calls {
+		// skips the calls cancelled while waiting for the window
+		if c.ctx.Err() != nil {
+			continue
+		}
+		calls = append(calls, c)
+		reqs = append(reqs, c.req)
+	}
+	if len(calls) == 0 {
+		return
+	}
+	replies, errs, err := batcher.Invoke(b.ctx, operation, reqs)
+	for i, c := range calls {
+		switch {
+		case err != nil:
+			c.err = err
+		case i < len(errs) && errs[i] != nil:
+			c.err = errs[i]
+		case i < len(replies):
+			c.reply = replies[i]
+		default:
+			c.err = fmt.Errorf("batch: missing reply %d of %s", i, operation)
+		}
+		close(c.done)
+	}
+}
+
+// detached is a context with the values of the parent but without its cancellation.
+type detached struct {
+	context.Context
+}
+
+func (detached) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detached) Done() <-chan struct{} {
+	return nil
+}
+
+func (detached) Err() error {
+	return nil
+}
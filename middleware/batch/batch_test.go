@@ -0,0 +1,81 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/transport/grpc"
+)
+
+type testBatcher struct {
+	invoked int32
+}
+
+func (b *testBatcher) Batchable(operation string) bool {
+	return operation == "/test.Test/Get"
+}
+
+func (b *testBatcher) Invoke(ctx context.Context, operation string, reqs []interface{}) ([]interface{}, []error, error) {
+	atomic.AddInt32(&b.invoked, 1)
+	replies := make([]interface{}, len(reqs))
+	errs := make([]error, len(reqs))
+	for i, req := range reqs {
+		if req.(int) < 0 {
+			errs[i] = errors.New("not found")
+			continue
+		}
+		replies[i] = req.(int) * 10
+	}
+	return replies, errs, nil
+}
+
+func TestClient(t *testing.T) {
+	b := &testBatcher{}
+	next := Client(50*time.Millisecond, b)(func(ctx context.Context, req interface{}) (interface{}, error) {
+		t.Error("the batchable call should not be invoked by the handler")
+		return nil, nil
+	})
+	ctx := grpc.NewClientContext(context.Background(), grpc.ClientInfo{FullMethod: "/test.Test/Get"})
+
+	var wg sync.WaitGroup
+	for _, req := range []int{1, 2, -1} {
+		req := req
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			reply, err := next(ctx, req)
+			if req < 0 {
+				if err == nil {
+					t.Errorf("expected an error of %d", req)
+				}
+				return
+			}
+			if err != nil || reply != req*10 {
+				t.Errorf("expected %d got %v %v", req*10, reply, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := atomic.LoadInt32(&b.invoked); n != 1 {
+		t.Fatalf("expected invoked once got %d", n)
+	}
+}
+
+func TestClientCancel(t *testing.T) {
+	b := &testBatcher{}
+	next := Client(100*time.Millisecond, b)(nil)
+	ctx := grpc.NewClientContext(context.Background(), grpc.ClientInfo{FullMethod: "/test.Test/Get"})
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if _, err := next(ctx, 1); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded got %v", err)
+	}
+	time.Sleep(200 * time.Millisecond)
+	if n := atomic.LoadInt32(&b.invoked); n != 0 {
+		t.Fatalf("expected the cancelled batch not invoked got %d", n)
+	}
+}
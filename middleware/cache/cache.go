@@ -0,0 +1,211 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/clock"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/go-kratos/kratos/v2/transport/grpc"
+	"github.com/go-kratos/kratos/v2/transport/http"
+	"golang.org/x/sync/singleflight"
+	"google.golang.org/protobuf/proto"
+)
+
+// KeyFunc returns the cache key of the request, the requests are not
+// cached if it returns false, e.g. for write operations.
+type KeyFunc func(ctx context.Context, req interface{}) (string, bool)
+
+// Option is cache option.
+type Option func(*options)
+
+type options struct {
+	maxAge     time.Duration
+	stale      time.Duration
+	maxEntries int
+	clock      clock.Clock
+}
+
+// WithMaxAge with the duration the cached reply is fresh, the default is 1m.
+func WithMaxAge(d time.Duration) Option {
+	return func(o *options) {
+		o.maxAge = d
+	}
+}
+
+// WithStaleWhileRevalidate with the duration after the max age during which the stale
+// reply is served while a single background call refreshes it, the default is zero.
+func WithStaleWhileRevalidate(d time.Duration) Option {
+	return func(o *options) {
+		o.stale = d
+	}
+}
+
+// WithMaxEntries with the max number of the cached replies, the least recently used
+// ones are evicted, the default is 1000.
+func WithMaxEntries(n int) Option {
+	return func(o *options) {
+		o.maxEntries = n
+	}
+}
+
+// WithClock with the clock of the ages, the default is the real clock.
+func WithClock(c clock.Clock) Option {
+	return func(o *options) {
+		o.clock = c
+	}
+}
+
+type entry struct {
+	key     string
+	reply   interface{}
+	created time.Time
+}
+
+type cache struct {
+	opts options
+	g    singleflight.Group
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+}
+
+// Server is a server middleware which caches the successful replies by the operation and
+// the key, the fresh replies are served without calling the handler, and the stale ones
+// within the stale-while-revalidate window are served while refreshed in the background.
+func Server(keyFunc KeyFunc, opts ...Option) middleware.Middleware {
+	c := &cache{
+		opts: options{
+			maxAge:     time.Minute,
+			maxEntries: 1000,
+			clock:      clock.New(),
+		},
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+	}
+	for _, o := range opts {
+		o(&c.opts)
+	}
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			key, ok := keyFunc(ctx, req)
+			if !ok {
+				return handler(ctx, req)
+			}
+			if info, ok := grpc.FromServerContext(ctx); ok {
+				key = info.FullMethod + "/" + key
+			} else if info, ok := http.FromServerContext(ctx); ok {
+				key = info.Request.Method + " " + info.Request.URL.Path + "/" + key
+			}
+			if reply, age, ok := c.get(key); ok {
+				if age < c.opts.maxAge {
+					return reply, nil
+				}
+				if age < c.opts.maxAge+c.opts.stale {
+					c.refresh(ctx, key, req, handler)
+					return reply, nil
+				}
+			}
+			reply, err := handler(ctx, req)
+			if err == nil {
+				c.set(key, reply)
+			}
+			return reply, err
+		}
+	}
+}
+
+// refresh calls the handler in the background once per key, the call is detached from
+// the cancellation of the request but bounded by its remaining deadline.
+func (c *cache) refresh(ctx context.Context, key string, req interface{}, handler middleware.Handler) {
+	var timeout time.Duration
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+	}
+	go c.g.Do(key, func() (interface{}, error) {
+		if c.fresh(key) {
+			// refreshed by the previous call since the stale hit
+			return nil, nil
+		}
+		// the response of the request is written already
+		ctx := transport.NewHeaderContext(detached{ctx}, nil)
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		reply, err := handler(ctx, req)
+		if err == nil {
+			c.set(key, reply)
+		}
+		return nil, nil
+	})
+}
+
+// get returns a copy of the cached reply and its age.
+func (c *cache) get(key string) (interface{}, time.Duration, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, 0, false
+	}
+	ent := e.Value.(*entry)
+	age := c.opts.clock.Now().Sub(ent.created)
+	if age >= c.opts.maxAge+c.opts.stale {
+		c.lru.Remove(e)
+		delete(c.entries, key)
+		return nil, 0, false
+	}
+	c.lru.MoveToFront(e)
+	return clone(ent.reply), age, true
+}
+
+func (c *cache) fresh(key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	return ok && c.opts.clock.Now().Sub(e.Value.(*entry).created) < c.opts.maxAge
+}
+
+func (c *cache) set(key string, reply interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ent := &entry{key: key, reply: clone(reply), created: c.opts.clock.Now()}
+	if e, ok := c.entries[key]; ok {
+		e.Value = ent
+		c.lru.MoveToFront(e)
+		return
+	}
+	c.entries[key] = c.lru.PushFront(ent)
+	for c.opts.maxEntries > 0 && c.lru.Len() > c.opts.maxEntries {
+		e := c.lru.Back()
+		c.lru.Remove(e)
+		delete(c.entries, e.Value.(*entry).key)
+	}
+}
+
+// clone copies the proto replies, so the cached ones are not shared with the callers.
+func clone(reply interface{}) interface{} {
+	if m, ok := reply.(proto.Message); ok {
+		return proto.Clone(m)
+	}
+	return reply
+}
+
+// detached is a context with the values of the parent but not its cancellation.
+type detached struct {
+	parent context.Context
+}
+
+func (detached) Deadline() (time.Time, bool) { return time.Time{}, false }
+
+func (detached) Done() <-chan struct{} { return nil }
+
+func (detached) Err() error { return nil }
+
+func (d detached) Value(key interface{}) interface{} { return d.parent.Value(key) }
@@ -0,0 +1,68 @@
+package cache
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/clock"
+)
+
+func TestServer(t *testing.T) {
+	c := clock.NewFake()
+	var (
+		calls   int32
+		release = make(chan struct{})
+	)
+	next := Server(
+		func(ctx context.Context, req interface{}) (string, bool) { return "key", true },
+		WithMaxAge(time.Minute),
+		WithStaleWhileRevalidate(time.Minute),
+		WithClock(c),
+	)(func(ctx context.Context, req interface{}) (interface{}, error) {
+		n := atomic.AddInt32(&calls, 1)
+		if n == 2 {
+			// the background refresh
+			<-release
+		}
+		return n, nil
+	})
+
+	if reply, _ := next(context.Background(), nil); reply != int32(1) {
+		t.Fatalf("expected the miss got %v", reply)
+	}
+	// fresh hit
+	c.Advance(30 * time.Second)
+	if reply, _ := next(context.Background(), nil); reply != int32(1) || atomic.LoadInt32(&calls) != 1 {
+		t.Fatalf("expected the fresh hit got %v", reply)
+	}
+
+	// the stale reply is served while a single refresh runs
+	c.Advance(time.Minute)
+	for i := 0; i < 3; i++ {
+		if reply, _ := next(context.Background(), nil); reply != int32(1) {
+			t.Fatalf("expected the stale reply got %v", reply)
+		}
+	}
+	close(release)
+	// the stale hits join the in-flight refresh until it is stored
+	for i := 0; ; i++ {
+		if reply, _ := next(context.Background(), nil); reply == int32(2) {
+			break
+		}
+		if i > 100 {
+			t.Fatal("expected the refreshed reply")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Fatalf("expected a single refresh got %d calls", n)
+	}
+
+	// expired miss
+	c.Advance(2 * time.Minute)
+	if reply, _ := next(context.Background(), nil); reply != int32(3) {
+		t.Fatalf("expected the expired miss got %v", reply)
+	}
+}
@@ -0,0 +1,125 @@
+package circuitbreaker
+
+import (
+	"sync"
+	"time"
+)
+
+// State is the state of a circuit breaker.
+type State int
+
+// Defines the states of the circuit breaker.
+const (
+	// Closed allows the requests and counts the failures.
+	Closed State = iota
+	// Open rejects the requests until the open timeout elapses.
+	Open
+	// HalfOpen allows the probe requests to decide whether to close or open again.
+	HalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// MarshalText marshals the state as its name, e.g. in the JSON of States.
+func (s State) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// breaker is the circuit breaker of a key, which trips once the error ratio over the
+// window reaches the threshold with the minimum requests, and probes after the timeout.
+type breaker struct {
+	opts *options
+
+	mu          sync.Mutex
+	state       State
+	since       time.Time
+	windowStart time.Time
+	requests    uint64
+	failures    uint64
+	// the in-flight and the successful probes of the half-open state
+	probes    int
+	successes int
+}
+
+func newBreaker(opts *options) *breaker {
+	now := opts.clock.Now()
+	return &breaker{opts: opts, since: now, windowStart: now}
+}
+
+// allow reports whether the request is allowed, the half-open breaker allows
+// the probes only until they are in flight or succeeded as many as the options.
+func (b *breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.opts.clock.Now()
+	switch b.state {
+	case Open:
+		if now.Sub(b.since) < b.opts.openTimeout {
+			return false
+		}
+		b.transit(HalfOpen, now)
+		fallthrough
+	case HalfOpen:
+		if b.probes+b.successes >= b.opts.probes {
+			return false
+		}
+		b.probes++
+		return true
+	}
+	if now.Sub(b.windowStart) >= b.opts.window {
+		b.windowStart, b.requests, b.failures = now, 0, 0
+	}
+	return true
+}
+
+// done records the result of the allowed request.
+func (b *breaker) done(failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.opts.clock.Now()
+	switch b.state {
+	case HalfOpen:
+		if b.probes > 0 {
+			b.probes--
+		}
+		if failed {
+			b.transit(Open, now)
+		} else if b.successes++; b.successes >= b.opts.probes {
+			b.transit(Closed, now)
+		}
+		return
+	case Open:
+		// the requests allowed before the breaker opened
+		return
+	}
+	b.requests++
+	if failed {
+		b.failures++
+	}
+	if b.requests >= b.opts.minRequests && float64(b.failures)/float64(b.requests) >= b.opts.threshold {
+		b.transit(Open, now)
+	}
+}
+
+func (b *breaker) transit(state State, now time.Time) {
+	b.state, b.since, b.probes, b.successes = state, now, 0, 0
+	if state == Closed {
+		b.windowStart, b.requests, b.failures = now, 0, 0
+	}
+}
+
+func (b *breaker) status() (state State, since time.Time, requests, failures uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state, b.since, b.requests, b.failures
+}
@@ -0,0 +1,231 @@
+package circuitbreaker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/clock"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport/grpc"
+	transhttp "github.com/go-kratos/kratos/v2/transport/http"
+)
+
+// Option is circuit breaker option.
+type Option func(*options)
+
+type options struct {
+	name        string
+	window      time.Duration
+	threshold   float64
+	minRequests uint64
+	openTimeout time.Duration
+	probes      int
+	failure     func(error) bool
+	clock       clock.Clock
+}
+
+// WithName with the name of the breakers listed by States, e.g. the target service.
+func WithName(name string) Option {
+	return func(o *options) {
+		o.name = name
+	}
+}
+
+// WithWindow with the window of the error ratio, the default is 10s.
+func WithWindow(d time.Duration) Option {
+	return func(o *options) {
+		o.window = d
+	}
+}
+
+// WithThreshold with the error ratio in (0, 1] which trips the breaker once there are
+// at least the minRequests in the window, the default is 0.5 with 20 requests.
+func WithThreshold(ratio float64, minRequests uint64) Option {
+	return func(o *options) {
+		o.threshold = ratio
+		o.minRequests = minRequests
+	}
+}
+
+// WithOpenTimeout with the duration the breaker stays open before probing, the default is 5s.
+func WithOpenTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.openTimeout = d
+	}
+}
+
+// WithProbes with the probe requests allowed in the half-open state, which close the breaker
+// once they all succeed, the default is 1.
+func WithProbes(n int) Option {
+	return func(o *options) {
+		o.probes = n
+	}
+}
+
+// WithFailure with the func which reports whether the error is counted as a failure,
+// the default counts the server errors, i.e. the code is 5xx.
+func WithFailure(f func(error) bool) Option {
+	return func(o *options) {
+		o.failure = f
+	}
+}
+
+// WithClock with the clock of the windows and the timeouts, the default is the real clock.
+func WithClock(c clock.Clock) Option {
+	return func(o *options) {
+		o.clock = c
+	}
+}
+
+func defaultFailure(err error) bool {
+	return errors.Code(err) >= 500
+}
+
+// Client is a client middleware which rejects the requests with ServiceUnavailable
+// while the breaker of the operation is open, the operation is the full method for gRPC
+// and the path template for HTTP. The breakers are listed by States.
+func Client(opts ...Option) middleware.Middleware {
+	options := &options{
+		window:      10 * time.Second,
+		threshold:   0.5,
+		minRequests: 20,
+		openTimeout: 5 * time.Second,
+		probes:      1,
+		failure:     defaultFailure,
+		clock:       clock.New(),
+	}
+	for _, o := range opts {
+		o(options)
+	}
+	g := &group{opts: options, breakers: make(map[string]*breaker)}
+	registry.add(g)
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			key := operation(ctx)
+			b := g.breaker(key)
+			if !b.allow() {
+				return nil, errors.ServiceUnavailable("CIRCUIT_OPEN", "circuit breaker is open for "+key)
+			}
+			reply, err := handler(ctx, req)
+			b.done(err != nil && options.failure(err))
+			return reply, err
+		}
+	}
+}
+
+func operation(ctx context.Context) string {
+	if info, ok := grpc.FromClientContext(ctx); ok {
+		return info.FullMethod
+	}
+	if info, ok := transhttp.FromClientContext(ctx); ok {
+		if info.PathPattern != "" {
+			return info.PathPattern
+		}
+		if info.Request != nil {
+			return info.Request.URL.Path
+		}
+	}
+	return ""
+}
+
+// group is the breakers of a Client middleware by the operation.
+type group struct {
+	opts *options
+
+	mu       sync.RWMutex
+	breakers map[string]*breaker
+}
+
+func (g *group) breaker(key string) *breaker {
+	g.mu.RLock()
+	b, ok := g.breakers[key]
+	g.mu.RUnlock()
+	if ok {
+		return b
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if b, ok = g.breakers[key]; !ok {
+		b = newBreaker(g.opts)
+		g.breakers[key] = b
+	}
+	return b
+}
+
+type groups struct {
+	mu     sync.Mutex
+	groups []*group
+}
+
+func (r *groups) add(g *group) {
+	r.mu.Lock()
+	r.groups = append(r.groups, g)
+	r.mu.Unlock()
+}
+
+var registry = &groups{}
+
+// Status is the introspection of a breaker.
+type Status struct {
+	Name  string `json:"name"`
+	Key   string `json:"key"`
+	State State  `json:"state"`
+	// ErrorRatio is the ratio of the failures in the current window.
+	ErrorRatio float64 `json:"error_ratio"`
+	Requests   uint64  `json:"requests"`
+	Failures   uint64  `json:"failures"`
+	// Since is the time of the last state change.
+	Since time.Time `json:"since"`
+}
+
+// States returns the status of all the breakers sorted by the name and the key,
+// so the tripped breakers can be seen during the incidents.
+func States() []Status {
+	registry.mu.Lock()
+	groups := append([]*group(nil), registry.groups...)
+	registry.mu.Unlock()
+
+	var states []Status
+	for _, g := range groups {
+		g.mu.RLock()
+		for key, b := range g.breakers {
+			state, since, requests, failures := b.status()
+			s := Status{
+				Name:     g.opts.name,
+				Key:      key,
+				State:    state,
+				Requests: requests,
+				Failures: failures,
+				Since:    since,
+			}
+			if requests > 0 {
+				s.ErrorRatio = float64(failures) / float64(requests)
+			}
+			states = append(states, s)
+		}
+		g.mu.RUnlock()
+	}
+	sort.Slice(states, func(i, j int) bool {
+		if states[i].Name != states[j].Name {
+			return states[i].Name < states[j].Name
+		}
+		return states[i].Key < states[j].Key
+	})
+	return states
+}
+
+// Handler returns the handler which serves the States as JSON, e.g. mounted on the
+// HTTP server of the admin endpoints:
+//
+//	srv.Handle("/debug/breakers", circuitbreaker.Handler())
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(States())
+	})
+}
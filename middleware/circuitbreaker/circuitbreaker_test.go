@@ -0,0 +1,129 @@
+package circuitbreaker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/clock"
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
+func TestClient(t *testing.T) {
+	c := clock.NewFake()
+	var failing = true
+	next := Client(
+		WithName("TestClient"),
+		WithThreshold(0.5, 4),
+		WithOpenTimeout(time.Second),
+		WithClock(c),
+	)(func(ctx context.Context, req interface{}) (interface{}, error) {
+		if failing {
+			return nil, errors.ServiceUnavailable("UNAVAILABLE", "unavailable")
+		}
+		return "ok", nil
+	})
+	status := func() Status {
+		for _, s := range States() {
+			if s.Name == "TestClient" {
+				return s
+			}
+		}
+		t.Fatal("expected the breaker listed")
+		return Status{}
+	}
+
+	for i := 0; i < 4; i++ {
+		_, _ = next(context.Background(), nil)
+	}
+	if s := status(); s.State != Open || s.ErrorRatio != 1 || !s.Since.Equal(c.Now()) {
+		t.Fatalf("expected open got %+v", s)
+	}
+	if _, err := next(context.Background(), nil); errors.Reason(err) != "CIRCUIT_OPEN" {
+		t.Fatalf("expected rejected got %v", err)
+	}
+
+	// a failed probe opens the breaker again
+	c.Advance(time.Second)
+	if _, err := next(context.Background(), nil); errors.Reason(err) != "UNAVAILABLE" {
+		t.Fatalf("expected the probe got %v", err)
+	}
+	if s := status(); s.State != Open {
+		t.Fatalf("expected open got %+v", s)
+	}
+
+	// a successful probe closes the breaker
+	failing = false
+	c.Advance(time.Second)
+	if _, err := next(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	if s := status(); s.State != Closed || s.Requests != 0 {
+		t.Fatalf("expected closed got %+v", s)
+	}
+
+	w := httptest.NewRecorder()
+	Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/breakers", nil))
+	var states []map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &states); err != nil || len(states) == 0 {
+		t.Fatalf("unexpected states %s %v", w.Body.String(), err)
+	}
+	if states[0]["state"] != "closed" {
+		t.Fatalf("expected the state name got %v", states[0]["state"])
+	}
+}
+
+func TestProbes(t *testing.T) {
+	c := clock.NewFake()
+	opts := &options{window: 10 * time.Second, threshold: 0.5, minRequests: 1, openTimeout: time.Second, clock: c}
+	WithProbes(2)(opts)
+	b := newBreaker(opts)
+	trip := func() {
+		if !b.allow() {
+			t.Fatal("expected the request allowed")
+		}
+		b.done(true)
+		c.Advance(time.Second)
+	}
+
+	// the sequential probes close the breaker after both succeed
+	trip()
+	for i := 0; i < 2; i++ {
+		if b.state != Open && b.state != HalfOpen {
+			t.Fatalf("expected not closed before the probe %d got %s", i, b.state)
+		}
+		if !b.allow() {
+			t.Fatalf("expected the probe %d allowed", i)
+		}
+		b.done(false)
+		if i == 0 && b.state != HalfOpen {
+			t.Fatalf("expected half-open after one success got %s", b.state)
+		}
+	}
+	if b.state != Closed {
+		t.Fatalf("expected closed got %s", b.state)
+	}
+
+	// the concurrent probes are limited and close the breaker after both succeed
+	trip()
+	if !b.allow() || !b.allow() {
+		t.Fatal("expected two probes allowed")
+	}
+	if b.allow() {
+		t.Fatal("expected the third probe rejected")
+	}
+	b.done(false)
+	if b.state != HalfOpen {
+		t.Fatalf("expected half-open after one success got %s", b.state)
+	}
+	if b.allow() {
+		t.Fatal("expected no more probes after one success and one in flight")
+	}
+	b.done(false)
+	if b.state != Closed {
+		t.Fatalf("expected closed got %s", b.state)
+	}
+}
@@ -0,0 +1,81 @@
+package contenttype
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	transhttp "github.com/go-kratos/kratos/v2/transport/http"
+)
+
+// Option is content type option.
+type Option func(*options)
+
+type options struct {
+	overrides map[string][]string
+}
+
+// WithOverrides with the allowed content types by the path prefix, e.g. /v1/upload,
+// the longest prefix wins, and an empty list allows any content type.
+func WithOverrides(overrides map[string][]string) Option {
+	return func(o *options) {
+		o.overrides = overrides
+	}
+}
+
+// Server is a server filter of the HTTP transport which rejects the requests with a body
+// of the content types not allowed with 415 before decoding, e.g.
+// http.Filter(contenttype.Server([]string{"application/json", "application/x-protobuf"})).
+// The content types are compared without the parameters such as charset, and the
+// wildcard subtype is supported, e.g. application/*. An empty list allows any content type.
+func Server(allowed []string, opts ...Option) transhttp.FilterFunc {
+	options := options{}
+	for _, o := range opts {
+		o(&options)
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			types := options.allowed(r.URL.Path, allowed)
+			if len(types) == 0 || r.Body == nil || r.Body == http.NoBody || r.ContentLength == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if err := check(r.Header.Get("Content-Type"), types); err != nil {
+				transhttp.DefaultErrorEncoder(w, r, err)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func (o *options) allowed(path string, allowed []string) []string {
+	var prefix string
+	for p, types := range o.overrides {
+		if strings.HasPrefix(path, p) && len(p) > len(prefix) {
+			prefix, allowed = p, types
+		}
+	}
+	return allowed
+}
+
+func check(contentType string, allowed []string) error {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return unsupported(contentType, allowed)
+	}
+	for _, t := range allowed {
+		t = strings.ToLower(t)
+		if t == mediaType || (strings.HasSuffix(t, "/*") && strings.HasPrefix(mediaType, t[:len(t)-1])) {
+			return nil
+		}
+	}
+	return unsupported(contentType, allowed)
+}
+
+func unsupported(contentType string, allowed []string) error {
+	return errors.New(http.StatusUnsupportedMediaType, "UNSUPPORTED_MEDIA_TYPE",
+		fmt.Sprintf("the content type %q is not allowed, expected one of %s", contentType, strings.Join(allowed, ", ")))
+}
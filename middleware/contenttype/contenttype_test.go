@@ -0,0 +1,42 @@
+package contenttype
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestServer(t *testing.T) {
+	h := Server([]string{"application/json"}, WithOverrides(map[string][]string{
+		"/upload": {"multipart/form-data", "image/*"},
+		"/raw":    nil,
+	}))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	tests := []struct {
+		method      string
+		path        string
+		contentType string
+		body        string
+		code        int
+	}{
+		{http.MethodGet, "/users", "", "", http.StatusOK},
+		{http.MethodPost, "/users", "application/json; charset=utf-8", "{}", http.StatusOK},
+		{http.MethodPost, "/users", "Application/JSON", "{}", http.StatusOK},
+		{http.MethodPost, "/users", "text/plain", "{}", http.StatusUnsupportedMediaType},
+		{http.MethodPost, "/users", "", "{}", http.StatusUnsupportedMediaType},
+		{http.MethodPost, "/upload", "image/png", "png", http.StatusOK},
+		{http.MethodPost, "/upload", "application/json", "{}", http.StatusUnsupportedMediaType},
+		{http.MethodPost, "/raw", "text/plain", "raw", http.StatusOK},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(test.method, test.path, strings.NewReader(test.body))
+		if test.contentType != "" {
+			req.Header.Set("Content-Type", test.contentType)
+		}
+		res := httptest.NewRecorder()
+		h.ServeHTTP(res, req)
+		if res.Code != test.code {
+			t.Errorf("%s %s %q: expected %d got %d", test.method, test.path, test.contentType, test.code, res.Code)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package cors
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	transhttp "github.com/go-kratos/kratos/v2/transport/http"
+)
+
+// Option is CORS option.
+type Option func(*options)
+
+type options struct {
+	allowOrigin func(r *http.Request, origin string) bool
+	anyOrigin   bool
+	methods     []string
+	headers     []string
+	exposed     []string
+	credentials bool
+	maxAge      time.Duration
+}
+
+// WithOrigins with the allowed origins, the default is none. The * allows any origin
+// without the credentials, as the browsers forbid the credentials of any origin.
+func WithOrigins(origins ...string) Option {
+	set := make(map[string]struct{}, len(origins))
+	for _, o := range origins {
+		set[o] = struct{}{}
+	}
+	_, wildcard := set["*"]
+	return func(o *options) {
+		o.anyOrigin = wildcard
+		o.allowOrigin = func(r *http.Request, origin string) bool {
+			_, ok := set[origin]
+			return wildcard || ok
+		}
+	}
+}
+
+// WithOriginFunc with the func which allows the origin by the request,
+// e.g. an allowlist of origins per path prefix.
+func WithOriginFunc(fn func(r *http.Request, origin string) bool) Option {
+	return func(o *options) {
+		o.anyOrigin = false
+		o.allowOrigin = fn
+	}
+}
+
+// WithMethods with the allowed methods, the default is GET, POST, PUT, PATCH, DELETE and HEAD.
+func WithMethods(methods ...string) Option {
+	return func(o *options) {
+		o.methods = methods
+	}
+}
+
+// WithHeaders with the allowed request headers, the default allows the requested headers.
+func WithHeaders(headers ...string) Option {
+	return func(o *options) {
+		o.headers = headers
+	}
+}
+
+// WithExposedHeaders with the response headers exposed to the browsers.
+func WithExposedHeaders(headers ...string) Option {
+	return func(o *options) {
+		o.exposed = headers
+	}
+}
+
+// WithCredentials with whether the requests with credentials such as cookies are allowed,
+// which is ignored if any origin is allowed by *.
+func WithCredentials(allow bool) Option {
+	return func(o *options) {
+		o.credentials = allow
+	}
+}
+
+// WithMaxAge with the max age of the preflight result cached by the browsers.
+func WithMaxAge(d time.Duration) Option {
+	return func(o *options) {
+		o.maxAge = d
+	}
+}
+
+// Server is a server filter of the HTTP transport which handles the CORS, it responds
+// the preflight requests before the route matching, and adds the CORS headers to the
+// responses of the actual requests, e.g. http.Filter(cors.Server(cors.WithOrigins(...))).
+func Server(opts ...Option) transhttp.FilterFunc {
+	options := options{
+		allowOrigin: func(*http.Request, string) bool { return false },
+		methods:     []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodHead},
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+	methods := strings.Join(options.methods, ", ")
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			if origin == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+			h := w.Header()
+			h.Add("Vary", "Origin")
+			if !options.allowOrigin(r, origin) {
+				if preflight {
+					w.WriteHeader(http.StatusForbidden)
+					return
+				}
+				// the browser blocks the response without the CORS headers
+				next.ServeHTTP(w, r)
+				return
+			}
+			if options.anyOrigin {
+				h.Set("Access-Control-Allow-Origin", "*")
+			} else {
+				h.Set("Access-Control-Allow-Origin", origin)
+				if options.credentials {
+					h.Set("Access-Control-Allow-Credentials", "true")
+				}
+			}
+			if !preflight {
+				if len(options.exposed) > 0 {
+					h.Set("Access-Control-Expose-Headers", strings.Join(options.exposed, ", "))
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+			h.Add("Vary", "Access-Control-Request-Method")
+			h.Add("Vary", "Access-Control-Request-Headers")
+			h.Set("Access-Control-Allow-Methods", methods)
+			if len(options.headers) > 0 {
+				h.Set("Access-Control-Allow-Headers", strings.Join(options.headers, ", "))
+			} else if headers := r.Header.Get("Access-Control-Request-Headers"); headers != "" {
+				h.Set("Access-Control-Allow-Headers", headers)
+			}
+			if options.maxAge > 0 {
+				h.Set("Access-Control-Max-Age", strconv.Itoa(int(options.maxAge/time.Second)))
+			}
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package cors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestServer(t *testing.T) {
+	var called bool
+	h := Server(WithOrigins("https://example.com"), WithCredentials(true))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+
+	req := httptest.NewRequest(http.MethodOptions, "/users", nil)
+	req.Header.Set("Origin", "https://example.com")
+	req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	req.Header.Set("Access-Control-Request-Headers", "Content-Type")
+	res := httptest.NewRecorder()
+	h.ServeHTTP(res, req)
+	if called || res.Code != http.StatusNoContent {
+		t.Fatalf("expected the preflight short-circuited got %d", res.Code)
+	}
+	if v := res.Header().Get("Access-Control-Allow-Headers"); v != "Content-Type" {
+		t.Fatalf("expected the requested headers allowed got %s", v)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/users", nil)
+	req.Header.Set("Origin", "https://example.com")
+	res = httptest.NewRecorder()
+	h.ServeHTTP(res, req)
+	if !called || res.Header().Get("Access-Control-Allow-Origin") != "https://example.com" {
+		t.Fatal("expected the CORS headers of the actual request")
+	}
+
+	req = httptest.NewRequest(http.MethodOptions, "/users", nil)
+	req.Header.Set("Origin", "https://evil.com")
+	req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	res = httptest.NewRecorder()
+	h.ServeHTTP(res, req)
+	if res.Code != http.StatusForbidden {
+		t.Fatalf("expected the origin forbidden got %d", res.Code)
+	}
+}
+
+func TestServerAnyOrigin(t *testing.T) {
+	h := Server(WithOrigins("*"), WithCredentials(true))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	req := httptest.NewRequest(http.MethodGet, "/users", nil)
+	req.Header.Set("Origin", "https://evil.com")
+	res := httptest.NewRecorder()
+	h.ServeHTTP(res, req)
+	if v := res.Header().Get("Access-Control-Allow-Origin"); v != "*" {
+		t.Fatalf("expected any origin got %s", v)
+	}
+	if v := res.Header().Get("Access-Control-Allow-Credentials"); v != "" {
+		t.Fatalf("expected no credentials of any origin got %s", v)
+	}
+}
@@ -0,0 +1,94 @@
+package deprecation
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/metrics"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// SunsetInfo is the deprecation information of an operation.
+type SunsetInfo struct {
+	// Deprecated is the time the operation was deprecated,
+	// the zero value means the operation is deprecated already.
+	Deprecated time.Time
+	// Sunset is the time the operation will be removed, optional.
+	Sunset time.Time
+	// Link is the link to the deprecation documentation, optional.
+	Link string
+}
+
+// Option is deprecation option.
+type Option func(*options)
+
+type options struct {
+	logger   log.Logger
+	requests metrics.Counter
+}
+
+// WithLogger with deprecation logger, which logs each call to a deprecated operation.
+func WithLogger(logger log.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// WithRequests with requests counter, which counts the calls by operation.
+func WithRequests(c metrics.Counter) Option {
+	return func(o *options) {
+		o.requests = c
+	}
+}
+
+// Server is a server middleware which adds the Deprecation and Sunset
+// headers to responses of deprecated operations.
+// The operation is the full method for gRPC and the path template for HTTP.
+func Server(ops map[string]SunsetInfo, opts ...Option) middleware.Middleware {
+	options := options{}
+	for _, o := range opts {
+		o(&options)
+	}
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			operation := transport.Operation(ctx)
+			si, ok := ops[operation]
+			if !ok {
+				return handler(ctx, req)
+			}
+			for k, v := range si.headers() {
+				_ = transport.SetHeader(ctx, k, v)
+			}
+			if options.logger != nil {
+				log.WithContext(ctx, options.logger).Log(log.LevelWarn,
+					"kind", "server",
+					"component", "deprecation",
+					"operation", operation,
+					"sunset", si.Sunset,
+				)
+			}
+			if options.requests != nil {
+				options.requests.With(operation).Inc()
+			}
+			return handler(ctx, req)
+		}
+	}
+}
+
+func (si SunsetInfo) headers() map[string]string {
+	headers := map[string]string{"Deprecation": "true"}
+	if !si.Deprecated.IsZero() {
+		headers["Deprecation"] = si.Deprecated.UTC().Format(http.TimeFormat)
+	}
+	if !si.Sunset.IsZero() {
+		headers["Sunset"] = si.Sunset.UTC().Format(http.TimeFormat)
+	}
+	if si.Link != "" {
+		headers["Link"] = fmt.Sprintf("<%s>; rel=\"deprecation\"", si.Link)
+	}
+	return headers
+}
@@ -0,0 +1,79 @@
+package deprecation
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/transport"
+	transgrpc "github.com/go-kratos/kratos/v2/transport/grpc"
+	transhttp "github.com/go-kratos/kratos/v2/transport/http"
+)
+
+type header map[string]string
+
+func (h header) SetHeader(key, value string) error {
+	h[key] = value
+	return nil
+}
+
+func (h header) SetTrailer(key, value string) error {
+	return nil
+}
+
+func TestServer(t *testing.T) {
+	sunset := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	m := Server(map[string]SunsetInfo{
+		"/helloworld.Greeter/SayHello": {Sunset: sunset, Link: "https://example.com/migrate"},
+		"/v1/users":                    {},
+	})
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		headers map[string]string
+	}{
+		{
+			"grpc deprecated",
+			transgrpc.NewServerContext(context.Background(), transgrpc.ServerInfo{FullMethod: "/helloworld.Greeter/SayHello"}),
+			map[string]string{
+				"Deprecation": "true",
+				"Sunset":      sunset.Format(http.TimeFormat),
+				"Link":        `<https://example.com/migrate>; rel="deprecation"`,
+			},
+		},
+		{
+			"grpc current",
+			transgrpc.NewServerContext(context.Background(), transgrpc.ServerInfo{FullMethod: "/helloworld.Greeter/SayHi"}),
+			map[string]string{},
+		},
+		{
+			"http deprecated",
+			transhttp.NewServerContext(context.Background(), transhttp.ServerInfo{Request: httptest.NewRequest(http.MethodGet, "/v1/users", nil)}),
+			map[string]string{"Deprecation": "true"},
+		},
+		{
+			"http current",
+			transhttp.NewServerContext(context.Background(), transhttp.ServerInfo{Request: httptest.NewRequest(http.MethodGet, "/v2/users", nil)}),
+			map[string]string{},
+		},
+	}
+	for _, test := range tests {
+		h := header{}
+		ctx := transport.NewHeaderContext(test.ctx, h)
+		if _, err := m(func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, nil
+		})(ctx, nil); err != nil {
+			t.Fatal(err)
+		}
+		if len(h) != len(test.headers) {
+			t.Errorf("%s: expected %v got %v", test.name, test.headers, h)
+		}
+		for k, v := range test.headers {
+			if h[k] != v {
+				t.Errorf("%s: expected %s %q got %q", test.name, k, v, h[k])
+			}
+		}
+	}
+}
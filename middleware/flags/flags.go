@@ -0,0 +1,66 @@
+package flags
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/go-kratos/kratos/v2/flags"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// Option is flags option.
+type Option func(*options)
+
+type options struct {
+	overrides string
+}
+
+// WithOverrides with the metadata key of the per-request overrides, e.g. x-md-flags,
+// the value is a comma separated list of the flags, e.g. new-checkout=true,dark-mode=false,
+// and a flag without the value is enabled. It should be enabled for the internal services only.
+func WithOverrides(key string) Option {
+	return func(o *options) {
+		o.overrides = key
+	}
+}
+
+// Server is a server middleware which attaches the feature flag provider
+// to the request context, so that handlers can use flags.Enabled.
+func Server(p flags.Provider, opts ...Option) middleware.Middleware {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			ctx = flags.NewContext(ctx, p)
+			if o.overrides != "" {
+				if overrides := parseOverrides(transport.RequestHeader(ctx, o.overrides)); len(overrides) > 0 {
+					ctx = flags.NewOverridesContext(ctx, overrides)
+				}
+			}
+			return handler(ctx, req)
+		}
+	}
+}
+
+func parseOverrides(s string) map[string]bool {
+	overrides := make(map[string]bool)
+	for _, kv := range strings.Split(s, ",") {
+		kv = strings.TrimSpace(kv)
+		if kv == "" {
+			continue
+		}
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) == 1 {
+			overrides[parts[0]] = true
+			continue
+		}
+		if enabled, err := strconv.ParseBool(parts[1]); err == nil {
+			overrides[strings.TrimSpace(parts[0])] = enabled
+		}
+	}
+	return overrides
+}
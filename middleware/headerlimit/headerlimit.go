@@ -0,0 +1,64 @@
+package headerlimit
+
+import (
+	"context"
+	"fmt"
+	nethttp "net/http"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/metrics"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// Option is header limit option.
+type Option func(*options)
+
+type options struct {
+	rejected metrics.Counter
+}
+
+// WithRejected with rejected requests counter, which counts by the operation,
+// the full method for gRPC and the path template for HTTP.
+func WithRejected(c metrics.Counter) Option {
+	return func(o *options) {
+		o.rejected = c
+	}
+}
+
+// Server is a server middleware which rejects the requests whose total size of the header
+// keys and values exceeds the limit in bytes, with 431 Request Header Fields Too Large,
+// which is ResourceExhausted for gRPC.
+func Server(limit int, opts ...Option) middleware.Middleware {
+	options := options{}
+	for _, o := range opts {
+		o(&options)
+	}
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			header, ok := transport.RequestHeaderCarrier(ctx)
+			if !ok {
+				return handler(ctx, req)
+			}
+			if size := headerSize(header); size > limit {
+				if options.rejected != nil {
+					options.rejected.With(transport.Operation(ctx)).Inc()
+				}
+				return nil, errors.New(nethttp.StatusRequestHeaderFieldsTooLarge, "HEADER_TOO_LARGE",
+					fmt.Sprintf("header size %d exceeds the limit %d", size, limit))
+			}
+			return handler(ctx, req)
+		}
+	}
+}
+
+// headerSize returns the total size of the keys and values, the key is
+// counted for each value as it is sent on the wire.
+func headerSize(header transport.HeaderCarrier) (size int) {
+	for _, k := range header.Keys() {
+		for _, v := range header.Values(k) {
+			size += len(k) + len(v)
+		}
+	}
+	return
+}
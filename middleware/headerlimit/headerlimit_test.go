@@ -0,0 +1,71 @@
+package headerlimit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/metrics"
+	"github.com/go-kratos/kratos/v2/transport/grpc"
+	transhttp "github.com/go-kratos/kratos/v2/transport/http"
+	"github.com/gorilla/mux"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestServer(t *testing.T) {
+	next := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	}
+	h := Server(64)(next)
+	tests := []struct {
+		md  metadata.MD
+		err bool
+	}{
+		{metadata.Pairs("key", "value"), false},
+		{metadata.Pairs("key", strings.Repeat("v", 64)), true},
+		{metadata.Pairs("key", strings.Repeat("v", 30), "KEY", strings.Repeat("v", 30)), true},
+	}
+	for _, test := range tests {
+		ctx := metadata.NewIncomingContext(context.Background(), test.md)
+		ctx = grpc.NewServerContext(ctx, grpc.ServerInfo{FullMethod: "/test.Test/Test"})
+		_, err := h(ctx, nil)
+		if want, have := test.err, errors.Code(err) == http.StatusRequestHeaderFieldsTooLarge; want != have {
+			t.Errorf("want %v have %v", want, have)
+		}
+	}
+}
+
+type testCounter struct {
+	lvs []string
+}
+
+func (c *testCounter) With(lvs ...string) metrics.Counter {
+	c.lvs = lvs
+	return c
+}
+
+func (c *testCounter) Inc()          {}
+func (c *testCounter) Add(d float64) {}
+
+func TestServerRejectedOperation(t *testing.T) {
+	c := &testCounter{}
+	h := Server(64, WithRejected(c))(func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	})
+	r := mux.NewRouter()
+	r.HandleFunc("/v1/users/{id}", func(w http.ResponseWriter, req *http.Request) {
+		ctx := transhttp.NewServerContext(req.Context(), transhttp.ServerInfo{Request: req, Response: w})
+		if _, err := h(ctx, nil); err == nil {
+			t.Error("expected the header rejected")
+		}
+	})
+	req := httptest.NewRequest(http.MethodGet, "/v1/users/1", nil)
+	req.Header.Set("Cookie", strings.Repeat("v", 64))
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	if len(c.lvs) != 1 || c.lvs[0] != "/v1/users/{id}" {
+		t.Fatalf("expected the path template label got %v", c.lvs)
+	}
+}
@@ -0,0 +1,48 @@
+package i18n
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
+// Catalog is the localized messages of the error reasons by locale, e.g.
+// {"zh-CN": {"USER_NOT_FOUND": "用户 {name} 不存在"}}, the {key} in the
+// message is replaced by the value of the error metadata.
+type Catalog map[string]map[string]string
+
+// Message returns the localized message of the reason, the messages of the
+// language are used if the locale is not found, e.g. zh for zh-CN.
+func (c Catalog) Message(locale, reason string) (string, bool) {
+	if msg, ok := c[locale][reason]; ok {
+		return msg, true
+	}
+	msg, ok := c[language(locale)][reason]
+	return msg, ok
+}
+
+// Localize returns the error with the message localized by the locale of ctx,
+// the error is returned as it is if there is no localized message.
+func (c Catalog) Localize(ctx context.Context, err error) error {
+	locale, ok := FromContext(ctx)
+	if !ok {
+		return err
+	}
+	se := errors.FromError(err)
+	if se == nil || se.Reason == "" {
+		return err
+	}
+	msg, ok := c.Message(locale, se.Reason)
+	if !ok {
+		return err
+	}
+	if len(se.Metadata) > 0 {
+		kv := make([]string, 0, len(se.Metadata)*2)
+		for k, v := range se.Metadata {
+			kv = append(kv, "{"+k+"}", v)
+		}
+		msg = strings.NewReplacer(kv...).Replace(msg)
+	}
+	return errors.New(int(se.Code), se.Reason, msg).WithMetadata(se.Metadata)
+}
@@ -0,0 +1,22 @@
+package i18n
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
+func TestCatalog(t *testing.T) {
+	c := Catalog{"zh": {"USER_NOT_FOUND": "用户 {name} 不存在"}}
+	err := errors.NotFound("USER_NOT_FOUND", "user not found").WithMetadata(map[string]string{"name": "kratos"})
+
+	ctx := NewContext(context.Background(), "zh-CN")
+	if got := errors.FromError(c.Localize(ctx, err)); got.Message != "用户 kratos 不存在" || got.Code != 404 {
+		t.Fatalf("expected localized message got %v", got)
+	}
+	ctx = NewContext(context.Background(), "en-US")
+	if got := errors.FromError(c.Localize(ctx, err)); got.Message != "user not found" {
+		t.Fatalf("expected original message got %v", got)
+	}
+}
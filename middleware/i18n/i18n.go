@@ -0,0 +1,199 @@
+package i18n
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+const (
+	defaultKey = "accept-language"
+	// localeMetadataKey is the metadata key of the locale propagated by kratos services,
+	// which takes precedence over the accept-language of gRPC.
+	localeMetadataKey = "x-md-locale"
+)
+
+type localeKey struct{}
+
+// NewContext returns a new Context that carries the locale.
+func NewContext(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeKey{}, locale)
+}
+
+// FromContext returns the locale stored in ctx, if any.
+func FromContext(ctx context.Context) (locale string, ok bool) {
+	locale, ok = ctx.Value(localeKey{}).(string)
+	return
+}
+
+// Option is i18n option.
+type Option func(*options)
+
+type options struct {
+	key       string
+	fallback  string
+	supported []string
+	catalog   Catalog
+}
+
+// WithSupported with the supported locales, e.g. en-US, zh-CN,
+// the first one is the fallback if WithDefault is not specified.
+func WithSupported(locales ...string) Option {
+	return func(o *options) {
+		o.supported = locales
+	}
+}
+
+// WithDefault with the default locale if there is no match.
+func WithDefault(locale string) Option {
+	return func(o *options) {
+		o.fallback = locale
+	}
+}
+
+// WithCatalog with the message catalog which localizes the messages of
+// the errors returned by the handler.
+func WithCatalog(c Catalog) Option {
+	return func(o *options) {
+		o.catalog = c
+	}
+}
+
+// WithMetadataKey with the header or metadata key of the languages, the default is accept-language.
+func WithMetadataKey(key string) Option {
+	return func(o *options) {
+		o.key = strings.ToLower(key)
+	}
+}
+
+// Server is a server middleware which parses the x-md-locale and the accept-language
+// header of HTTP or metadata of gRPC, and stores the best matched locale in the context.
+func Server(opts ...Option) middleware.Middleware {
+	options := options{key: defaultKey}
+	for _, o := range opts {
+		o(&options)
+	}
+	supported := make([]string, 0, len(options.supported))
+	for _, l := range options.supported {
+		supported = append(supported, Normalize(l))
+	}
+	options.supported = supported
+	if options.fallback == "" && len(options.supported) > 0 {
+		options.fallback = options.supported[0]
+	}
+	options.fallback = Normalize(options.fallback)
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			var header string
+			if h, ok := transport.RequestHeaderCarrier(ctx); ok {
+				if header = strings.Join(h.Values(localeMetadataKey), ","); header == "" {
+					header = strings.Join(h.Values(options.key), ",")
+				}
+			}
+			locale := Match(Parse(header), options.supported)
+			if locale == "" {
+				locale = options.fallback
+			}
+			ctx = NewContext(ctx, locale)
+			reply, err := handler(ctx, req)
+			if err != nil && options.catalog != nil {
+				err = options.catalog.Localize(ctx, err)
+			}
+			return reply, err
+		}
+	}
+}
+
+// Normalize normalizes the locale to the form of en-US.
+func Normalize(locale string) string {
+	parts := strings.Split(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"), "-")
+	parts[0] = strings.ToLower(parts[0])
+	for i := 1; i < len(parts); i++ {
+		switch len(parts[i]) {
+		case 2:
+			// region, e.g. US
+			parts[i] = strings.ToUpper(parts[i])
+		case 4:
+			// script, e.g. Hans
+			parts[i] = strings.Title(strings.ToLower(parts[i]))
+		default:
+			parts[i] = strings.ToLower(parts[i])
+		}
+	}
+	return strings.Join(parts, "-")
+}
+
+// Parse parses the Accept-Language header and returns the normalized
+// locales in descending order of quality, the wildcard is ignored.
+func Parse(header string) []string {
+	type tag struct {
+		locale string
+		q      float64
+	}
+	var tags []tag
+	for _, s := range strings.Split(header, ",") {
+		parts := strings.Split(s, ";")
+		locale := strings.TrimSpace(parts[0])
+		if locale == "" || locale == "*" {
+			continue
+		}
+		q := 1.0
+		for _, p := range parts[1:] {
+			p = strings.TrimSpace(p)
+			if !strings.HasPrefix(p, "q=") {
+				continue
+			}
+			if v, err := strconv.ParseFloat(p[2:], 64); err == nil {
+				q = v
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+		tags = append(tags, tag{locale: Normalize(locale), q: q})
+	}
+	sort.SliceStable(tags, func(i, j int) bool {
+		return tags[i].q > tags[j].q
+	})
+	locales := make([]string, 0, len(tags))
+	for _, t := range tags {
+		locales = append(locales, t.locale)
+	}
+	return locales
+}
+
+// Match returns the best supported locale of the preferred locales, an exact match
+// wins over a match of the language only, e.g. en-GB matches en or en-US.
+// It returns the first preferred locale if supported is empty, or "" if there is no match.
+func Match(preferred []string, supported []string) string {
+	if len(supported) == 0 {
+		if len(preferred) > 0 {
+			return preferred[0]
+		}
+		return ""
+	}
+	for _, p := range preferred {
+		base := language(p)
+		var partial string
+		for _, s := range supported {
+			if s == p {
+				return s
+			}
+			if partial == "" && language(s) == base {
+				partial = s
+			}
+		}
+		if partial != "" {
+			return partial
+		}
+	}
+	return ""
+}
+
+func language(locale string) string {
+	return strings.SplitN(locale, "-", 2)[0]
+}
@@ -0,0 +1,57 @@
+package i18n
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/transport/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestParse(t *testing.T) {
+	got := Parse("fr-CH, fr;q=0.9, en;q=0.8, de;q=0.7, *;q=0.5, zh_cn;q=0")
+	want := []string{"fr-CH", "fr", "en", "de"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v got %v", want, got)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	supported := []string{"en-US", "zh-CN", "zh-Hant-TW"}
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"zh-CN,zh;q=0.9", "zh-CN"},
+		{"en-GB,en;q=0.9", "en-US"},
+		{"ja,zh-hant-tw;q=0.8", "zh-Hant-TW"},
+		{"ja", ""},
+		{"", ""},
+	}
+	for _, test := range tests {
+		if got := Match(Parse(test.header), supported); got != test.want {
+			t.Errorf("%q: expected %q got %q", test.header, test.want, got)
+		}
+	}
+}
+
+func TestServerSupported(t *testing.T) {
+	supported := []string{"en_us", "zh_cn"}
+	var locale string
+	h := Server(WithSupported(supported...))(func(ctx context.Context, req interface{}) (interface{}, error) {
+		locale, _ = FromContext(ctx)
+		return nil, nil
+	})
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("accept-language", "zh-CN"))
+	ctx = grpc.NewServerContext(ctx, grpc.ServerInfo{FullMethod: "/test.Test/Test"})
+	if _, err := h(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+	if locale != "zh-CN" {
+		t.Fatalf("expected zh-CN got %q", locale)
+	}
+	if want := []string{"en_us", "zh_cn"}; !reflect.DeepEqual(supported, want) {
+		t.Fatalf("expected the supported locales unchanged %v got %v", want, supported)
+	}
+}
@@ -7,42 +7,40 @@ import (
 	"github.com/go-kratos/kratos/v2/transport/grpc"
 )
 
-func grpcServerLog(logger log.Logger, ctx context.Context, args string, err error) {
+func grpcServerLog(logger log.Logger, ctx context.Context, p payload, err error) {
 	info, ok := grpc.FromServerContext(ctx)
 	if !ok {
 		return
 	}
-	code, errMsg := extractError(err)
-	level := log.LevelInfo
-	if err != nil {
-		level = log.LevelError
-	}
-	log.WithContext(ctx, logger).Log(level,
+	level, code, errMsg := extractError(err)
+	kvs := []interface{}{
 		"kind", "server",
 		"component", "grpc",
 		"grpc.target", info.FullMethod,
-		"grpc.args", args,
+	}
+	kvs = append(kvs, p.keyvals("grpc")...)
+	kvs = append(kvs,
 		"grpc.code", code,
 		"grpc.error", errMsg,
 	)
+	log.WithContext(ctx, logger).Log(level, kvs...)
 }
 
-func grpcClientLog(logger log.Logger, ctx context.Context, args string, err error) {
+func grpcClientLog(logger log.Logger, ctx context.Context, p payload, err error) {
 	info, ok := grpc.FromClientContext(ctx)
 	if !ok {
 		return
 	}
-	code, errMsg := extractError(err)
-	level := log.LevelInfo
-	if err != nil {
-		level = log.LevelError
-	}
-	log.WithContext(ctx, logger).Log(level,
+	level, code, errMsg := extractError(err)
+	kvs := []interface{}{
 		"kind", "client",
 		"component", "grpc",
 		"grpc.target", info.FullMethod,
-		"grpc.args", args,
+	}
+	kvs = append(kvs, p.keyvals("grpc")...)
+	kvs = append(kvs,
 		"grpc.code", code,
 		"grpc.error", errMsg,
 	)
+	log.WithContext(ctx, logger).Log(level, kvs...)
 }
@@ -7,44 +7,42 @@ import (
 	"github.com/go-kratos/kratos/v2/transport/http"
 )
 
-func httpServerLog(logger log.Logger, ctx context.Context, args string, err error) {
+func httpServerLog(logger log.Logger, ctx context.Context, p payload, err error) {
 	info, ok := http.FromServerContext(ctx)
 	if !ok {
 		return
 	}
-	code, errMsg := extractError(err)
-	level := log.LevelInfo
-	if err != nil {
-		level = log.LevelError
-	}
-	log.WithContext(ctx, logger).Log(level,
+	level, code, errMsg := extractError(err)
+	kvs := []interface{}{
 		"kind", "server",
 		"component", "http",
 		"http.target", info.Request.RequestURI,
 		"http.method", info.Request.Method,
-		"http.args", args,
+	}
+	kvs = append(kvs, p.keyvals("http")...)
+	kvs = append(kvs,
 		"http.code", code,
 		"http.error", errMsg,
 	)
+	log.WithContext(ctx, logger).Log(level, kvs...)
 }
 
-func httpClientLog(logger log.Logger, ctx context.Context, args string, err error) {
+func httpClientLog(logger log.Logger, ctx context.Context, p payload, err error) {
 	info, ok := http.FromClientContext(ctx)
 	if !ok {
 		return
 	}
-	code, errMsg := extractError(err)
-	level := log.LevelInfo
-	if err != nil {
-		level = log.LevelError
-	}
-	log.WithContext(ctx, logger).Log(level,
+	level, code, errMsg := extractError(err)
+	kvs := []interface{}{
 		"kind", "client",
 		"component", "http",
 		"http.target", info.Request.RequestURI,
 		"http.method", info.Request.Method,
-		"http.args", args,
+	}
+	kvs = append(kvs, p.keyvals("http")...)
+	kvs = append(kvs,
 		"http.code", code,
 		"http.error", errMsg,
 	)
+	log.WithContext(ctx, logger).Log(level, kvs...)
 }
@@ -8,19 +8,72 @@ import (
 	"github.com/go-kratos/kratos/v2/log"
 	"github.com/go-kratos/kratos/v2/middleware"
 	"github.com/go-kratos/kratos/v2/transport"
+	"google.golang.org/protobuf/proto"
 )
 
+// Redactor redacts the sensitive fields of the message before it is logged,
+// the message is a clone of the request, so it can be modified in place.
+type Redactor func(msg proto.Message) proto.Message
+
+// Option is logging option.
+type Option func(*options)
+
+type options struct {
+	redactor Redactor
+	sampling bool
+	rate     float64
+	forceKey string
+}
+
+// WithRedactor with the redactor of the logged proto requests.
+func WithRedactor(r Redactor) Option {
+	return func(o *options) {
+		o.redactor = r
+	}
+}
+
+// WithSampling with the rate in [0, 1] of the requests logged verbosely including the request
+// and the reply payloads, the others get the terse access logs without the payloads.
+// By default the request payloads of all the requests are logged.
+func WithSampling(rate float64) Option {
+	return func(o *options) {
+		o.sampling = true
+		o.rate = rate
+	}
+}
+
+// WithForceKey with the header or metadata key which forces the verbose logging of the request
+// when the value is true, e.g. x-md-debug, which can be propagated to the downstream services.
+// It enables the sampling as WithSampling.
+func WithForceKey(key string) Option {
+	return func(o *options) {
+		o.sampling = true
+		o.forceKey = key
+	}
+}
+
 // Server is an server logging middleware.
-func Server(logger log.Logger) middleware.Middleware {
+// The decision of the verbose logging is stored in the context with the sampling enabled,
+// see VerboseFromContext.
+func Server(logger log.Logger, opts ...Option) middleware.Middleware {
+	options := options{}
+	for _, o := range opts {
+		o(&options)
+	}
 	return func(handler middleware.Handler) middleware.Handler {
 		return func(ctx context.Context, req interface{}) (reply interface{}, err error) {
+			var verbose bool
+			if options.sampling {
+				verbose = options.verbose(ctx)
+				ctx = NewVerboseContext(ctx, verbose)
+			}
 			reply, err = handler(ctx, req)
 			if tr, ok := transport.FromContext(ctx); ok {
 				switch tr.Kind {
 				case transport.KindHTTP:
-					httpServerLog(logger, ctx, extractArgs(req), err)
+					httpServerLog(logger, ctx, options.payload(verbose, req, reply), err)
 				case transport.KindGRPC:
-					grpcServerLog(logger, ctx, extractArgs(req), err)
+					grpcServerLog(logger, ctx, options.payload(verbose, req, reply), err)
 				}
 			}
 			return
@@ -29,16 +82,26 @@ func Server(logger log.Logger) middleware.Middleware {
 }
 
 // Client is an client logging middleware.
-func Client(logger log.Logger) middleware.Middleware {
+// The decision of the verbose logging in the context is honored with the sampling enabled,
+// so the calls of a sampled request are logged verbosely as well.
+func Client(logger log.Logger, opts ...Option) middleware.Middleware {
+	options := options{}
+	for _, o := range opts {
+		o(&options)
+	}
 	return func(handler middleware.Handler) middleware.Handler {
 		return func(ctx context.Context, req interface{}) (reply interface{}, err error) {
+			var verbose bool
+			if options.sampling {
+				verbose = options.verbose(ctx)
+			}
 			reply, err = handler(ctx, req)
 			if tr, ok := transport.FromContext(ctx); ok {
 				switch tr.Kind {
 				case transport.KindHTTP:
-					httpClientLog(logger, ctx, extractArgs(req), err)
+					httpClientLog(logger, ctx, options.payload(verbose, req, reply), err)
 				case transport.KindGRPC:
-					grpcClientLog(logger, ctx, extractArgs(req), err)
+					grpcClientLog(logger, ctx, options.payload(verbose, req, reply), err)
 				}
 			}
 			return
@@ -46,17 +109,27 @@ func Client(logger log.Logger) middleware.Middleware {
 	}
 }
 
-func extractArgs(req interface{}) string {
+func extractArgs(req interface{}, redactor Redactor) string {
+	if m, ok := req.(proto.Message); ok && redactor != nil {
+		// never mutates the request passed to the handler
+		req = redactor(proto.Clone(m))
+	}
 	if stringer, ok := req.(fmt.Stringer); ok {
 		return stringer.String()
 	}
 	return fmt.Sprintf("%+v", req)
 }
 
-func extractError(err error) (code int, errMsg string) {
-	if err != nil {
-		code = errors.Code(err)
-		errMsg = fmt.Sprintf("%+v", err)
+// extractError returns the level, code and message of the error, the deadline exceeded
+// and the cancellation are warnings, since they are usually caused by the clients.
+func extractError(err error) (level log.Level, code int, errMsg string) {
+	if err == nil {
+		return log.LevelInfo, 0, ""
+	}
+	code = errors.Code(err)
+	level = log.LevelError
+	if errors.IsGatewayTimeout(err) || errors.IsClientClosed(err) {
+		level = log.LevelWarn
 	}
-	return
+	return level, code, fmt.Sprintf("%+v", err)
 }
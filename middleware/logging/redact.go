@@ -0,0 +1,63 @@
+package logging
+
+import (
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const redacted = "***"
+
+// RedactFields returns a redactor which redacts the fields by the paths of the proto
+// field names, e.g. password or user.credentials.token, the repeated and map fields
+// of messages apply to each element. The string fields are replaced by *** and
+// the others are cleared.
+func RedactFields(paths ...string) Redactor {
+	fields := make([][]string, 0, len(paths))
+	for _, p := range paths {
+		fields = append(fields, strings.Split(p, "."))
+	}
+	return func(msg proto.Message) proto.Message {
+		m := msg.ProtoReflect()
+		for _, path := range fields {
+			redactPath(m, path)
+		}
+		return msg
+	}
+}
+
+func redactPath(m protoreflect.Message, path []string) {
+	fd := m.Descriptor().Fields().ByName(protoreflect.Name(path[0]))
+	if fd == nil || !m.Has(fd) {
+		return
+	}
+	if len(path) == 1 {
+		if fd.Kind() == protoreflect.StringKind && fd.Cardinality() != protoreflect.Repeated {
+			m.Set(fd, protoreflect.ValueOfString(redacted))
+		} else {
+			m.Clear(fd)
+		}
+		return
+	}
+	switch {
+	case fd.IsMap():
+		if fd.MapValue().Message() == nil {
+			return
+		}
+		m.Get(fd).Map().Range(func(_ protoreflect.MapKey, v protoreflect.Value) bool {
+			redactPath(v.Message(), path[1:])
+			return true
+		})
+	case fd.IsList():
+		if fd.Message() == nil {
+			return
+		}
+		list := m.Get(fd).List()
+		for i := 0; i < list.Len(); i++ {
+			redactPath(list.Get(i).Message(), path[1:])
+		}
+	case fd.Message() != nil:
+		redactPath(m.Get(fd).Message(), path[1:])
+	}
+}
@@ -0,0 +1,25 @@
+package logging
+
+import (
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestRedactFields(t *testing.T) {
+	req, err := structpb.NewStruct(map[string]interface{}{
+		"name":     "kratos",
+		"password": "secret",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	args := extractArgs(req, RedactFields("fields.string_value"))
+	if strings.Contains(args, "secret") || !strings.Contains(args, "***") {
+		t.Fatalf("expected redacted got %s", args)
+	}
+	if req.Fields["password"].GetStringValue() != "secret" {
+		t.Fatal("expected the request not mutated")
+	}
+}
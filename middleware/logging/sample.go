@@ -0,0 +1,70 @@
+package logging
+
+import (
+	"context"
+	"math/rand"
+	"strconv"
+
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+type verboseKey struct{}
+
+// NewVerboseContext returns a new Context that carries the decision of the verbose logging.
+func NewVerboseContext(ctx context.Context, verbose bool) context.Context {
+	return context.WithValue(ctx, verboseKey{}, verbose)
+}
+
+// VerboseFromContext returns the decision of the verbose logging stored in ctx, if any,
+// which can be used by the downstream loggers to keep the logs of the request consistent.
+func VerboseFromContext(ctx context.Context) (verbose bool, ok bool) {
+	verbose, ok = ctx.Value(verboseKey{}).(bool)
+	return
+}
+
+// payload is the logged payloads of the request and the reply, the terse logs have none.
+type payload struct {
+	args  string
+	reply string
+	terse bool
+}
+
+func (p payload) keyvals(component string) []interface{} {
+	if p.terse {
+		return nil
+	}
+	kvs := []interface{}{component + ".args", p.args}
+	if p.reply != "" {
+		kvs = append(kvs, component+".reply", p.reply)
+	}
+	return kvs
+}
+
+// verbose returns the decision of the verbose logging, which is made only once per request,
+// the decision in the context is honored, then the force-log key and the sampling rate.
+func (o *options) verbose(ctx context.Context) bool {
+	if verbose, ok := VerboseFromContext(ctx); ok {
+		return verbose
+	}
+	if o.forceKey != "" {
+		if force, err := strconv.ParseBool(transport.RequestHeader(ctx, o.forceKey)); err == nil && force {
+			return true
+		}
+	}
+	return o.rate > 0 && rand.Float64() < o.rate
+}
+
+// payload returns the logged payloads, the args only if the sampling is disabled.
+func (o *options) payload(verbose bool, req, reply interface{}) payload {
+	if !o.sampling {
+		return payload{args: extractArgs(req, o.redactor)}
+	}
+	if !verbose {
+		return payload{terse: true}
+	}
+	p := payload{args: extractArgs(req, o.redactor)}
+	if reply != nil {
+		p.reply = extractArgs(reply, o.redactor)
+	}
+	return p
+}
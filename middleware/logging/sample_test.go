@@ -0,0 +1,52 @@
+package logging
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+func TestSampling(t *testing.T) {
+	tests := []struct {
+		opts    []Option
+		ctx     context.Context
+		verbose bool
+	}{
+		{[]Option{WithSampling(1)}, context.Background(), true},
+		{[]Option{WithSampling(0)}, context.Background(), false},
+		// the decision of the upstream is honored
+		{[]Option{WithSampling(1)}, NewVerboseContext(context.Background(), false), false},
+		{[]Option{WithForceKey("x-md-debug")}, NewVerboseContext(context.Background(), true), true},
+	}
+	for _, test := range tests {
+		var (
+			verbose bool
+			ok      bool
+		)
+		next := Server(log.DefaultLogger, test.opts...)(func(ctx context.Context, req interface{}) (interface{}, error) {
+			verbose, ok = VerboseFromContext(ctx)
+			return "reply", nil
+		})
+		if _, err := next(test.ctx, "req"); err != nil {
+			t.Fatal(err)
+		}
+		if !ok || verbose != test.verbose {
+			t.Errorf("got verbose %v %v want %v", verbose, ok, test.verbose)
+		}
+	}
+}
+
+func TestPayload(t *testing.T) {
+	o := &options{}
+	if p := o.payload(false, "req", "reply"); p.terse || p.args != "req" || p.reply != "" {
+		t.Errorf("unexpected payload without sampling %+v", p)
+	}
+	WithSampling(0.01)(o)
+	if p := o.payload(false, "req", "reply"); !p.terse || p.keyvals("grpc") != nil {
+		t.Errorf("unexpected terse payload %+v", p)
+	}
+	if p := o.payload(true, "req", "reply"); p.args != "req" || p.reply != "reply" || len(p.keyvals("grpc")) != 4 {
+		t.Errorf("unexpected verbose payload %+v", p)
+	}
+}
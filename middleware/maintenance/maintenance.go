@@ -0,0 +1,88 @@
+package maintenance
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// Switch is the maintenance mode switch, which is safe for concurrent use.
+type Switch struct {
+	enabled int32
+}
+
+// Set turns the maintenance mode on or off.
+func (s *Switch) Set(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&s.enabled, v)
+}
+
+// Enabled reports whether the maintenance mode is on.
+func (s *Switch) Enabled() bool {
+	return atomic.LoadInt32(&s.enabled) == 1
+}
+
+// Watch sets the switch by the bool value of the config key, and keeps it
+// in sync when the config is reloaded.
+func (s *Switch) Watch(c config.Config, key string) error {
+	enabled, err := c.Value(key).Bool()
+	if err != nil {
+		return err
+	}
+	s.Set(enabled)
+	return c.Watch(key, func(_ string, v config.Value) {
+		if enabled, err := v.Bool(); err == nil {
+			s.Set(enabled)
+		}
+	})
+}
+
+// Option is maintenance option.
+type Option func(*options)
+
+type options struct {
+	retryAfter time.Duration
+}
+
+// WithRetryAfter with the Retry-After of the rejected operations, the default is 1 minute.
+func WithRetryAfter(d time.Duration) Option {
+	return func(o *options) {
+		o.retryAfter = d
+	}
+}
+
+// Server is a server middleware which rejects the operations not on the
+// read-only allowlist with FailedPrecondition while in the maintenance mode.
+// The operation is the full method for gRPC and the path template for HTTP.
+func Server(s *Switch, allowlist []string, opts ...Option) middleware.Middleware {
+	options := options{
+		retryAfter: time.Minute,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+	allowed := make(map[string]struct{}, len(allowlist))
+	for _, op := range allowlist {
+		allowed[op] = struct{}{}
+	}
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			if !s.Enabled() {
+				return handler(ctx, req)
+			}
+			if _, ok := allowed[transport.Operation(ctx)]; ok {
+				return handler(ctx, req)
+			}
+			return nil, errors.PreconditionFailed("MAINTENANCE", "the service is in maintenance mode").
+				WithRetryAfter(options.retryAfter)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package maintenance
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/transport/grpc"
+)
+
+func TestServer(t *testing.T) {
+	s := new(Switch)
+	next := Server(s, []string{"/test.Test/Get"})(func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	})
+	call := func(method string) error {
+		ctx := grpc.NewServerContext(context.Background(), grpc.ServerInfo{FullMethod: method})
+		_, err := next(ctx, nil)
+		return err
+	}
+	if err := call("/test.Test/Update"); err != nil {
+		t.Fatal(err)
+	}
+	s.Set(true)
+	if err := call("/test.Test/Get"); err != nil {
+		t.Fatal(err)
+	}
+	err := call("/test.Test/Update")
+	if !errors.IsPreconditionFailed(err) {
+		t.Fatalf("expected precondition failed got %v", err)
+	}
+	if d, ok := errors.RetryAfter(err); !ok || d != time.Minute {
+		t.Fatalf("expected the default retry delay got %v", d)
+	}
+	s.Set(false)
+	if err := call("/test.Test/Update"); err != nil {
+		t.Fatal(err)
+	}
+}
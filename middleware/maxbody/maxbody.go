@@ -0,0 +1,86 @@
+package maxbody
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	transhttp "github.com/go-kratos/kratos/v2/transport/http"
+)
+
+// Option is max body option.
+type Option func(*options)
+
+type options struct {
+	overrides map[string]int64
+}
+
+// WithOverrides with the limits by the path prefix, e.g. /v1/upload, the longest prefix wins.
+func WithOverrides(overrides map[string]int64) Option {
+	return func(o *options) {
+		o.overrides = overrides
+	}
+}
+
+// Server is a server filter of the HTTP transport which limits the bytes of the request body,
+// the requests over the limit are rejected with 413 by the Content-Length before reading,
+// or while streaming the body without Content-Length such as the chunked transfer encoding,
+// before the body is buffered entirely, e.g. http.Filter(maxbody.Server(4 << 20)).
+func Server(limit int64, opts ...Option) transhttp.FilterFunc {
+	options := options{}
+	for _, o := range opts {
+		o(&options)
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			n := options.limit(r.URL.Path, limit)
+			if n <= 0 || r.Body == nil || r.Body == http.NoBody {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if r.ContentLength > n {
+				transhttp.DefaultErrorEncoder(w, r, tooLarge(n))
+				return
+			}
+			r.Body = &limitedBody{body: http.MaxBytesReader(w, r.Body, n), limit: n}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func (o *options) limit(path string, limit int64) int64 {
+	var prefix string
+	for p, n := range o.overrides {
+		if strings.HasPrefix(path, p) && len(p) > len(prefix) {
+			prefix, limit = p, n
+		}
+	}
+	return limit
+}
+
+func tooLarge(limit int64) error {
+	return errors.New(http.StatusRequestEntityTooLarge, "REQUEST_ENTITY_TOO_LARGE",
+		fmt.Sprintf("the request body exceeds the limit of %d bytes", limit))
+}
+
+// limitedBody converts the error of the MaxBytesReader to a kratos error.
+type limitedBody struct {
+	body  io.ReadCloser
+	limit int64
+	read  int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	n, err := b.body.Read(p)
+	b.read += int64(n)
+	if err != nil && err != io.EOF && b.read >= b.limit {
+		return n, tooLarge(b.limit)
+	}
+	return n, err
+}
+
+func (b *limitedBody) Close() error {
+	return b.body.Close()
+}
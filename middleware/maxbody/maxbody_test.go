@@ -0,0 +1,75 @@
+package maxbody
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	transhttp "github.com/go-kratos/kratos/v2/transport/http"
+)
+
+func TestServer(t *testing.T) {
+	var readErr error
+	h := Server(8, WithOverrides(map[string]int64{"/upload": 16}))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, readErr = ioutil.ReadAll(r.Body)
+	}))
+	tests := []struct {
+		path    string
+		body    string
+		chunked bool
+		code    int
+	}{
+		{"/users", "1234", false, 0},
+		{"/users", "123456789", false, http.StatusRequestEntityTooLarge},
+		{"/users", "123456789", true, http.StatusRequestEntityTooLarge},
+		{"/upload", "123456789", true, 0},
+	}
+	for _, test := range tests {
+		readErr = nil
+		req := httptest.NewRequest(http.MethodPost, test.path, strings.NewReader(test.body))
+		if test.chunked {
+			req.ContentLength = -1
+		}
+		res := httptest.NewRecorder()
+		h.ServeHTTP(res, req)
+		code := errors.Code(readErr)
+		if res.Code != http.StatusOK {
+			code = res.Code
+		}
+		if code != test.code {
+			t.Errorf("%s %q: expected %d got %d", test.path, test.body, test.code, code)
+		}
+	}
+}
+
+func TestServerDecode(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []transhttp.HandleOption
+	}{
+		{"default", nil},
+		{"naming", []transhttp.HandleOption{transhttp.JSONFieldNaming(transhttp.NamingCamelCase)}},
+		{"strict", []transhttp.HandleOption{transhttp.WithDiscardUnknown(false)}},
+	}
+	for _, test := range tests {
+		o := transhttp.DefaultHandleOptions()
+		for _, opt := range test.opts {
+			opt(&o)
+		}
+		var decodeErr error
+		h := Server(8)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var v map[string]interface{}
+			decodeErr = o.Decode(r, &v)
+		}))
+		req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`{"name":"kratos"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.ContentLength = -1
+		h.ServeHTTP(httptest.NewRecorder(), req)
+		if code := errors.Code(decodeErr); code != http.StatusRequestEntityTooLarge {
+			t.Errorf("%s: expected %d got %d", test.name, http.StatusRequestEntityTooLarge, code)
+		}
+	}
+}
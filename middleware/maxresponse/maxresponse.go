@@ -0,0 +1,83 @@
+package maxresponse
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport/grpc"
+	transhttp "github.com/go-kratos/kratos/v2/transport/http"
+	"google.golang.org/protobuf/proto"
+)
+
+// Option is max response option.
+type Option func(*options)
+
+type options struct {
+	overrides map[string]int
+}
+
+// WithOverrides with the limits by the operation, the full method for gRPC and
+// the path template for HTTP, e.g. /blob.Blob/Download, a limit of 0 is unlimited.
+func WithOverrides(overrides map[string]int) Option {
+	return func(o *options) {
+		o.overrides = overrides
+	}
+}
+
+// Client is a client middleware which limits the size of the decoded responses,
+// the responses over the limit are discarded with a 502 RESPONSE_TOO_LARGE error,
+// so that a huge response does not flow through the caller. The size is the proto
+// size of the proto messages and the JSON size of the others.
+// It does not bound the memory of decoding, which should be limited by the transport
+// as well, e.g. the grpc.MaxCallRecvMsgSize call option, which also covers the streams.
+func Client(limit int, opts ...Option) middleware.Middleware {
+	options := options{}
+	for _, o := range opts {
+		o(&options)
+	}
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			reply, err := handler(ctx, req)
+			if err != nil || reply == nil {
+				return reply, err
+			}
+			n := limit
+			if override, ok := options.overrides[operation(ctx)]; ok {
+				n = override
+			}
+			if n <= 0 {
+				return reply, nil
+			}
+			if size := sizeOf(reply); size > n {
+				return nil, errors.New(http.StatusBadGateway, "RESPONSE_TOO_LARGE",
+					fmt.Sprintf("the response of %d bytes exceeds the limit of %d bytes", size, n))
+			}
+			return reply, nil
+		}
+	}
+}
+
+func operation(ctx context.Context) string {
+	if info, ok := grpc.FromClientContext(ctx); ok {
+		return info.FullMethod
+	}
+	if info, ok := transhttp.FromClientContext(ctx); ok {
+		return info.PathPattern
+	}
+	return ""
+}
+
+func sizeOf(reply interface{}) int {
+	if m, ok := reply.(proto.Message); ok {
+		return proto.Size(m)
+	}
+	data, err := json.Marshal(reply)
+	if err != nil {
+		return 0
+	}
+	return len(data)
+}
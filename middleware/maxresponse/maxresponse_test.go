@@ -0,0 +1,30 @@
+package maxresponse
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/transport/grpc"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestClient(t *testing.T) {
+	next := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return wrapperspb.String(strings.Repeat("x", req.(int))), nil
+	}
+	m := Client(100, WithOverrides(map[string]int{"/blob.Blob/Download": 0}))(next)
+	ctx := grpc.NewClientContext(context.Background(), grpc.ClientInfo{FullMethod: "/test.Test/Call"})
+	if _, err := m(ctx, 10); err != nil {
+		t.Fatal(err)
+	}
+	_, err := m(ctx, 1000)
+	if errors.Code(err) != 502 || errors.Reason(err) != "RESPONSE_TOO_LARGE" {
+		t.Fatalf("expected the response too large got %v", err)
+	}
+	ctx = grpc.NewClientContext(context.Background(), grpc.ClientInfo{FullMethod: "/blob.Blob/Download"})
+	if _, err := m(ctx, 1000); err != nil {
+		t.Fatalf("expected the overridden operation unlimited got %v", err)
+	}
+}
@@ -2,6 +2,9 @@ package middleware
 
 import (
 	"context"
+	"reflect"
+	"runtime"
+	"strings"
 )
 
 // Handler defines the handler invoked by Middleware.
@@ -19,3 +22,23 @@ func Chain(m ...Middleware) Middleware {
 		return next
 	}
 }
+
+// Name returns the name of the func which created the middleware, e.g.
+// github.com/go-kratos/kratos/v2/middleware/recovery.Recovery, so the enabled
+// middlewares can be listed, it is empty if the name is unknown.
+func Name(m Middleware) string {
+	if m == nil {
+		return ""
+	}
+	f := runtime.FuncForPC(reflect.ValueOf(m).Pointer())
+	if f == nil {
+		return ""
+	}
+	name := f.Name()
+	// trims the closure suffix, e.g. .func1 or .func1.2
+	pkg := strings.LastIndex(name, "/") + 1
+	if i := strings.Index(name[pkg:], ".func"); i > 0 {
+		name = name[:pkg+i]
+	}
+	return name
+}
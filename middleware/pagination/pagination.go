@@ -0,0 +1,131 @@
+package pagination
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Page is the pagination parameters of the request.
+type Page struct {
+	// Size is the page size, which is clamped to the max size.
+	Size int
+	// Token is the page token of the next page.
+	Token string
+	// Offset is the offset of the first item.
+	Offset int
+}
+
+type pageKey struct{}
+
+// NewContext returns a new Context that carries value.
+func NewContext(ctx context.Context, p Page) context.Context {
+	return context.WithValue(ctx, pageKey{}, p)
+}
+
+// FromContext returns the Page value stored in ctx, if any.
+func FromContext(ctx context.Context) (p Page, ok bool) {
+	p, ok = ctx.Value(pageKey{}).(Page)
+	return
+}
+
+// Option is pagination option.
+type Option func(*options)
+
+type options struct {
+	defaultSize int
+	maxSize     int
+}
+
+// WithDefaultSize with the default page size if not specified.
+func WithDefaultSize(size int) Option {
+	return func(o *options) {
+		o.defaultSize = size
+	}
+}
+
+// WithMaxSize with the max page size.
+func WithMaxSize(size int) Option {
+	return func(o *options) {
+		o.maxSize = size
+	}
+}
+
+// Server is a server middleware which parses the pagination parameters from the
+// page_size/page_token or limit/offset fields of the request message by convention,
+// and rejects the request if both of page_size and limit are set to different sizes.
+func Server(opts ...Option) middleware.Middleware {
+	options := options{
+		defaultSize: 20,
+		maxSize:     100,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			m, ok := req.(proto.Message)
+			if !ok {
+				return handler(ctx, req)
+			}
+			var (
+				page  Page
+				found bool
+				msg   = m.ProtoReflect()
+			)
+			for _, name := range []protoreflect.Name{"page_size", "limit"} {
+				if size, ok := intField(msg, name); ok {
+					if size < 0 {
+						return nil, errors.BadRequest("PAGINATION", fmt.Sprintf("invalid %s: %d", name, size))
+					}
+					if size > 0 && page.Size > 0 && int(size) != page.Size {
+						return nil, errors.BadRequest("PAGINATION", fmt.Sprintf("conflicting page_size and limit: %d != %d", page.Size, size))
+					}
+					if size > 0 || !found {
+						page.Size = int(size)
+					}
+					found = true
+				}
+			}
+			if offset, ok := intField(msg, "offset"); ok {
+				if offset < 0 {
+					return nil, errors.BadRequest("PAGINATION", fmt.Sprintf("invalid offset: %d", offset))
+				}
+				page.Offset, found = int(offset), true
+			}
+			if fd := msg.Descriptor().Fields().ByName("page_token"); fd != nil && fd.Kind() == protoreflect.StringKind {
+				page.Token, found = msg.Get(fd).String(), true
+			}
+			if !found {
+				return handler(ctx, req)
+			}
+			if page.Size == 0 {
+				page.Size = options.defaultSize
+			}
+			if options.maxSize > 0 && page.Size > options.maxSize {
+				page.Size = options.maxSize
+			}
+			return handler(NewContext(ctx, page), req)
+		}
+	}
+}
+
+func intField(msg protoreflect.Message, name protoreflect.Name) (int64, bool) {
+	fd := msg.Descriptor().Fields().ByName(name)
+	if fd == nil || fd.Cardinality() == protoreflect.Repeated {
+		return 0, false
+	}
+	switch fd.Kind() {
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return msg.Get(fd).Int(), true
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return int64(msg.Get(fd).Uint()), true
+	}
+	return 0, false
+}
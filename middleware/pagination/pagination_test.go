@@ -0,0 +1,103 @@
+package pagination
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+func newRequest(t *testing.T, values map[string]interface{}) proto.Message {
+	field := func(name string, typ descriptorpb.FieldDescriptorProto_Type, number int32) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			JsonName: proto.String(name),
+			Number:   proto.Int32(number),
+			Type:     typ.Enum(),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		}
+	}
+	fd, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:    proto.String("pagination_test.proto"),
+		Package: proto.String("pagination.test"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("ListRequest"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				field("page_size", descriptorpb.FieldDescriptorProto_TYPE_INT32, 1),
+				field("page_token", descriptorpb.FieldDescriptorProto_TYPE_STRING, 2),
+				field("limit", descriptorpb.FieldDescriptorProto_TYPE_UINT32, 3),
+				field("offset", descriptorpb.FieldDescriptorProto_TYPE_INT64, 4),
+			},
+		}},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := dynamicpb.NewMessage(fd.Messages().Get(0))
+	for name, v := range values {
+		msg.Set(msg.Descriptor().Fields().ByName(protoreflect.Name(name)), protoreflect.ValueOf(v))
+	}
+	return msg
+}
+
+func TestServer(t *testing.T) {
+	tests := []struct {
+		name   string
+		values map[string]interface{}
+		page   Page
+		reason string
+	}{
+		{"default", nil, Page{Size: 20}, ""},
+		{"page_size", map[string]interface{}{"page_size": int32(10), "page_token": "next"}, Page{Size: 10, Token: "next"}, ""},
+		{"limit", map[string]interface{}{"limit": uint32(30), "offset": int64(5)}, Page{Size: 30, Offset: 5}, ""},
+		{"max size", map[string]interface{}{"page_size": int32(1000)}, Page{Size: 100}, ""},
+		{"same sizes", map[string]interface{}{"page_size": int32(10), "limit": uint32(10)}, Page{Size: 10}, ""},
+		{"limit only", map[string]interface{}{"page_size": int32(0), "limit": uint32(10)}, Page{Size: 10}, ""},
+		{"conflicting sizes", map[string]interface{}{"page_size": int32(10), "limit": uint32(30)}, Page{}, "PAGINATION"},
+		{"negative size", map[string]interface{}{"page_size": int32(-1)}, Page{}, "PAGINATION"},
+		{"negative offset", map[string]interface{}{"offset": int64(-1)}, Page{}, "PAGINATION"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				page Page
+				ok   bool
+			)
+			h := Server()(func(ctx context.Context, req interface{}) (interface{}, error) {
+				page, ok = FromContext(ctx)
+				return nil, nil
+			})
+			_, err := h(context.Background(), newRequest(t, test.values))
+			if test.reason != "" {
+				if errors.Reason(err) != test.reason {
+					t.Fatalf("expected reason %s got %v", test.reason, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !ok || page != test.page {
+				t.Fatalf("expected %+v got %+v", test.page, page)
+			}
+		})
+	}
+}
+
+func TestServerNotProto(t *testing.T) {
+	h := Server()(func(ctx context.Context, req interface{}) (interface{}, error) {
+		if _, ok := FromContext(ctx); ok {
+			t.Error("expected no page of the non-proto request")
+		}
+		return nil, nil
+	})
+	if _, err := h(context.Background(), "request"); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,116 @@
+package priority
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// Priority is the priority class of the request.
+type Priority int
+
+// Defines the priority classes, the requests without a priority are Normal.
+const (
+	Low Priority = iota
+	Normal
+	High
+)
+
+func (p Priority) String() string {
+	switch p {
+	case Low:
+		return "low"
+	case High:
+		return "high"
+	}
+	return "normal"
+}
+
+// Parse parses the priority class of low, normal or high.
+func Parse(s string) (Priority, bool) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "low":
+		return Low, true
+	case "normal":
+		return Normal, true
+	case "high":
+		return High, true
+	}
+	return Normal, false
+}
+
+type priorityKey struct{}
+
+// NewContext returns a new Context that carries the priority.
+func NewContext(ctx context.Context, p Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, p)
+}
+
+// FromContext returns the priority stored in ctx, if any.
+func FromContext(ctx context.Context) (p Priority, ok bool) {
+	p, ok = ctx.Value(priorityKey{}).(Priority)
+	return
+}
+
+// Option is priority option.
+type Option func(*options)
+
+type options struct {
+	key    string
+	low    float64
+	normal float64
+}
+
+// WithHeader with the metadata key of the priority, the default is x-md-priority.
+func WithHeader(key string) Option {
+	return func(o *options) {
+		o.key = key
+	}
+}
+
+// WithThresholds with the ratios of the limit, over which the low and the normal
+// requests are shed, the default is 0.5 and 0.8, so the high requests always
+// have 20% of the limit reserved.
+func WithThresholds(low, normal float64) Option {
+	return func(o *options) {
+		o.low = low
+		o.normal = normal
+	}
+}
+
+// Server is a server middleware which limits the concurrent requests, and sheds the
+// lower priority requests first once the in-flight requests reach their thresholds,
+// the shed requests are rejected with ServiceUnavailable. The priority of the request
+// is read from the metadata and stored in the context, e.g. for logging.
+func Server(limit int64, opts ...Option) middleware.Middleware {
+	o := options{
+		key:    "x-md-priority",
+		low:    0.5,
+		normal: 0.8,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	thresholds := map[Priority]int64{
+		Low:    int64(float64(limit) * o.low),
+		Normal: int64(float64(limit) * o.normal),
+		High:   limit,
+	}
+	var inflight int64
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			p, _ := Parse(transport.RequestHeader(ctx, o.key))
+			ctx = NewContext(ctx, p)
+			if n := atomic.AddInt64(&inflight, 1); n > thresholds[p] {
+				atomic.AddInt64(&inflight, -1)
+				return nil, errors.ServiceUnavailable("PRIORITY_SHED", "the "+p.String()+" priority request is shed by the overload")
+			}
+			defer atomic.AddInt64(&inflight, -1)
+			return handler(ctx, req)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package priority
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/transport/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestServer(t *testing.T) {
+	var (
+		release = make(chan struct{})
+		started = make(chan struct{})
+	)
+	m := Server(10)(func(ctx context.Context, req interface{}) (interface{}, error) {
+		if req == "block" {
+			started <- struct{}{}
+			<-release
+		}
+		p, _ := FromContext(ctx)
+		return p, nil
+	})
+	call := func(priority string, req interface{}) (interface{}, error) {
+		ctx := grpc.NewServerContext(context.Background(), grpc.ServerInfo{FullMethod: "/test.Test/Call"})
+		ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("x-md-priority", priority))
+		return m(ctx, req)
+	}
+	// 6 in-flight requests are over the low threshold and within the normal one
+	done := make(chan struct{})
+	for i := 0; i < 6; i++ {
+		go func() {
+			_, _ = call("high", "block")
+			done <- struct{}{}
+		}()
+		<-started
+	}
+	if _, err := call("low", "ok"); !errors.IsServiceUnavailable(err) {
+		t.Fatalf("expected the low priority shed got %v", err)
+	}
+	if p, err := call("normal", "ok"); err != nil || p != Normal {
+		t.Fatalf("expected the normal priority served got %v %v", p, err)
+	}
+	if p, err := call("", "ok"); err != nil || p != Normal {
+		t.Fatalf("expected the default normal priority got %v %v", p, err)
+	}
+	close(release)
+	for i := 0; i < 6; i++ {
+		<-done
+	}
+	if p, err := call("low", "ok"); err != nil || p != Low {
+		t.Fatalf("expected the low priority served got %v %v", p, err)
+	}
+}
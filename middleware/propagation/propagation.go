@@ -0,0 +1,132 @@
+package propagation
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/go-kratos/kratos/v2/transport/grpc"
+	"github.com/go-kratos/kratos/v2/transport/http"
+	"google.golang.org/grpc/metadata"
+)
+
+// EncodeFunc encodes a context value into a metadata value.
+type EncodeFunc func(v interface{}) (string, error)
+
+// DecodeFunc decodes a metadata value into a context value.
+type DecodeFunc func(s string) (interface{}, error)
+
+type field struct {
+	ctxKey  interface{}
+	metaKey string
+	encode  EncodeFunc
+	decode  DecodeFunc
+}
+
+var (
+	mu     sync.RWMutex
+	fields []field
+)
+
+// Register registers a context key which is propagated by the metadata key.
+// The value stored in context by ctxKey is encoded into the outgoing gRPC metadata
+// or HTTP header by the client, and decoded into the context by the server.
+func Register(ctxKey interface{}, metaKey string, encode EncodeFunc, decode DecodeFunc) {
+	mu.Lock()
+	defer mu.Unlock()
+	f := field{
+		ctxKey:  ctxKey,
+		metaKey: strings.ToLower(metaKey),
+		encode:  encode,
+		decode:  decode,
+	}
+	next := make([]field, 0, len(fields)+1)
+	for _, o := range fields {
+		if o.ctxKey != ctxKey {
+			next = append(next, o)
+		}
+	}
+	fields = append(next, f)
+}
+
+// String returns the encode and decode funcs for string context values.
+func String() (EncodeFunc, DecodeFunc) {
+	encode := func(v interface{}) (string, error) {
+		s, _ := v.(string)
+		return s, nil
+	}
+	decode := func(s string) (interface{}, error) {
+		return s, nil
+	}
+	return encode, decode
+}
+
+func registered() []field {
+	mu.RLock()
+	defer mu.RUnlock()
+	return fields
+}
+
+// Server is a server middleware which decodes the registered
+// metadata values into the context.
+func Server() middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			if h, ok := transport.RequestHeaderCarrier(ctx); ok {
+				for _, f := range registered() {
+					s := h.Get(f.metaKey)
+					if s == "" {
+						continue
+					}
+					v, err := f.decode(s)
+					if err != nil {
+						return nil, errors.BadRequest("PROPAGATION", err.Error())
+					}
+					ctx = context.WithValue(ctx, f.ctxKey, v)
+				}
+			}
+			return handler(ctx, req)
+		}
+	}
+}
+
+// Client is a client middleware which encodes the registered
+// context values into the outgoing metadata.
+func Client() middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			var (
+				set func(key, value string)
+				md  metadata.MD
+			)
+			if info, ok := http.FromClientContext(ctx); ok {
+				set = info.Request.Header.Set
+			} else if _, ok := grpc.FromClientContext(ctx); ok {
+				if md, ok = metadata.FromOutgoingContext(ctx); !ok {
+					md = metadata.Pairs()
+				}
+				set = func(k, v string) { md.Set(k, v) }
+			}
+			if set != nil {
+				for _, f := range registered() {
+					v := ctx.Value(f.ctxKey)
+					if v == nil {
+						continue
+					}
+					s, err := f.encode(v)
+					if err != nil {
+						return nil, err
+					}
+					set(f.metaKey, s)
+				}
+				if md != nil {
+					ctx = metadata.NewOutgoingContext(ctx, md)
+				}
+			}
+			return handler(ctx, req)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package propagation
+
+import (
+	"context"
+	nethttp "net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/transport/grpc"
+	"github.com/go-kratos/kratos/v2/transport/http"
+	"google.golang.org/grpc/metadata"
+)
+
+type tenantKey struct{}
+
+func TestPropagation(t *testing.T) {
+	encode, decode := String()
+	Register(tenantKey{}, "X-Tenant", encode, decode)
+
+	var md metadata.MD
+	client := Client()(func(ctx context.Context, req interface{}) (interface{}, error) {
+		md, _ = metadata.FromOutgoingContext(ctx)
+		return nil, nil
+	})
+	ctx := context.WithValue(context.Background(), tenantKey{}, "kratos")
+	ctx = grpc.NewClientContext(ctx, grpc.ClientInfo{FullMethod: "/test.Test/Test"})
+	if _, err := client(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+	if v := md.Get("x-tenant"); len(v) != 1 || v[0] != "kratos" {
+		t.Fatalf("expected kratos got %v", v)
+	}
+
+	var tenant interface{}
+	server := Server()(func(ctx context.Context, req interface{}) (interface{}, error) {
+		tenant = ctx.Value(tenantKey{})
+		return nil, nil
+	})
+	ctx = metadata.NewIncomingContext(context.Background(), md)
+	ctx = grpc.NewServerContext(ctx, grpc.ServerInfo{FullMethod: "/test.Test/Test"})
+	if _, err := server(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+	if tenant != "kratos" {
+		t.Fatalf("expected kratos got %v", tenant)
+	}
+}
+
+func TestPropagationHTTP(t *testing.T) {
+	encode, decode := String()
+	Register(tenantKey{}, "X-Tenant", encode, decode)
+
+	req := httptest.NewRequest(nethttp.MethodGet, "/users", nil)
+	client := Client()(func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	})
+	ctx := context.WithValue(context.Background(), tenantKey{}, "kratos")
+	ctx = http.NewClientContext(ctx, http.ClientInfo{Request: req})
+	if _, err := client(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+	if v := req.Header.Get("X-Tenant"); v != "kratos" {
+		t.Fatalf("expected kratos got %q", v)
+	}
+
+	var tenant interface{}
+	server := Server()(func(ctx context.Context, req interface{}) (interface{}, error) {
+		tenant = ctx.Value(tenantKey{})
+		return nil, nil
+	})
+	ctx = http.NewServerContext(context.Background(), http.ServerInfo{Request: req})
+	if _, err := server(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+	if tenant != "kratos" {
+		t.Fatalf("expected kratos got %v", tenant)
+	}
+}
@@ -0,0 +1,56 @@
+package quota
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/clock"
+)
+
+var _ Store = (*MemoryStore)(nil)
+
+type window struct {
+	start time.Time
+	count int64
+}
+
+// MemoryOption is memory store option.
+type MemoryOption func(*MemoryStore)
+
+// WithClock with the clock of the windows, the default is the real clock.
+func WithClock(c clock.Clock) MemoryOption {
+	return func(s *MemoryStore) {
+		s.clock = c
+	}
+}
+
+// MemoryStore is an in-memory quota store of a single instance.
+type MemoryStore struct {
+	mu      sync.Mutex
+	windows map[string]*window
+	clock   clock.Clock
+}
+
+// NewMemoryStore new an in-memory quota store.
+func NewMemoryStore(opts ...MemoryOption) *MemoryStore {
+	s := &MemoryStore{windows: make(map[string]*window), clock: clock.New()}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+// Take counts a request of the key in the current window of the period.
+func (s *MemoryStore) Take(ctx context.Context, key string, period time.Duration) (int64, time.Duration, error) {
+	now := s.clock.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w, ok := s.windows[key]
+	if !ok || now.Sub(w.start) >= period {
+		w = &window{start: now}
+		s.windows[key] = w
+	}
+	w.count++
+	return w.count, w.start.Add(period).Sub(now), nil
+}
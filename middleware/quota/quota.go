@@ -0,0 +1,88 @@
+package quota
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// Limit is the quota of a tenant, which allows Requests per Period.
+type Limit struct {
+	Requests int64
+	Period   time.Duration
+}
+
+// Store is the quota store, which counts the requests in fixed windows.
+type Store interface {
+	// Take counts a request of the key in the current window of the period,
+	// it returns the count and the time left of the window.
+	Take(ctx context.Context, key string, period time.Duration) (count int64, reset time.Duration, err error)
+}
+
+// TenantFunc extracts the tenant of the request.
+type TenantFunc func(ctx context.Context) (tenant string, ok bool)
+
+// Option is quota option.
+type Option func(*options)
+
+type options struct {
+	tenant    TenantFunc
+	overrides map[string]Limit
+}
+
+// WithTenant with the tenant extractor, e.g. from the baggage,
+// the default is the x-tenant header or metadata.
+func WithTenant(fn TenantFunc) Option {
+	return func(o *options) {
+		o.tenant = fn
+	}
+}
+
+// WithOverrides with the limits of the tenants which override the default.
+func WithOverrides(overrides map[string]Limit) Option {
+	return func(o *options) {
+		o.overrides = overrides
+	}
+}
+
+// Header returns a TenantFunc which extracts the tenant from the header or metadata.
+func Header(key string) TenantFunc {
+	return func(ctx context.Context) (string, bool) {
+		tenant := transport.RequestHeader(ctx, key)
+		return tenant, tenant != ""
+	}
+}
+
+// Server is a server middleware which limits the requests of each tenant by its quota,
+// the exceeded requests are rejected with ResourceExhausted and the retry delay of the error,
+// which is the Retry-After header of HTTP and the RetryInfo of gRPC.
+// The requests without a tenant are not limited, and the requests are allowed
+// when the store fails, so that the quota store is not a single point of failure.
+func Server(store Store, limit Limit, opts ...Option) middleware.Middleware {
+	options := options{
+		tenant: Header("x-tenant"),
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			tenant, ok := options.tenant(ctx)
+			if !ok {
+				return handler(ctx, req)
+			}
+			l := limit
+			if o, ok := options.overrides[tenant]; ok {
+				l = o
+			}
+			count, reset, err := store.Take(ctx, tenant, l.Period)
+			if err != nil || count <= l.Requests {
+				return handler(ctx, req)
+			}
+			return nil, errors.New(429, "QUOTA_EXCEEDED", "tenant quota exceeded").WithRetryAfter(reset)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package quota
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/transport/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestServer(t *testing.T) {
+	next := Server(NewMemoryStore(), Limit{Requests: 1, Period: time.Minute}, WithOverrides(map[string]Limit{
+		"gold": {Requests: 2, Period: time.Minute},
+	}))(func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	})
+	call := func(tenant string) error {
+		ctx := grpc.NewServerContext(context.Background(), grpc.ServerInfo{FullMethod: "/test.Test/Test"})
+		ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("x-tenant", tenant))
+		_, err := next(ctx, nil)
+		return err
+	}
+	tests := []struct {
+		tenant   string
+		requests int
+	}{
+		{"silver", 1},
+		{"gold", 2},
+	}
+	for _, test := range tests {
+		for i := 0; i < test.requests; i++ {
+			if err := call(test.tenant); err != nil {
+				t.Fatal(err)
+			}
+		}
+		err := call(test.tenant)
+		if errors.Code(err) != 429 {
+			t.Fatalf("%s: expected quota exceeded got %v", test.tenant, err)
+		}
+		if d, ok := errors.RetryAfter(err); !ok || d <= 0 || d > time.Minute {
+			t.Fatalf("%s: expected the retry delay within the period got %v", test.tenant, d)
+		}
+	}
+}
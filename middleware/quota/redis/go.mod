@@ -0,0 +1,10 @@
+module github.com/go-kratos/kratos/middleware/quota/redis/v2
+
+go 1.15
+
+require (
+	github.com/go-kratos/kratos/v2 v2.0.0-rc1
+	github.com/go-redis/redis/v8 v8.9.0
+)
+
+replace github.com/go-kratos/kratos/v2 => ../../../
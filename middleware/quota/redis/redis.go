@@ -0,0 +1,63 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/middleware/quota"
+	"github.com/go-redis/redis/v8"
+)
+
+var _ quota.Store = (*Store)(nil)
+
+// the window starts with the first request, and expires after the period.
+var take = redis.NewScript(`
+local count = redis.call('INCR', KEYS[1])
+if count == 1 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+return {count, redis.call('PTTL', KEYS[1])}
+`)
+
+// Option is redis store option.
+type Option func(*Store)
+
+// Prefix with the prefix of the keys, the default is kratos:quota:.
+func Prefix(prefix string) Option {
+	return func(s *Store) {
+		s.prefix = prefix
+	}
+}
+
+// Store is a quota store shared by the instances via redis.
+type Store struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+// New new a redis quota store.
+func New(client redis.UniversalClient, opts ...Option) *Store {
+	s := &Store{
+		client: client,
+		prefix: "kratos:quota:",
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+// Take counts a request of the key in the current window of the period.
+func (s *Store) Take(ctx context.Context, key string, period time.Duration) (int64, time.Duration, error) {
+	res, err := take.Run(ctx, s.client, []string{s.prefix + key}, period.Milliseconds()).Result()
+	if err != nil {
+		return 0, 0, err
+	}
+	values, _ := res.([]interface{})
+	if len(values) != 2 {
+		return 0, 0, redis.Nil
+	}
+	count, _ := values[0].(int64)
+	ttl, _ := values[1].(int64)
+	return count, time.Duration(ttl) * time.Millisecond, nil
+}
@@ -0,0 +1,115 @@
+package record
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/middleware/logging"
+	"github.com/go-kratos/kratos/v2/middleware/sampling"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/go-kratos/kratos/v2/transport/grpc"
+	"github.com/go-kratos/kratos/v2/transport/http"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// Record is a recorded request.
+type Record struct {
+	Time time.Time      `json:"time"`
+	Kind transport.Kind `json:"kind"`
+	// Operation is the full method for gRPC and the path template for HTTP.
+	Operation string `json:"operation"`
+	// Method and URI are the method and the request URI of HTTP.
+	Method   string            `json:"method,omitempty"`
+	URI      string            `json:"uri,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+	Payload  json.RawMessage   `json:"payload,omitempty"`
+}
+
+// Store is the store of the records, it should not block the requests for long.
+type Store interface {
+	Save(ctx context.Context, r *Record) error
+}
+
+// Option is record option.
+type Option func(*options)
+
+type options struct {
+	redactor logging.Redactor
+	drop     map[string]struct{}
+}
+
+// WithRedactor with the redactor of the payload, e.g. logging.RedactFields.
+func WithRedactor(r logging.Redactor) Option {
+	return func(o *options) {
+		o.redactor = r
+	}
+}
+
+// WithDropMetadata with the metadata keys not recorded,
+// the default is authorization and cookie.
+func WithDropMetadata(keys ...string) Option {
+	return func(o *options) {
+		o.drop = make(map[string]struct{}, len(keys))
+		for _, k := range keys {
+			o.drop[strings.ToLower(k)] = struct{}{}
+		}
+	}
+}
+
+// Server is a server middleware which records the requests sampled by the sampler
+// to the store, so that they can be replayed by Replay for debugging.
+// The recording is best-effort, the errors of the store are ignored.
+func Server(store Store, sampler sampling.Sampler, opts ...Option) middleware.Middleware {
+	options := options{
+		drop: map[string]struct{}{"authorization": {}, "cookie": {}},
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			r := &Record{Time: time.Now(), Metadata: make(map[string]string)}
+			if info, ok := grpc.FromServerContext(ctx); ok {
+				r.Kind = transport.KindGRPC
+				r.Operation = info.FullMethod
+			} else if info, ok := http.FromServerContext(ctx); ok {
+				r.Kind = transport.KindHTTP
+				r.Operation = transport.Operation(ctx)
+				r.Method = info.Request.Method
+				r.URI = info.Request.URL.RequestURI()
+			} else {
+				return handler(ctx, req)
+			}
+			if h, ok := transport.RequestHeaderCarrier(ctx); ok {
+				for _, k := range h.Keys() {
+					r.Metadata[k] = h.Get(k)
+				}
+			}
+			if !sampler.Sample(ctx, r.Operation) {
+				return handler(ctx, req)
+			}
+			for k := range options.drop {
+				delete(r.Metadata, k)
+			}
+			if payload, err := marshal(req, options.redactor); err == nil {
+				r.Payload = payload
+			}
+			_ = store.Save(ctx, r)
+			return handler(ctx, req)
+		}
+	}
+}
+
+func marshal(req interface{}, redactor logging.Redactor) ([]byte, error) {
+	if m, ok := req.(proto.Message); ok {
+		if redactor != nil {
+			m = redactor(proto.Clone(m))
+		}
+		return protojson.Marshal(m)
+	}
+	return json.Marshal(req)
+}
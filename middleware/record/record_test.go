@@ -0,0 +1,48 @@
+package record
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/middleware/logging"
+	"github.com/go-kratos/kratos/v2/middleware/sampling"
+	"github.com/go-kratos/kratos/v2/transport/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestServer(t *testing.T) {
+	var buf bytes.Buffer
+	next := Server(NewWriterStore(&buf), sampling.NewRateSampler(1, nil),
+		WithRedactor(logging.RedactFields("fields.string_value")),
+	)(func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	})
+	req, err := structpb.NewStruct(map[string]interface{}{"name": "kratos", "password": "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := grpc.NewServerContext(context.Background(), grpc.ServerInfo{FullMethod: "/test.Test/Test"})
+	ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", "token", "x-tenant", "kratos"))
+	if _, err := next(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+	records, err := Read(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("expected 1 record got %d", len(records))
+	}
+	r := records[0]
+	if r.Operation != "/test.Test/Test" || r.Metadata["x-tenant"] != "kratos" {
+		t.Fatalf("unexpected record: %+v", r)
+	}
+	if _, ok := r.Metadata["authorization"]; ok {
+		t.Fatal("expected the authorization dropped")
+	}
+	if bytes.Contains(r.Payload, []byte("secret")) {
+		t.Fatalf("expected the password redacted got %s", r.Payload)
+	}
+}
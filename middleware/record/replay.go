@@ -0,0 +1,95 @@
+package record
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// Replay issues the recorded gRPC request via the connection, e.g. dialed by the kratos
+// gRPC client, and returns the reply. The message types are resolved from the global
+// registry, so the generated package of the service should be imported.
+func Replay(ctx context.Context, conn grpc.ClientConnInterface, r *Record) (proto.Message, error) {
+	parts := strings.Split(strings.TrimPrefix(r.Operation, "/"), "/")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("record: invalid gRPC operation: %s", r.Operation)
+	}
+	d, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(parts[0]))
+	if err != nil {
+		return nil, err
+	}
+	sd, ok := d.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("record: not a service: %s", parts[0])
+	}
+	md := sd.Methods().ByName(protoreflect.Name(parts[1]))
+	if md == nil {
+		return nil, fmt.Errorf("record: method not found: %s", r.Operation)
+	}
+	in, err := newMessage(md.Input())
+	if err != nil {
+		return nil, err
+	}
+	out, err := newMessage(md.Output())
+	if err != nil {
+		return nil, err
+	}
+	if len(r.Payload) > 0 {
+		if err := protojson.Unmarshal(r.Payload, in); err != nil {
+			return nil, err
+		}
+	}
+	kv := make([]string, 0, len(r.Metadata)*2)
+	for k, v := range r.Metadata {
+		if reserved(k) {
+			continue
+		}
+		kv = append(kv, k, v)
+	}
+	ctx = metadata.AppendToOutgoingContext(ctx, kv...)
+	if err := conn.Invoke(ctx, r.Operation, in, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ReplayHTTP issues the recorded HTTP request to the endpoint, e.g. http://127.0.0.1:8000.
+func ReplayHTTP(ctx context.Context, endpoint string, r *Record) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, r.Method, strings.TrimSuffix(endpoint, "/")+r.URI, bytes.NewReader(r.Payload))
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range r.Metadata {
+		req.Header.Set(k, v)
+	}
+	if len(r.Payload) > 0 {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return http.DefaultClient.Do(req)
+}
+
+func newMessage(d protoreflect.MessageDescriptor) (proto.Message, error) {
+	mt, err := protoregistry.GlobalTypes.FindMessageByName(d.FullName())
+	if err != nil {
+		return nil, err
+	}
+	return mt.New().Interface(), nil
+}
+
+// reserved reports whether the metadata is set by the gRPC transport.
+func reserved(key string) bool {
+	switch key {
+	case "content-type", "user-agent", "te":
+		return true
+	}
+	return strings.HasPrefix(key, ":") || strings.HasPrefix(key, "grpc-")
+}
@@ -0,0 +1,47 @@
+package record
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+var _ Store = (*WriterStore)(nil)
+
+// WriterStore is a store which writes the records as JSON lines, e.g. to a file.
+type WriterStore struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewWriterStore new a store writing the records to w.
+func NewWriterStore(w io.Writer) *WriterStore {
+	return &WriterStore{enc: json.NewEncoder(w)}
+}
+
+// Save writes the record as a JSON line.
+func (s *WriterStore) Save(ctx context.Context, r *Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.enc.Encode(r)
+}
+
+// Read reads the records written by the WriterStore.
+func Read(r io.Reader) ([]*Record, error) {
+	var records []*Record
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		rec := new(Record)
+		if err := json.Unmarshal(scanner.Bytes(), rec); err != nil {
+			return nil, err
+		}
+		records = append(records, rec)
+	}
+	return records, scanner.Err()
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/go-kratos/kratos/v2/errors"
 	"github.com/go-kratos/kratos/v2/log"
 	"github.com/go-kratos/kratos/v2/middleware"
+	"google.golang.org/grpc"
 )
 
 // HandlerFunc is recovery handler func.
@@ -35,31 +36,73 @@ func WithLogger(logger log.Logger) Option {
 	}
 }
 
-// Recovery is a server middleware that recovers from any panics.
-func Recovery(opts ...Option) middleware.Middleware {
-	options := options{
+func newOptions(opts ...Option) *options {
+	options := &options{
 		logger: log.DefaultLogger,
 		handler: func(ctx context.Context, req, err interface{}) error {
 			return errors.InternalServer("RECOVERY", fmt.Sprintf("panic triggered: %v", err))
 		},
 	}
 	for _, o := range opts {
-		o(&options)
+		o(options)
 	}
-	logger := log.NewHelper(options.logger)
+	return options
+}
+
+func (o *options) recover(ctx context.Context, req, rerr interface{}) error {
+	buf := make([]byte, 64<<10)
+	n := runtime.Stack(buf, false)
+	buf = buf[:n]
+	log.NewHelper(o.logger).Errorf("%v: %+v\n%s\n", rerr, req, buf)
+
+	return o.handler(ctx, req, rerr)
+}
+
+// Recovery is a server middleware that recovers from any panics.
+func Recovery(opts ...Option) middleware.Middleware {
+	options := newOptions(opts...)
 	return func(handler middleware.Handler) middleware.Handler {
 		return func(ctx context.Context, req interface{}) (reply interface{}, err error) {
 			defer func() {
 				if rerr := recover(); rerr != nil {
-					buf := make([]byte, 64<<10)
-					n := runtime.Stack(buf, false)
-					buf = buf[:n]
-					logger.Errorf("%v: %+v\n%s\n", rerr, req, buf)
-
-					err = options.handler(ctx, req, rerr)
+					err = options.recover(ctx, req, rerr)
 				}
 			}()
 			return handler(ctx, req)
 		}
 	}
 }
+
+// Stream is a stream server interceptor that recovers from the panics of the streaming
+// handlers, the panic is logged with the stack and ends the stream with the error of the
+// recovery handler, e.g. Internal, instead of crashing the server, the messages sent
+// before the panic are delivered to the client.
+func Stream(opts ...Option) grpc.StreamServerInterceptor {
+	options := newOptions(opts...)
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if rerr := recover(); rerr != nil {
+				err = options.recover(ss.Context(), info.FullMethod, rerr)
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
+
+// Go runs fn in a new goroutine that recovers from any panics, which is useful
+// for the goroutines spawned by handlers. The returned channel receives the
+// error returned by the recovery handler if fn panics, and is closed when fn returns.
+func Go(ctx context.Context, fn func(ctx context.Context), opts ...Option) <-chan error {
+	options := newOptions(opts...)
+	errc := make(chan error, 1)
+	go func() {
+		defer close(errc)
+		defer func() {
+			if rerr := recover(); rerr != nil {
+				errc <- options.recover(ctx, nil, rerr)
+			}
+		}()
+		fn(ctx)
+	}()
+	return errc
+}
@@ -19,3 +19,12 @@ func TestOnce(t *testing.T) {
 	_, e := next(context.Background(), "panic")
 	t.Logf("succ and reason is %v", e)
 }
+
+func TestGo(t *testing.T) {
+	errc := Go(context.Background(), func(ctx context.Context) {
+		panic("panic reason")
+	})
+	if err := <-errc; err == nil {
+		t.Error("expected the recovered error")
+	}
+}
@@ -0,0 +1,77 @@
+package recovery_test
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware/recovery"
+	transgrpc "github.com/go-kratos/kratos/v2/transport/grpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+var panicDesc = grpc.ServiceDesc{
+	ServiceName: "test.Panic",
+	HandlerType: (*interface{})(nil),
+	Streams: []grpc.StreamDesc{{
+		StreamName:    "Pull",
+		ServerStreams: true,
+		Handler: func(srv interface{}, stream grpc.ServerStream) error {
+			in := new(wrapperspb.Int32Value)
+			if err := stream.RecvMsg(in); err != nil {
+				return err
+			}
+			for i := int32(0); i < in.Value; i++ {
+				if err := stream.SendMsg(wrapperspb.Int32(i)); err != nil {
+					return err
+				}
+			}
+			panic("panic after sending")
+		},
+	}},
+}
+
+func TestStream(t *testing.T) {
+	srv := transgrpc.NewServer(transgrpc.Address("127.0.0.1:0"), transgrpc.StreamInterceptor(recovery.Stream()))
+	srv.RegisterService(&panicDesc, struct{}{})
+	e, err := srv.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	go srv.Start(context.Background())
+	defer srv.Stop(context.Background())
+
+	conn, err := transgrpc.DialInsecure(context.Background(), transgrpc.WithEndpoint(e.Host))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	stream, err := conn.NewStream(context.Background(), &panicDesc.Streams[0], "/test.Panic/Pull")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = stream.SendMsg(wrapperspb.Int32(2)); err != nil {
+		t.Fatal(err)
+	}
+	if err = stream.CloseSend(); err != nil {
+		t.Fatal(err)
+	}
+	var received int
+	for {
+		out := new(wrapperspb.Int32Value)
+		if err = stream.RecvMsg(out); err != nil {
+			break
+		}
+		received++
+	}
+	if received != 2 {
+		t.Fatalf("expected the messages before the panic got %d", received)
+	}
+	if err == io.EOF || status.Code(err) != codes.Internal || errors.Reason(err) != "RECOVERY" {
+		t.Fatalf("expected the internal error got %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+package requestid
+
+import (
+	"context"
+
+	"github.com/go-kratos/kratos/v2/id"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/go-kratos/kratos/v2/transport/grpc"
+	"github.com/go-kratos/kratos/v2/transport/http"
+	"google.golang.org/grpc/metadata"
+)
+
+const defaultHeader = "x-request-id"
+
+type requestIDKey struct{}
+
+// NewContext returns a new Context that carries the request ID.
+func NewContext(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// FromContext returns the request ID stored in ctx, if any.
+func FromContext(ctx context.Context) (requestID string, ok bool) {
+	requestID, ok = ctx.Value(requestIDKey{}).(string)
+	return
+}
+
+// RequestID returns a log Valuer of the request ID.
+func RequestID() log.Valuer {
+	return func(ctx context.Context) interface{} {
+		requestID, _ := FromContext(ctx)
+		return requestID
+	}
+}
+
+// Option is request ID option.
+type Option func(*options)
+
+type options struct {
+	header    string
+	generator id.Generator
+}
+
+// WithHeader with the request ID header or metadata key, the default is x-request-id.
+func WithHeader(key string) Option {
+	return func(o *options) {
+		o.header = key
+	}
+}
+
+// WithGenerator with the request ID generator, the default is the UUID generator,
+// which should be the same as the app ID generator.
+func WithGenerator(g id.Generator) Option {
+	return func(o *options) {
+		o.generator = g
+	}
+}
+
+func newOptions(opts ...Option) options {
+	options := options{
+		header:    defaultHeader,
+		generator: id.UUID(),
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+	return options
+}
+
+// Server is a server middleware which reads the request ID from the request,
+// or generates one if missing, and sets it to the context and the response header.
+func Server(opts ...Option) middleware.Middleware {
+	options := newOptions(opts...)
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			requestID := transport.RequestHeader(ctx, options.header)
+			if requestID == "" {
+				requestID = options.generator.Generate()
+			}
+			_ = transport.SetHeader(ctx, options.header, requestID)
+			return handler(NewContext(ctx, requestID), req)
+		}
+	}
+}
+
+// Client is a client middleware which propagates the request ID in the context,
+// or generates one if missing, to the outgoing request.
+func Client(opts ...Option) middleware.Middleware {
+	options := newOptions(opts...)
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			requestID, ok := FromContext(ctx)
+			if !ok {
+				requestID = options.generator.Generate()
+				ctx = NewContext(ctx, requestID)
+			}
+			if _, ok := grpc.FromClientContext(ctx); ok {
+				ctx = metadata.AppendToOutgoingContext(ctx, options.header, requestID)
+			} else if info, ok := http.FromClientContext(ctx); ok {
+				info.Request.Header.Set(options.header, requestID)
+			}
+			return handler(ctx, req)
+		}
+	}
+}
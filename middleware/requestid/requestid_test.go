@@ -0,0 +1,40 @@
+package requestid
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/id"
+	"github.com/go-kratos/kratos/v2/transport/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestRequestID(t *testing.T) {
+	gen := id.GeneratorFunc(func() string { return "kratos" })
+	var md metadata.MD
+	client := Client(WithGenerator(gen))(func(ctx context.Context, req interface{}) (interface{}, error) {
+		md, _ = metadata.FromOutgoingContext(ctx)
+		return nil, nil
+	})
+	ctx := grpc.NewClientContext(context.Background(), grpc.ClientInfo{FullMethod: "/test.Test/Test"})
+	if _, err := client(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+	if v := md.Get(defaultHeader); len(v) != 1 || v[0] != "kratos" {
+		t.Fatalf("expected kratos got %v", v)
+	}
+
+	var requestID string
+	server := Server()(func(ctx context.Context, req interface{}) (interface{}, error) {
+		requestID, _ = FromContext(ctx)
+		return nil, nil
+	})
+	ctx = metadata.NewIncomingContext(context.Background(), md)
+	ctx = grpc.NewServerContext(ctx, grpc.ServerInfo{FullMethod: "/test.Test/Test"})
+	if _, err := server(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+	if requestID != "kratos" {
+		t.Fatalf("expected kratos got %s", requestID)
+	}
+}
@@ -0,0 +1,72 @@
+package retry
+
+import (
+	"sync"
+
+	"github.com/go-kratos/kratos/v2/clock"
+)
+
+const buckets = 10
+
+type bucket struct {
+	second   int64
+	requests int
+	retries  int
+}
+
+// Budget is a retry budget which limits the ratio of the retries to the requests
+// over a sliding window of 10 seconds, so that the retries do not amplify an outage.
+type Budget struct {
+	mu          sync.Mutex
+	ratio       float64
+	minRequests int
+	buckets     [buckets]bucket
+	clock       clock.Clock
+}
+
+// NewBudget new a retry budget by the real clock.
+func NewBudget(ratio float64, minRequests int) *Budget {
+	return &Budget{
+		ratio:       ratio,
+		minRequests: minRequests,
+		clock:       clock.New(),
+	}
+}
+
+func (b *Budget) bucket() *bucket {
+	second := b.clock.Now().Unix()
+	bk := &b.buckets[second%buckets]
+	if bk.second != second {
+		*bk = bucket{second: second}
+	}
+	return bk
+}
+
+func (b *Budget) sum() (requests, retries int) {
+	second := b.clock.Now().Unix()
+	for _, bk := range b.buckets {
+		if second-bk.second < buckets {
+			requests += bk.requests
+			retries += bk.retries
+		}
+	}
+	return
+}
+
+func (b *Budget) request() {
+	b.mu.Lock()
+	b.bucket().requests++
+	b.mu.Unlock()
+}
+
+// retry reports whether a retry is allowed and records it.
+func (b *Budget) retry() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	requests, retries := b.sum()
+	if requests >= b.minRequests && float64(retries+1) > b.ratio*float64(requests) {
+		return false
+	}
+	b.bucket().retries++
+	return true
+}
@@ -0,0 +1,185 @@
+package retry
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/clock"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/go-kratos/kratos/v2/transport/grpc"
+	"github.com/go-kratos/kratos/v2/transport/http"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Option is retry option.
+type Option func(*options)
+
+type options struct {
+	attempts  int
+	backoff   time.Duration
+	retryable func(error) bool
+	budget    *Budget
+	// retries the idempotent requests only
+	idempotent bool
+	operations map[string]struct{}
+	clock      clock.Clock
+}
+
+// WithAttempts with the max attempts including the first one, the default is 3.
+func WithAttempts(n int) Option {
+	return func(o *options) {
+		o.attempts = n
+	}
+}
+
+// WithBackoff with the backoff between the attempts, the default is 100ms.
+// The Retry-After of the error, see errors.RetryAfter, takes precedence over it.
+func WithBackoff(d time.Duration) Option {
+	return func(o *options) {
+		o.backoff = d
+	}
+}
+
+// WithRetryable with the func which reports whether the error is retryable,
+// the default retries the Unavailable errors only.
+func WithRetryable(f func(error) bool) Option {
+	return func(o *options) {
+		o.retryable = f
+	}
+}
+
+// WithBudget with the retry budget, the retries are suppressed once the ratio of
+// the retries to the requests over the last 10 seconds exceeds the ratio,
+// and the requests fewer than the minRequests are always allowed to retry.
+// The budget can be shared by the clients with WithSharedBudget.
+func WithBudget(ratio float64, minRequests int) Option {
+	return func(o *options) {
+		// the clock is set by the client
+		o.budget = &Budget{ratio: ratio, minRequests: minRequests}
+	}
+}
+
+// WithSharedBudget with the retry budget shared by the clients, e.g. per service.
+func WithSharedBudget(b *Budget) Option {
+	return func(o *options) {
+		o.budget = b
+	}
+}
+
+// WithIdempotentOnly with retrying only the idempotent requests by the HTTP method,
+// e.g. GET and DELETE, see the Idempotent of the transport. The gRPC requests
+// and the HTTP POST requests are not retried.
+func WithIdempotentOnly() Option {
+	return func(o *options) {
+		o.idempotent = true
+	}
+}
+
+// WithIdempotentOperations with the idempotent operations, the full method for gRPC and
+// the path template for HTTP, e.g. /helloworld.Greeter/SayHello and /v1/users/{id}.
+// Only the idempotent operations are retried once it is set, and with WithIdempotentOnly
+// the requests idempotent by the HTTP method are retried as well.
+func WithIdempotentOperations(operations ...string) Option {
+	return func(o *options) {
+		if o.operations == nil {
+			o.operations = make(map[string]struct{}, len(operations))
+		}
+		for _, op := range operations {
+			o.operations[op] = struct{}{}
+		}
+	}
+}
+
+// idempotentRequest reports whether the request is idempotent by the operation or the HTTP method.
+func (o *options) idempotentRequest(ctx context.Context) bool {
+	if _, ok := o.operations[operation(ctx)]; ok {
+		return true
+	}
+	if o.idempotent {
+		tr, ok := transport.FromContext(ctx)
+		return ok && tr.Idempotent()
+	}
+	return false
+}
+
+func operation(ctx context.Context) string {
+	if info, ok := grpc.FromClientContext(ctx); ok {
+		return info.FullMethod
+	}
+	if info, ok := http.FromClientContext(ctx); ok {
+		if info.PathPattern != "" {
+			return info.PathPattern
+		}
+		if info.Request != nil {
+			return info.Request.URL.Path
+		}
+	}
+	return ""
+}
+
+// WithClock with the clock of the backoff, the Retry-After and the budget of WithBudget,
+// the default is the real clock.
+func WithClock(c clock.Clock) Option {
+	return func(o *options) {
+		o.clock = c
+	}
+}
+
+func defaultRetryable(err error) bool {
+	return status.Code(err) == codes.Unavailable
+}
+
+// Client is a client middleware which retries the failed requests.
+func Client(opts ...Option) middleware.Middleware {
+	options := options{
+		attempts:  3,
+		backoff:   100 * time.Millisecond,
+		retryable: defaultRetryable,
+		clock:     clock.New(),
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+	if options.budget != nil && options.budget.clock == nil {
+		options.budget.clock = options.clock
+	}
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (reply interface{}, err error) {
+			if (options.idempotent || options.operations != nil) && !options.idempotentRequest(ctx) {
+				return handler(ctx, req)
+			}
+			if options.budget != nil {
+				options.budget.request()
+			}
+			for i := 0; i < options.attempts; i++ {
+				if i > 0 {
+					if options.budget != nil && !options.budget.retry() {
+						// returns the original error once the budget is exhausted
+						return reply, err
+					}
+					backoff := options.backoff
+					if d, ok := errors.RetryAfter(err); ok {
+						// honors the delay asked by the server instead of the backoff,
+						// and gives up if it could not be retried before the deadline.
+						if deadline, ok := ctx.Deadline(); ok && deadline.Sub(options.clock.Now()) < d {
+							return reply, err
+						}
+						backoff = d
+					}
+					select {
+					case <-ctx.Done():
+						return nil, ctx.Err()
+					case <-options.clock.After(backoff):
+					}
+				}
+				if reply, err = handler(ctx, req); err == nil || !options.retryable(err) {
+					return reply, err
+				}
+			}
+			return reply, err
+		}
+	}
+}
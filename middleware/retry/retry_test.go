@@ -0,0 +1,165 @@
+package retry
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/clock"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/go-kratos/kratos/v2/transport/grpc"
+	"github.com/go-kratos/kratos/v2/transport/http"
+)
+
+func TestRetry(t *testing.T) {
+	var calls int
+	next := Client(WithBackoff(0))(func(ctx context.Context, req interface{}) (interface{}, error) {
+		calls++
+		if calls < 3 {
+			return nil, errors.ServiceUnavailable("UNAVAILABLE", "unavailable")
+		}
+		return "ok", nil
+	})
+	reply, err := next(context.Background(), nil)
+	if err != nil || reply != "ok" || calls != 3 {
+		t.Fatalf("unexpected reply %v %v after %d calls", reply, err, calls)
+	}
+}
+
+func TestBudget(t *testing.T) {
+	var calls int
+	next := Client(WithBackoff(time.Millisecond), WithBudget(0.2, 10))(func(ctx context.Context, req interface{}) (interface{}, error) {
+		calls++
+		return nil, errors.ServiceUnavailable("UNAVAILABLE", "unavailable")
+	})
+	// the backend is fully down
+	const requests = 100
+	for i := 0; i < requests; i++ {
+		if _, err := next(context.Background(), nil); !errors.IsServiceUnavailable(err) {
+			t.Fatalf("expected the original error got %v", err)
+		}
+	}
+	retries := calls - requests
+	if retries > requests*2/10+10 {
+		t.Fatalf("expected the retries limited by the budget got %d", retries)
+	}
+	if retries == 0 {
+		t.Fatal("expected some retries within the budget")
+	}
+}
+
+func TestBudgetClock(t *testing.T) {
+	c := clock.NewFake()
+	var calls int
+	next := Client(WithBackoff(0), WithBudget(0.5, 2), WithClock(c))(func(ctx context.Context, req interface{}) (interface{}, error) {
+		calls++
+		return nil, errors.ServiceUnavailable("UNAVAILABLE", "unavailable")
+	})
+	// the first request is retried below the min requests, the second exhausts the budget
+	_, _ = next(context.Background(), nil)
+	_, _ = next(context.Background(), nil)
+	if calls != 4 {
+		t.Fatalf("expected 4 calls got %d", calls)
+	}
+	// the window slides past the requests
+	c.Advance(10 * time.Second)
+	_, _ = next(context.Background(), nil)
+	if calls != 7 {
+		t.Fatalf("expected the budget window kept by the clock got %d calls", calls)
+	}
+}
+
+func TestRetryAfterClock(t *testing.T) {
+	c := clock.NewFake()
+	var calls int
+	next := Client(WithClock(c))(func(ctx context.Context, req interface{}) (interface{}, error) {
+		calls++
+		return nil, errors.ServiceUnavailable("UNAVAILABLE", "unavailable").WithRetryAfter(time.Second)
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	deadline, _ := ctx.Deadline()
+	c.Set(deadline.Add(-500 * time.Millisecond))
+	_, _ = next(ctx, nil)
+	if calls != 1 {
+		t.Fatalf("expected no retry after the deadline got %d calls", calls)
+	}
+}
+
+func TestIdempotentOnly(t *testing.T) {
+	tests := []struct {
+		tr    transport.Transport
+		calls int
+	}{
+		{transport.Transport{Kind: transport.KindHTTP, Method: "GET"}, 3},
+		{transport.Transport{Kind: transport.KindHTTP, Method: "POST"}, 1},
+		{transport.Transport{Kind: transport.KindGRPC}, 1},
+	}
+	for _, test := range tests {
+		var calls int
+		next := Client(WithBackoff(0), WithIdempotentOnly())(func(ctx context.Context, req interface{}) (interface{}, error) {
+			calls++
+			return nil, errors.ServiceUnavailable("UNAVAILABLE", "unavailable")
+		})
+		_, _ = next(transport.NewContext(context.Background(), test.tr), nil)
+		if calls != test.calls {
+			t.Errorf("%s %s: expected %d calls got %d", test.tr.Kind, test.tr.Method, test.calls, calls)
+		}
+	}
+}
+
+func TestIdempotentOperations(t *testing.T) {
+	tests := []struct {
+		name  string
+		ctx   context.Context
+		calls int
+	}{
+		{"grpc idempotent", grpc.NewClientContext(context.Background(), grpc.ClientInfo{FullMethod: "/user.User/GetUser"}), 3},
+		{"grpc not idempotent", grpc.NewClientContext(context.Background(), grpc.ClientInfo{FullMethod: "/user.User/CreateUser"}), 1},
+		{"http idempotent", http.NewClientContext(context.Background(), http.ClientInfo{PathPattern: "/v1/users/{id}"}), 3},
+		{"http post not idempotent", transport.NewContext(
+			http.NewClientContext(context.Background(), http.ClientInfo{PathPattern: "/v1/users"}),
+			transport.Transport{Kind: transport.KindHTTP, Method: "POST"},
+		), 1},
+	}
+	for _, test := range tests {
+		var calls int
+		next := Client(WithBackoff(0), WithIdempotentOperations("/user.User/GetUser", "/v1/users/{id}"))(func(ctx context.Context, req interface{}) (interface{}, error) {
+			calls++
+			return nil, errors.ServiceUnavailable("UNAVAILABLE", "unavailable")
+		})
+		_, _ = next(test.ctx, nil)
+		if calls != test.calls {
+			t.Errorf("%s: expected %d calls got %d", test.name, test.calls, calls)
+		}
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	var calls int
+	next := Client(WithBackoff(time.Hour))(func(ctx context.Context, req interface{}) (interface{}, error) {
+		calls++
+		if calls < 2 {
+			return nil, errors.ServiceUnavailable("UNAVAILABLE", "unavailable").WithRetryAfter(time.Millisecond)
+		}
+		return "ok", nil
+	})
+	// the backoff of an hour is replaced by the retry after
+	reply, err := next(context.Background(), nil)
+	if err != nil || reply != "ok" || calls != 2 {
+		t.Fatalf("unexpected reply %v %v after %d calls", reply, err, calls)
+	}
+
+	calls = 0
+	next = Client(WithBackoff(0))(func(ctx context.Context, req interface{}) (interface{}, error) {
+		calls++
+		return nil, errors.ServiceUnavailable("UNAVAILABLE", "unavailable").WithRetryAfter(time.Hour)
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	// gives up at once as the retry after exceeds the deadline
+	if _, err := next(ctx, nil); !errors.IsServiceUnavailable(err) || calls != 1 {
+		t.Fatalf("unexpected error %v after %d calls", err, calls)
+	}
+}
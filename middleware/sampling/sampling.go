@@ -0,0 +1,103 @@
+package sampling
+
+import (
+	"context"
+	"math/rand"
+
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/go-kratos/kratos/v2/transport/grpc"
+	"github.com/go-kratos/kratos/v2/transport/http"
+	"google.golang.org/grpc/metadata"
+)
+
+const header = "x-sampled"
+
+// Sampler makes the head-based sampling decision of the operation,
+// the operation is the full method for gRPC and the path template for HTTP.
+type Sampler interface {
+	Sample(ctx context.Context, operation string) bool
+}
+
+type rateSampler struct {
+	rate      float64
+	overrides map[string]float64
+}
+
+// NewRateSampler returns a sampler which samples the requests by the rate in [0, 1],
+// the overrides are the rates by operation.
+func NewRateSampler(rate float64, overrides map[string]float64) Sampler {
+	return &rateSampler{rate: rate, overrides: overrides}
+}
+
+func (s *rateSampler) Sample(ctx context.Context, operation string) bool {
+	rate := s.rate
+	if r, ok := s.overrides[operation]; ok {
+		rate = r
+	}
+	return rand.Float64() < rate
+}
+
+type sampledKey struct{}
+
+// NewContext returns a new Context that carries the sampling decision.
+func NewContext(ctx context.Context, sampled bool) context.Context {
+	return context.WithValue(ctx, sampledKey{}, sampled)
+}
+
+// FromContext returns the sampling decision stored in ctx, if any,
+// which can be used by the tracer and the logger to keep the whole trace consistent.
+func FromContext(ctx context.Context) (sampled bool, ok bool) {
+	sampled, ok = ctx.Value(sampledKey{}).(bool)
+	return
+}
+
+// Server is a server middleware which honors the sampling decision of the upstream,
+// or makes the decision by the sampler if there is none, and stores it in the context.
+func Server(sampler Sampler) middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			decision := transport.RequestHeader(ctx, header)
+			var sampled bool
+			switch decision {
+			case "1":
+				sampled = true
+			case "0":
+				sampled = false
+			default:
+				sampled = sampler.Sample(ctx, transport.Operation(ctx))
+			}
+			return handler(NewContext(ctx, sampled), req)
+		}
+	}
+}
+
+// Client is a client middleware which propagates the sampling decision in the context
+// to the downstream, or makes the decision by the sampler if there is none.
+func Client(sampler Sampler) middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			var operation string
+			if info, ok := grpc.FromClientContext(ctx); ok {
+				operation = info.FullMethod
+			} else if info, ok := http.FromClientContext(ctx); ok {
+				operation = info.PathPattern
+			}
+			sampled, ok := FromContext(ctx)
+			if !ok {
+				sampled = sampler.Sample(ctx, operation)
+				ctx = NewContext(ctx, sampled)
+			}
+			decision := "0"
+			if sampled {
+				decision = "1"
+			}
+			if _, ok := grpc.FromClientContext(ctx); ok {
+				ctx = metadata.AppendToOutgoingContext(ctx, header, decision)
+			} else if info, ok := http.FromClientContext(ctx); ok {
+				info.Request.Header.Set(header, decision)
+			}
+			return handler(ctx, req)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package sampling
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/transport/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestSampling(t *testing.T) {
+	sampler := NewRateSampler(0, map[string]float64{"/test.Test/Sampled": 1})
+
+	var md metadata.MD
+	client := Client(sampler)(func(ctx context.Context, req interface{}) (interface{}, error) {
+		md, _ = metadata.FromOutgoingContext(ctx)
+		return nil, nil
+	})
+	ctx := grpc.NewClientContext(context.Background(), grpc.ClientInfo{FullMethod: "/test.Test/Sampled"})
+	if _, err := client(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+	if v := md.Get(header); len(v) != 1 || v[0] != "1" {
+		t.Fatalf("expected sampled got %v", v)
+	}
+
+	// the downstream honors the decision even if its sampler would not sample
+	var sampled bool
+	server := Server(NewRateSampler(0, nil))(func(ctx context.Context, req interface{}) (interface{}, error) {
+		sampled, _ = FromContext(ctx)
+		return nil, nil
+	})
+	ctx = metadata.NewIncomingContext(context.Background(), md)
+	ctx = grpc.NewServerContext(ctx, grpc.ServerInfo{FullMethod: "/test.Test/Other"})
+	if _, err := server(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !sampled {
+		t.Fatal("expected sampled")
+	}
+}
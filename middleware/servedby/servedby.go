@@ -0,0 +1,47 @@
+// Package servedby adds the ID of the application instance which served the request
+// to the response, it is not installed by default to avoid leaking the internals,
+// enable it only for the internal services.
+package servedby
+
+import (
+	"context"
+
+	"github.com/go-kratos/kratos/v2"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// Option is served-by option.
+type Option func(*options)
+
+type options struct {
+	key string
+}
+
+// WithKey with the header key of the instance ID, default is X-Served-By.
+func WithKey(key string) Option {
+	return func(o *options) {
+		o.key = key
+	}
+}
+
+// Server is a server middleware which sets the instance ID from kratos.FromContext
+// to the X-Served-By header of HTTP or the trailer of gRPC.
+func Server(opts ...Option) middleware.Middleware {
+	o := options{key: "X-Served-By"}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			if app, ok := kratos.FromContext(ctx); ok && app.ID != "" {
+				if tr, ok := transport.FromContext(ctx); ok && tr.Kind == transport.KindGRPC {
+					_ = transport.SetTrailer(ctx, o.key, app.ID)
+				} else {
+					_ = transport.SetHeader(ctx, o.key, app.ID)
+				}
+			}
+			return handler(ctx, req)
+		}
+	}
+}
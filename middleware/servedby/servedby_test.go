@@ -0,0 +1,50 @@
+package servedby
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+type testHeader struct {
+	header  map[string]string
+	trailer map[string]string
+}
+
+func (h *testHeader) SetHeader(key, value string) error {
+	h.header[key] = value
+	return nil
+}
+
+func (h *testHeader) SetTrailer(key, value string) error {
+	h.trailer[key] = value
+	return nil
+}
+
+func TestServer(t *testing.T) {
+	next := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "reply", nil
+	}
+	tests := []struct {
+		kind    transport.Kind
+		header  string
+		trailer string
+	}{
+		{transport.KindHTTP, "instance-1", ""},
+		{transport.KindGRPC, "", "instance-1"},
+	}
+	for _, test := range tests {
+		h := &testHeader{header: map[string]string{}, trailer: map[string]string{}}
+		ctx := kratos.NewContext(context.Background(), kratos.AppInfo{ID: "instance-1"})
+		ctx = transport.NewContext(ctx, transport.Transport{Kind: test.kind})
+		ctx = transport.NewHeaderContext(ctx, h)
+		if _, err := Server()(next)(ctx, "req"); err != nil {
+			t.Fatal(err)
+		}
+		if h.header["X-Served-By"] != test.header || h.trailer["X-Served-By"] != test.trailer {
+			t.Errorf("%s: unexpected header %v and trailer %v", test.kind, h.header, h.trailer)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package signature
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	transhttp "github.com/go-kratos/kratos/v2/transport/http"
+)
+
+const defaultHeader = "X-Signature-256"
+
+type verifiedKey struct{}
+
+// Verified reports whether the signature of the request body is verified by the Server filter.
+func Verified(ctx context.Context) bool {
+	ok, _ := ctx.Value(verifiedKey{}).(bool)
+	return ok
+}
+
+// Option is signature option.
+type Option func(*options)
+
+type options struct {
+	header    string
+	prefix    string
+	timestamp string
+	hash      func() hash.Hash
+}
+
+// WithHeader with the header of the signature, the default is X-Signature-256,
+// e.g. X-Hub-Signature-256 for GitHub.
+func WithHeader(key string) Option {
+	return func(o *options) {
+		o.header = key
+	}
+}
+
+// WithPrefix with the prefix of the hex encoded signature, e.g. sha256= for GitHub.
+func WithPrefix(prefix string) Option {
+	return func(o *options) {
+		o.prefix = prefix
+	}
+}
+
+// WithTimestamp with the header of the request timestamp, e.g. X-Timestamp, which is signed
+// along with the body as the timestamp, a dot and the body, the requests without it are rejected.
+// Without it only the body is signed, so a captured request can be replayed with a rewritten
+// timestamp, the skew middleware checks the same header to reject the replayed requests.
+func WithTimestamp(key string) Option {
+	return func(o *options) {
+		o.timestamp = key
+	}
+}
+
+// WithHash with the hash of the HMAC, the default is SHA-256.
+func WithHash(h func() hash.Hash) Option {
+	return func(o *options) {
+		o.hash = h
+	}
+}
+
+// Server is a server filter of the HTTP transport which verifies the HMAC signature of
+// the raw request body with the secrets, e.g. of the webhooks, and rejects the requests
+// with 401 if it is missing or does not match. The body is buffered and reset for the
+// decoding, so the filter should be installed after maxbody to bound the buffer. Several
+// secrets can be configured while rotating them, the signature matching any is accepted.
+func Server(secrets [][]byte, opts ...Option) transhttp.FilterFunc {
+	options := options{
+		header: defaultHeader,
+		hash:   sha256.New,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			signature := r.Header.Get(options.header)
+			if signature == "" || !strings.HasPrefix(signature, options.prefix) {
+				transhttp.DefaultErrorEncoder(w, r, errors.Unauthorized("MISSING_SIGNATURE", "missing request signature"))
+				return
+			}
+			sum, err := hex.DecodeString(strings.TrimPrefix(signature, options.prefix))
+			if err != nil {
+				transhttp.DefaultErrorEncoder(w, r, errors.Unauthorized("INVALID_SIGNATURE", "invalid request signature"))
+				return
+			}
+			var timestamp string
+			if options.timestamp != "" {
+				if timestamp = r.Header.Get(options.timestamp); timestamp == "" {
+					transhttp.DefaultErrorEncoder(w, r, errors.Unauthorized("MISSING_TIMESTAMP", "missing request timestamp"))
+					return
+				}
+			}
+			var body []byte
+			if r.Body != nil {
+				if body, err = ioutil.ReadAll(r.Body); err != nil {
+					se := new(errors.Error)
+					if !errors.As(err, &se) {
+						se = errors.BadRequest("BODY_READ", err.Error())
+					}
+					transhttp.DefaultErrorEncoder(w, r, se)
+					return
+				}
+				r.Body.Close()
+			}
+			if !options.verify(timestamp, body, sum, secrets) {
+				transhttp.DefaultErrorEncoder(w, r, errors.Unauthorized("INVALID_SIGNATURE", "invalid request signature"))
+				return
+			}
+			r.Body = ioutil.NopCloser(bytes.NewReader(body))
+			r.GetBody = func() (io.ReadCloser, error) {
+				return ioutil.NopCloser(bytes.NewReader(body)), nil
+			}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), verifiedKey{}, true)))
+		})
+	}
+}
+
+func (o *options) verify(timestamp string, body, sum []byte, secrets [][]byte) bool {
+	for _, secret := range secrets {
+		mac := hmac.New(o.hash, secret)
+		if o.timestamp != "" {
+			mac.Write([]byte(timestamp + "."))
+		}
+		mac.Write(body)
+		if hmac.Equal(mac.Sum(nil), sum) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+package signature
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func sign(secret, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestServer(t *testing.T) {
+	var (
+		verified bool
+		read     string
+	)
+	h := Server([][]byte{[]byte("new"), []byte("old")}, WithHeader("X-Hub-Signature-256"), WithPrefix("sha256="))(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			verified = Verified(r.Context())
+			b, _ := ioutil.ReadAll(r.Body)
+			read = string(b)
+		}))
+	body := `{"action":"opened"}`
+	tests := []struct {
+		name      string
+		signature string
+		code      int
+	}{
+		{"new secret", sign("new", body), http.StatusOK},
+		{"rotated secret", sign("old", body), http.StatusOK},
+		{"wrong secret", sign("other", body), http.StatusUnauthorized},
+		{"tampered body", sign("new", body+" "), http.StatusUnauthorized},
+		{"missing", "", http.StatusUnauthorized},
+		{"malformed", "sha256=zz", http.StatusUnauthorized},
+	}
+	for _, test := range tests {
+		verified, read = false, ""
+		req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(body))
+		if test.signature != "" {
+			req.Header.Set("X-Hub-Signature-256", test.signature)
+		}
+		res := httptest.NewRecorder()
+		h.ServeHTTP(res, req)
+		if res.Code != test.code {
+			t.Errorf("%s: expected %d got %d", test.name, test.code, res.Code)
+		}
+		if test.code == http.StatusOK && (!verified || read != body) {
+			t.Errorf("%s: expected the verified body %q got %q (verified %v)", test.name, body, read, verified)
+		}
+	}
+}
+
+func TestServerTimestamp(t *testing.T) {
+	h := Server([][]byte{[]byte("secret")}, WithTimestamp("X-Timestamp"))(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	body := `{"action":"opened"}`
+	signed := strings.TrimPrefix(sign("secret", "1700000000."+body), "sha256=")
+	tests := []struct {
+		name      string
+		timestamp string
+		signature string
+		code      int
+	}{
+		{"signed timestamp", "1700000000", signed, http.StatusOK},
+		{"rewritten timestamp", "1700000300", signed, http.StatusUnauthorized},
+		{"missing timestamp", "", signed, http.StatusUnauthorized},
+		{"body only", "1700000000", strings.TrimPrefix(sign("secret", body), "sha256="), http.StatusUnauthorized},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(body))
+		req.Header.Set(defaultHeader, test.signature)
+		if test.timestamp != "" {
+			req.Header.Set("X-Timestamp", test.timestamp)
+		}
+		res := httptest.NewRecorder()
+		h.ServeHTTP(res, req)
+		if res.Code != test.code {
+			t.Errorf("%s: expected %d got %d", test.name, test.code, res.Code)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package singleflight
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	ic "github.com/go-kratos/kratos/v2/internal/context"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport/grpc"
+	"github.com/go-kratos/kratos/v2/transport/http"
+	"golang.org/x/sync/singleflight"
+)
+
+// KeyFunc returns the key of the request, the requests are not
+// deduplicated if it returns false, e.g. for write operations.
+type KeyFunc func(ctx context.Context, req interface{}) (string, bool)
+
+// Option is singleflight option.
+type Option func(*options)
+
+type options struct {
+	shareErrors bool
+	timeout     time.Duration
+}
+
+// WithShareErrors with sharing the error results, by default the waiting
+// requests execute the handler by themselves if the shared execution fails.
+func WithShareErrors(share bool) Option {
+	return func(o *options) {
+		o.shareErrors = share
+	}
+}
+
+// WithTimeout with the timeout of the shared execution, by default it is bounded by
+// the deadline of the request which starts it, e.g. the timeout of the server.
+func WithTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.timeout = d
+	}
+}
+
+// Server is a server middleware which collapses the concurrent in-flight
+// requests with the same operation and key into a single handler execution.
+// The shared execution is not canceled with the request which starts it, and
+// its context errors are never shared, the waiting requests execute the handler
+// by themselves instead.
+func Server(keyFunc KeyFunc, opts ...Option) middleware.Middleware {
+	options := options{}
+	for _, o := range opts {
+		o(&options)
+	}
+	var g singleflight.Group
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			key, ok := keyFunc(ctx, req)
+			if !ok {
+				return handler(ctx, req)
+			}
+			if info, ok := grpc.FromServerContext(ctx); ok {
+				key = info.FullMethod + "/" + key
+			} else if info, ok := http.FromServerContext(ctx); ok {
+				key = info.Request.Method + " " + info.Request.URL.Path + "/" + key
+			}
+			var executed bool
+			ch := g.DoChan(key, func() (interface{}, error) {
+				executed = true
+				sctx, cancel := options.shared(ctx)
+				defer cancel()
+				return handler(sctx, req)
+			})
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case res := <-ch:
+				if res.Err != nil && !executed && (!options.shareErrors || contextError(res.Err)) {
+					return handler(ctx, req)
+				}
+				return res.Val, res.Err
+			}
+		}
+	}
+}
+
+// shared returns the context of the shared execution, which is detached from the
+// cancellation of the request and bounded by the timeout or the request deadline.
+func (o *options) shared(ctx context.Context) (context.Context, context.CancelFunc) {
+	sctx := ic.Detach(ctx)
+	if o.timeout > 0 {
+		return context.WithTimeout(sctx, o.timeout)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		return context.WithDeadline(sctx, deadline)
+	}
+	return context.WithCancel(sctx)
+}
+
+func contextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
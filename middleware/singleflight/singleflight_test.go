@@ -0,0 +1,88 @@
+package singleflight
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestServer(t *testing.T) {
+	var calls int32
+	next := func(ctx context.Context, req interface{}) (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(100 * time.Millisecond)
+		return req, nil
+	}
+	keyFunc := func(ctx context.Context, req interface{}) (string, bool) {
+		return req.(string), true
+	}
+	h := Server(keyFunc)(next)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			reply, err := h(context.Background(), "key")
+			if err != nil || reply != "key" {
+				t.Errorf("unexpected reply %v error %v", reply, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if calls != 1 {
+		t.Fatalf("expected 1 call got %d", calls)
+	}
+}
+
+func TestServerLeaderCanceled(t *testing.T) {
+	var (
+		calls   int32
+		started = make(chan struct{})
+		release = make(chan struct{})
+	)
+	next := func(ctx context.Context, req interface{}) (interface{}, error) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			close(started)
+		}
+		<-release
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return req, nil
+	}
+	keyFunc := func(ctx context.Context, req interface{}) (string, bool) {
+		return req.(string), true
+	}
+	h := Server(keyFunc, WithShareErrors(true))(next)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	leader := make(chan error, 1)
+	go func() {
+		_, err := h(ctx, "key")
+		leader <- err
+	}()
+	<-started
+	follower := make(chan error, 1)
+	go func() {
+		reply, err := h(context.Background(), "key")
+		if err == nil && reply != "key" {
+			t.Errorf("unexpected reply %v", reply)
+		}
+		follower <- err
+	}()
+	// lets the follower wait for the shared execution
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	if err := <-leader; err != context.Canceled {
+		t.Fatalf("expected the leader canceled got %v", err)
+	}
+	close(release)
+	if err := <-follower; err != nil {
+		t.Fatalf("expected the follower served by the shared execution got %v", err)
+	}
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Fatalf("expected 1 call got %d", n)
+	}
+}
@@ -0,0 +1,102 @@
+package skew
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/clock"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// Option is skew option.
+type Option func(*options)
+
+type options struct {
+	key       string
+	window    time.Duration
+	tolerance time.Duration
+	clock     clock.Clock
+}
+
+// WithHeader with the header or metadata key of the request timestamp, the default is x-timestamp.
+func WithHeader(key string) Option {
+	return func(o *options) {
+		o.key = key
+	}
+}
+
+// WithWindow with the max skew between the request timestamp and the server time, the default is 5 minutes.
+func WithWindow(d time.Duration) Option {
+	return func(o *options) {
+		o.window = d
+	}
+}
+
+// WithTolerance with the tolerance of the transit time, which is added to the window
+// of the past timestamps, the default is 1 second.
+func WithTolerance(d time.Duration) Option {
+	return func(o *options) {
+		o.tolerance = d
+	}
+}
+
+// WithClock with the clock of the server time, the default is the real clock.
+func WithClock(c clock.Clock) Option {
+	return func(o *options) {
+		o.clock = c
+	}
+}
+
+// Server is a server middleware which rejects the requests without a timestamp, or with
+// a timestamp out of the window from the server time, e.g. the replayed requests and the
+// webhooks, with Unauthorized. The timestamp is the unix seconds, the unix milliseconds or RFC 3339.
+// The timestamp alone does not prevent the replay, as it can be rewritten unless it is signed,
+// e.g. by the signature filter with the WithTimestamp of the same header.
+func Server(opts ...Option) middleware.Middleware {
+	o := options{
+		key:       "x-timestamp",
+		window:    5 * time.Minute,
+		tolerance: time.Second,
+		clock:     clock.New(),
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			v := transport.RequestHeader(ctx, o.key)
+			if v == "" {
+				return nil, errors.Unauthorized("MISSING_TIMESTAMP", "missing the request timestamp")
+			}
+			ts, err := parseTimestamp(v)
+			if err != nil {
+				return nil, errors.Unauthorized("INVALID_TIMESTAMP", err.Error())
+			}
+			now := o.clock.Now()
+			if now.Sub(ts) > o.window+o.tolerance || ts.Sub(now) > o.window {
+				return nil, errors.Unauthorized("TIMESTAMP_SKEWED",
+					fmt.Sprintf("the request timestamp is out of the %s window", o.window))
+			}
+			return handler(ctx, req)
+		}
+	}
+}
+
+func parseTimestamp(v string) (time.Time, error) {
+	if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+		// the unix milliseconds, which are over 1e12 since 2001
+		if n > 1e12 {
+			return time.Unix(0, n*int64(time.Millisecond)), nil
+		}
+		return time.Unix(n, 0), nil
+	}
+	ts, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid request timestamp: %s", v)
+	}
+	return ts, nil
+}
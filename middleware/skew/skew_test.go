@@ -0,0 +1,47 @@
+package skew
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/clock"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/transport/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestServer(t *testing.T) {
+	c := clock.NewFake()
+	c.Set(time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC))
+	now := c.Now()
+	next := Server(WithWindow(time.Minute), WithClock(c))(func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	})
+	tests := []struct {
+		timestamp string
+		reason    string
+	}{
+		{"", "MISSING_TIMESTAMP"},
+		{"yesterday", "INVALID_TIMESTAMP"},
+		{strconv.FormatInt(now.Unix(), 10), ""},
+		{now.Add(-30 * time.Second).Format(time.RFC3339), ""},
+		{strconv.FormatInt(now.Add(-61*time.Second).UnixNano()/int64(time.Millisecond), 10), ""},
+		{strconv.FormatInt(now.Add(-2*time.Minute).Unix(), 10), "TIMESTAMP_SKEWED"},
+		{strconv.FormatInt(now.Add(2*time.Minute).Unix(), 10), "TIMESTAMP_SKEWED"},
+	}
+	for _, test := range tests {
+		ctx := grpc.NewServerContext(context.Background(), grpc.ServerInfo{FullMethod: "/webhook.Webhook/Notify"})
+		if test.timestamp != "" {
+			ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("x-timestamp", test.timestamp))
+		}
+		_, err := next(ctx, nil)
+		if test.reason == "" && err != nil {
+			t.Errorf("%s: unexpected error %v", test.timestamp, err)
+		}
+		if test.reason != "" && (!errors.IsUnauthorized(err) || errors.Reason(err) != test.reason) {
+			t.Errorf("%s: expected %s got %v", test.timestamp, test.reason, err)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package sli
+
+import (
+	"math"
+	"sort"
+)
+
+type centroid struct {
+	mean   float64
+	weight float64
+}
+
+// digest is a merging t-digest which estimates the quantiles of a stream
+// in the memory bounded by the compression, the centroids near the tails
+// are kept smaller so that the high percentiles are accurate.
+type digest struct {
+	compression float64
+	centroids   []centroid
+	buffer      []centroid
+	count       float64
+	min         float64
+	max         float64
+}
+
+func newDigest(compression float64) *digest {
+	return &digest{
+		compression: compression,
+		buffer:      make([]centroid, 0, int(compression)*4),
+		min:         math.Inf(1),
+		max:         math.Inf(-1),
+	}
+}
+
+// add adds the value, and reports whether the buffer is merged into the centroids.
+func (d *digest) add(x float64) bool {
+	d.buffer = append(d.buffer, centroid{mean: x, weight: 1})
+	d.count++
+	if x < d.min {
+		d.min = x
+	}
+	if x > d.max {
+		d.max = x
+	}
+	if len(d.buffer) < cap(d.buffer) {
+		return false
+	}
+	d.merge()
+	return true
+}
+
+func (d *digest) merge() {
+	if len(d.buffer) == 0 {
+		return
+	}
+	all := append(d.centroids, d.buffer...)
+	sort.Slice(all, func(i, j int) bool { return all[i].mean < all[j].mean })
+	merged := make([]centroid, 0, len(d.centroids)+1)
+	cur := all[0]
+	var soFar float64
+	kLeft := d.scale(0)
+	for _, next := range all[1:] {
+		// a centroid spans one unit of the scale at most
+		if d.scale((soFar+cur.weight+next.weight)/d.count)-kLeft <= 1 {
+			cur.mean += (next.mean - cur.mean) * next.weight / (cur.weight + next.weight)
+			cur.weight += next.weight
+			continue
+		}
+		soFar += cur.weight
+		kLeft = d.scale(soFar / d.count)
+		merged = append(merged, cur)
+		cur = next
+	}
+	d.centroids = append(merged, cur)
+	d.buffer = d.buffer[:0]
+}
+
+// scale is the k1 scale function, which is steeper near the tails.
+func (d *digest) scale(q float64) float64 {
+	return d.compression / (2 * math.Pi) * math.Asin(2*q-1)
+}
+
+// quantile returns the estimated value at the quantile q in [0, 1].
+func (d *digest) quantile(q float64) float64 {
+	d.merge()
+	switch {
+	case len(d.centroids) == 0:
+		return 0
+	case q <= 0:
+		return d.min
+	case q >= 1:
+		return d.max
+	case len(d.centroids) == 1:
+		return d.centroids[0].mean
+	}
+	// interpolates between the centers of the centroids,
+	// and between the min or max and the first or last center.
+	target := q * d.count
+	prevMean, prevPos := d.min, 0.0
+	var cum float64
+	for _, c := range d.centroids {
+		pos := cum + c.weight/2
+		if target < pos {
+			return prevMean + (c.mean-prevMean)*(target-prevPos)/(pos-prevPos)
+		}
+		prevMean, prevPos = c.mean, pos
+		cum += c.weight
+	}
+	if d.count == prevPos {
+		return d.max
+	}
+	return prevMean + (d.max-prevMean)*(target-prevPos)/(d.count-prevPos)
+}
@@ -0,0 +1,27 @@
+package sli
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestDigest(t *testing.T) {
+	d := newDigest(100)
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100000; i++ {
+		d.add(r.Float64())
+	}
+	for _, q := range []float64{0.01, 0.5, 0.9, 0.99, 0.999} {
+		if v := d.quantile(q); math.Abs(v-q) > 0.01 {
+			t.Errorf("got quantile %v = %v", q, v)
+		}
+	}
+	// the memory is bounded by the compression
+	if n := len(d.centroids); n > 100 {
+		t.Errorf("got %d centroids", n)
+	}
+	if d.quantile(0) != d.min || d.quantile(1) != d.max {
+		t.Errorf("got %v %v want %v %v", d.quantile(0), d.quantile(1), d.min, d.max)
+	}
+}
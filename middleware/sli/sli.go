@@ -0,0 +1,267 @@
+package sli
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/metrics"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// Option is SLI option.
+type Option func(*options)
+
+type options struct {
+	quantiles   []float64
+	compression float64
+	concurrency int64
+	failure     func(error) bool
+	// counter: requests{operation, code}
+	requests metrics.Counter
+	// counter: errors{operation, code}
+	errors metrics.Counter
+	// gauge: latency_seconds{operation, quantile}
+	latency metrics.Gauge
+	// gauge: inflight_requests{operation}
+	inflight metrics.Gauge
+}
+
+// WithQuantiles with the latency quantiles, the default is 0.5, 0.9 and 0.99.
+func WithQuantiles(quantiles ...float64) Option {
+	return func(o *options) {
+		o.quantiles = quantiles
+	}
+}
+
+// WithCompression with the compression of the latency digest, the default is 100.
+// The higher the compression the more accurate the quantiles and the more the memory.
+func WithCompression(compression float64) Option {
+	return func(o *options) {
+		o.compression = compression
+	}
+}
+
+// WithConcurrency with the concurrency limit of the server, e.g. of the priority middleware,
+// the saturation is the in-flight requests over it.
+func WithConcurrency(limit int64) Option {
+	return func(o *options) {
+		o.concurrency = limit
+	}
+}
+
+// WithFailure with the func which reports whether the error is counted as an error signal,
+// the default counts the server errors, i.e. the code is 5xx.
+func WithFailure(f func(error) bool) Option {
+	return func(o *options) {
+		o.failure = f
+	}
+}
+
+// WithRequests with the requests counter.
+func WithRequests(c metrics.Counter) Option {
+	return func(o *options) {
+		o.requests = c
+	}
+}
+
+// WithErrors with the errors counter.
+func WithErrors(c metrics.Counter) Option {
+	return func(o *options) {
+		o.errors = c
+	}
+}
+
+// WithLatency with the latency quantiles gauge, which is refreshed as the samples are merged.
+func WithLatency(g metrics.Gauge) Option {
+	return func(o *options) {
+		o.latency = g
+	}
+}
+
+// WithInflight with the in-flight requests gauge.
+func WithInflight(g metrics.Gauge) Option {
+	return func(o *options) {
+		o.inflight = g
+	}
+}
+
+func defaultFailure(err error) bool {
+	return errors.Code(err) >= 500
+}
+
+// Signals is the golden signals of an operation.
+type Signals struct {
+	Operation string `json:"operation"`
+	// Requests is the traffic, the total requests.
+	Requests uint64 `json:"requests"`
+	// Errors is the total failed requests.
+	Errors uint64 `json:"errors"`
+	// Inflight is the requests in progress.
+	Inflight int64 `json:"inflight"`
+	// Latency is the latency seconds by the quantile, e.g. "0.99".
+	Latency map[string]float64 `json:"latency"`
+}
+
+// Snapshot is the golden signals of the server.
+type Snapshot struct {
+	Inflight int64 `json:"inflight"`
+	// Saturation is the in-flight requests over the concurrency limit,
+	// it is zero if the limit is not set.
+	Saturation float64   `json:"saturation"`
+	Operations []Signals `json:"operations"`
+}
+
+type operation struct {
+	requests uint64
+	errors   uint64
+	inflight int64
+
+	mu     sync.Mutex
+	digest *digest
+}
+
+// SLI collects the golden signals of the operations, the latency, traffic,
+// errors and saturation, the operation is the full method for gRPC and the
+// path template for HTTP. The latency is estimated by a t-digest per operation,
+// so the memory is bounded regardless of the requests.
+type SLI struct {
+	inflight int64
+	opts     options
+
+	mu         sync.RWMutex
+	operations map[string]*operation
+}
+
+// New new a SLI with options.
+func New(opts ...Option) *SLI {
+	options := options{
+		quantiles:   []float64{0.5, 0.9, 0.99},
+		compression: 100,
+		failure:     defaultFailure,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+	return &SLI{
+		opts:       options,
+		operations: make(map[string]*operation),
+	}
+}
+
+// Server is a server middleware which collects the golden signals.
+func (s *SLI) Server() middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			name := transport.Operation(ctx)
+			op := s.operation(name)
+			atomic.AddInt64(&s.inflight, 1)
+			atomic.AddInt64(&op.inflight, 1)
+			if s.opts.inflight != nil {
+				s.opts.inflight.With(name).Add(1)
+			}
+			startTime := time.Now()
+			reply, err := handler(ctx, req)
+			s.observe(name, op, time.Since(startTime), err)
+			atomic.AddInt64(&op.inflight, -1)
+			atomic.AddInt64(&s.inflight, -1)
+			if s.opts.inflight != nil {
+				s.opts.inflight.With(name).Sub(1)
+			}
+			return reply, err
+		}
+	}
+}
+
+func (s *SLI) operation(name string) *operation {
+	s.mu.RLock()
+	op, ok := s.operations[name]
+	s.mu.RUnlock()
+	if ok {
+		return op
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if op, ok = s.operations[name]; !ok {
+		op = &operation{digest: newDigest(s.opts.compression)}
+		s.operations[name] = op
+	}
+	return op
+}
+
+func (s *SLI) observe(name string, op *operation, d time.Duration, err error) {
+	code := strconv.Itoa(errors.Code(err))
+	atomic.AddUint64(&op.requests, 1)
+	if s.opts.requests != nil {
+		s.opts.requests.With(name, code).Inc()
+	}
+	if err != nil && s.opts.failure(err) {
+		atomic.AddUint64(&op.errors, 1)
+		if s.opts.errors != nil {
+			s.opts.errors.With(name, code).Inc()
+		}
+	}
+	op.mu.Lock()
+	merged := op.digest.add(d.Seconds())
+	if merged && s.opts.latency != nil {
+		for _, q := range s.opts.quantiles {
+			s.opts.latency.With(name, formatQuantile(q)).Set(op.digest.quantile(q))
+		}
+	}
+	op.mu.Unlock()
+}
+
+// Snapshot returns the golden signals of the operations sorted by the name.
+func (s *SLI) Snapshot() Snapshot {
+	s.mu.RLock()
+	names := make([]string, 0, len(s.operations))
+	for name := range s.operations {
+		names = append(names, name)
+	}
+	s.mu.RUnlock()
+	sort.Strings(names)
+
+	snapshot := Snapshot{
+		Inflight:   atomic.LoadInt64(&s.inflight),
+		Operations: make([]Signals, 0, len(names)),
+	}
+	if s.opts.concurrency > 0 {
+		snapshot.Saturation = float64(snapshot.Inflight) / float64(s.opts.concurrency)
+	}
+	for _, name := range names {
+		op := s.operation(name)
+		signals := Signals{
+			Operation: name,
+			Requests:  atomic.LoadUint64(&op.requests),
+			Errors:    atomic.LoadUint64(&op.errors),
+			Inflight:  atomic.LoadInt64(&op.inflight),
+			Latency:   make(map[string]float64, len(s.opts.quantiles)),
+		}
+		op.mu.Lock()
+		for _, q := range s.opts.quantiles {
+			signals.Latency[formatQuantile(q)] = op.digest.quantile(q)
+		}
+		op.mu.Unlock()
+		snapshot.Operations = append(snapshot.Operations, signals)
+	}
+	return snapshot
+}
+
+// ServeHTTP serves the snapshot as JSON, e.g. mounted on the HTTP server:
+//
+//	srv.Handle("/debug/sli", s)
+func (s *SLI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(s.Snapshot())
+}
+
+func formatQuantile(q float64) string {
+	return strconv.FormatFloat(q, 'f', -1, 64)
+}
@@ -0,0 +1,53 @@
+package sli
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
+func TestServer(t *testing.T) {
+	s := New(WithConcurrency(4))
+	release := make(chan struct{})
+	started := make(chan struct{})
+	next := s.Server()(func(ctx context.Context, req interface{}) (interface{}, error) {
+		switch req {
+		case "block":
+			close(started)
+			<-release
+		case "internal":
+			return nil, errors.InternalServer("INTERNAL", "internal")
+		case "notfound":
+			return nil, errors.NotFound("NOT_FOUND", "not found")
+		}
+		time.Sleep(time.Millisecond)
+		return "ok", nil
+	})
+	for _, req := range []string{"ok", "ok", "internal", "notfound"} {
+		_, _ = next(context.Background(), req)
+	}
+	go next(context.Background(), "block")
+	<-started
+
+	snapshot := s.Snapshot()
+	if snapshot.Inflight != 1 || snapshot.Saturation != 0.25 || len(snapshot.Operations) != 1 {
+		t.Fatalf("unexpected snapshot %+v", snapshot)
+	}
+	// the client errors are not counted as the errors by default
+	if op := snapshot.Operations[0]; op.Requests != 4 || op.Errors != 1 || op.Inflight != 1 || op.Latency["0.99"] <= 0 {
+		t.Fatalf("unexpected signals %+v", op)
+	}
+	close(release)
+
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/sli", nil))
+	var served Snapshot
+	if err := json.Unmarshal(w.Body.Bytes(), &served); err != nil || len(served.Operations) != 1 {
+		t.Fatalf("unexpected served snapshot %s %v", w.Body.String(), err)
+	}
+}
@@ -0,0 +1,76 @@
+package slowlog
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/metrics"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// Option is slowlog option.
+type Option func(*options)
+
+type options struct {
+	logger   log.Logger
+	requests metrics.Counter
+}
+
+// WithLogger with slowlog logger.
+func WithLogger(logger log.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// WithRequests with slow requests counter.
+func WithRequests(c metrics.Counter) Option {
+	return func(o *options) {
+		o.requests = c
+	}
+}
+
+// Server is a server middleware which logs the requests exceeding the latency
+// threshold of the operation, or the default threshold if not configured.
+// The operation is the full method for gRPC and the path template for HTTP.
+func Server(thresholds map[string]time.Duration, defaultThreshold time.Duration, opts ...Option) middleware.Middleware {
+	options := options{
+		logger: log.DefaultLogger,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			startTime := time.Now()
+			reply, err := handler(ctx, req)
+			duration := time.Since(startTime)
+
+			operation := transport.Operation(ctx)
+			threshold, ok := thresholds[operation]
+			if !ok {
+				threshold = defaultThreshold
+			}
+			if threshold <= 0 || duration < threshold {
+				return reply, err
+			}
+			code := errors.Code(err)
+			log.WithContext(ctx, options.logger).Log(log.LevelWarn,
+				"kind", "server",
+				"component", "slowlog",
+				"operation", operation,
+				"duration", duration.Seconds(),
+				"threshold", threshold.Seconds(),
+				"code", code,
+			)
+			if options.requests != nil {
+				options.requests.With(operation, strconv.Itoa(code)).Inc()
+			}
+			return reply, err
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package slowlog
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/transport/grpc"
+)
+
+type testLogger struct {
+	logs int
+}
+
+func (l *testLogger) Log(level log.Level, keyvals ...interface{}) error {
+	l.logs++
+	return nil
+}
+
+func TestServer(t *testing.T) {
+	logger := &testLogger{}
+	thresholds := map[string]time.Duration{"/test.Test/Slow": 10 * time.Millisecond}
+	next := func(ctx context.Context, req interface{}) (interface{}, error) {
+		time.Sleep(20 * time.Millisecond)
+		return nil, nil
+	}
+	h := Server(thresholds, time.Second, WithLogger(logger))(next)
+
+	ctx := grpc.NewServerContext(context.Background(), grpc.ServerInfo{FullMethod: "/test.Test/Slow"})
+	if _, err := h(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+	ctx = grpc.NewServerContext(context.Background(), grpc.ServerInfo{FullMethod: "/test.Test/Fast"})
+	if _, err := h(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+	if logger.logs != 1 {
+		t.Fatalf("expected 1 slow log got %d", logger.logs)
+	}
+}
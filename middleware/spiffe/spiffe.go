@@ -0,0 +1,75 @@
+package spiffe
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/go-kratos/kratos/v2/transport/grpc"
+	"github.com/go-kratos/kratos/v2/transport/http"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// Policy reports whether the SPIFFE ID is allowed to call the operation.
+type Policy func(id string, operation string) bool
+
+type idKey struct{}
+
+// FromContext returns the SPIFFE ID of the caller stored in ctx, if any.
+func FromContext(ctx context.Context) (id string, ok bool) {
+	id, ok = ctx.Value(idKey{}).(string)
+	return
+}
+
+// Server is a server middleware which authorizes the callers by the SPIFFE ID
+// in the URI SAN of the verified mTLS peer certificate.
+// The operation is the full method for gRPC and the path template for HTTP, see transport.Operation.
+func Server(policy Policy) middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			var state *tls.ConnectionState
+			if _, ok := grpc.FromServerContext(ctx); ok {
+				if p, ok := peer.FromContext(ctx); ok {
+					if ti, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+						state = &ti.State
+					}
+				}
+			} else if info, ok := http.FromServerContext(ctx); ok {
+				state = info.Request.TLS
+			}
+			operation := transport.Operation(ctx)
+			id, err := extractID(state)
+			if err != nil {
+				return nil, errors.Unauthorized("SPIFFE", err.Error())
+			}
+			if !policy(id, operation) {
+				return nil, errors.Forbidden("SPIFFE", fmt.Sprintf("%s is not allowed to call %s", id, operation))
+			}
+			return handler(context.WithValue(ctx, idKey{}, id), req)
+		}
+	}
+}
+
+func extractID(state *tls.ConnectionState) (string, error) {
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return "", fmt.Errorf("no verified peer certificate")
+	}
+	var id string
+	for _, u := range state.VerifiedChains[0][0].URIs {
+		if u.Scheme != "spiffe" {
+			continue
+		}
+		if id != "" {
+			return "", fmt.Errorf("multiple SPIFFE IDs in peer certificate")
+		}
+		id = u.String()
+	}
+	if id == "" {
+		return "", fmt.Errorf("no SPIFFE ID in peer certificate")
+	}
+	return id, nil
+}
@@ -0,0 +1,101 @@
+package spiffe
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/transport/grpc"
+	transhttp "github.com/go-kratos/kratos/v2/transport/http"
+	"github.com/gorilla/mux"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+func tlsState(uris ...string) *tls.ConnectionState {
+	cert := &x509.Certificate{}
+	for _, uri := range uris {
+		u, err := url.Parse(uri)
+		if err != nil {
+			panic(err)
+		}
+		cert.URIs = append(cert.URIs, u)
+	}
+	return &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+}
+
+func httpContext(path string, state *tls.ConnectionState) context.Context {
+	req := &http.Request{URL: &url.URL{Path: path}, TLS: state}
+	return transhttp.NewServerContext(context.Background(), transhttp.ServerInfo{Request: req})
+}
+
+// routedContext returns the context of the HTTP request routed by the path template.
+func routedContext(template, path string, state *tls.ConnectionState) context.Context {
+	var ctx context.Context
+	r := mux.NewRouter()
+	r.HandleFunc(template, func(w http.ResponseWriter, req *http.Request) {
+		ctx = transhttp.NewServerContext(req.Context(), transhttp.ServerInfo{Request: req})
+	})
+	req := &http.Request{Method: http.MethodGet, URL: &url.URL{Path: path}, TLS: state}
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	return ctx
+}
+
+func grpcContext(method string, state *tls.ConnectionState) context.Context {
+	ctx := grpc.NewServerContext(context.Background(), grpc.ServerInfo{FullMethod: method})
+	if state != nil {
+		ctx = peer.NewContext(ctx, &peer.Peer{AuthInfo: credentials.TLSInfo{State: *state}})
+	}
+	return ctx
+}
+
+func TestServer(t *testing.T) {
+	policy := func(id string, operation string) bool {
+		return id == "spiffe://example.org/client" && (operation == "/test.Echo/Say" || operation == "/v1/say" || operation == "/v1/users/{id}")
+	}
+	tests := []struct {
+		name string
+		ctx  context.Context
+		code int
+	}{
+		{"grpc allowed", grpcContext("/test.Echo/Say", tlsState("spiffe://example.org/client")), 200},
+		{"http allowed", httpContext("/v1/say", tlsState("https://example.org", "spiffe://example.org/client")), 200},
+		{"http allowed template", routedContext("/v1/users/{id}", "/v1/users/1", tlsState("spiffe://example.org/client")), 200},
+		{"http forbidden path", httpContext("/v1/users/1", tlsState("spiffe://example.org/client")), 403},
+		{"grpc forbidden operation", grpcContext("/test.Echo/Other", tlsState("spiffe://example.org/client")), 403},
+		{"http forbidden id", httpContext("/v1/say", tlsState("spiffe://example.org/other")), 403},
+		{"no transport", context.Background(), 401},
+		{"no tls", httpContext("/v1/say", nil), 401},
+		{"no peer", grpcContext("/test.Echo/Say", nil), 401},
+		{"no verified chains", httpContext("/v1/say", &tls.ConnectionState{}), 401},
+		{"no spiffe id", httpContext("/v1/say", tlsState("https://example.org")), 401},
+		{"multiple spiffe ids", grpcContext("/test.Echo/Say", tlsState("spiffe://example.org/client", "spiffe://example.org/other")), 401},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var id string
+			h := Server(policy)(func(ctx context.Context, req interface{}) (interface{}, error) {
+				id, _ = FromContext(ctx)
+				return "reply", nil
+			})
+			_, err := h(test.ctx, "req")
+			if test.code == 200 {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if id != "spiffe://example.org/client" {
+					t.Fatalf("unexpected id %s", id)
+				}
+				return
+			}
+			if errors.Code(err) != test.code || errors.Reason(err) != "SPIFFE" {
+				t.Fatalf("expected %d got %v", test.code, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package version
+
+import (
+	"context"
+
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// Info is the build info of the server, which is usually set by the ldflags, e.g.
+// go build -ldflags "-X main.Version=v1.0.0 -X main.Commit=$(git rev-parse HEAD)".
+type Info struct {
+	Version   string
+	Commit    string
+	BuildTime string
+}
+
+// Server is a server middleware which adds the build info to the response headers
+// X-Server-Version, X-Server-Commit and X-Server-Build-Time, the empty ones are omitted.
+func Server(info Info) middleware.Middleware {
+	var headers [][2]string
+	for _, h := range [][2]string{
+		{"X-Server-Version", info.Version},
+		{"X-Server-Commit", info.Commit},
+		{"X-Server-Build-Time", info.BuildTime},
+	} {
+		if h[1] != "" {
+			headers = append(headers, h)
+		}
+	}
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			for _, h := range headers {
+				_ = transport.SetHeader(ctx, h[0], h[1])
+			}
+			return handler(ctx, req)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package version
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+type header map[string]string
+
+func (h header) SetHeader(key, value string) error {
+	h[key] = value
+	return nil
+}
+
+func (h header) SetTrailer(key, value string) error {
+	return nil
+}
+
+func TestServer(t *testing.T) {
+	tests := []struct {
+		name    string
+		info    Info
+		headers map[string]string
+	}{
+		{
+			"full",
+			Info{Version: "v1.0.0", Commit: "abc123", BuildTime: "2021-01-01T00:00:00Z"},
+			map[string]string{
+				"X-Server-Version":    "v1.0.0",
+				"X-Server-Commit":     "abc123",
+				"X-Server-Build-Time": "2021-01-01T00:00:00Z",
+			},
+		},
+		{
+			"version only",
+			Info{Version: "v1.0.0"},
+			map[string]string{"X-Server-Version": "v1.0.0"},
+		},
+		{
+			"empty",
+			Info{},
+			map[string]string{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			h := header{}
+			ctx := transport.NewHeaderContext(context.Background(), h)
+			reply, err := Server(test.info)(func(ctx context.Context, req interface{}) (interface{}, error) {
+				return "reply", nil
+			})(ctx, "req")
+			if err != nil || reply != "reply" {
+				t.Fatalf("unexpected reply %v %v", reply, err)
+			}
+			if !reflect.DeepEqual(map[string]string(h), test.headers) {
+				t.Fatalf("expected %v got %v", test.headers, h)
+			}
+		})
+	}
+}
+
+func TestServerWithoutHeader(t *testing.T) {
+	_, err := Server(Info{Version: "v1.0.0"})(func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "reply", nil
+	})(context.Background(), "req")
+	if err != nil {
+		t.Fatal(err)
+	}
+}
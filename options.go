@@ -4,7 +4,9 @@ import (
 	"context"
 	"net/url"
 	"os"
+	"time"
 
+	"github.com/go-kratos/kratos/v2/id"
 	"github.com/go-kratos/kratos/v2/log"
 	"github.com/go-kratos/kratos/v2/registry"
 	"github.com/go-kratos/kratos/v2/transport"
@@ -16,13 +18,15 @@ type Option func(o *options)
 // options is an application options.
 type options struct {
 	id        string
+	idGen     id.Generator
 	name      string
 	version   string
 	metadata  map[string]string
 	endpoints []*url.URL
 
-	ctx  context.Context
-	sigs []os.Signal
+	ctx       context.Context
+	sigs      []os.Signal
+	drainWait time.Duration
 
 	logger    log.Logger
 	registrar registry.Registrar
@@ -35,6 +39,12 @@ func ID(id string) Option {
 	return func(o *options) { o.id = id }
 }
 
+// IDGenerator with service id generator, which generates the id if not specified by ID,
+// it should be the same as the generator of the request id middleware.
+func IDGenerator(g id.Generator) Option {
+	return func(o *options) { o.idGen = g }
+}
+
 // Name with service name.
 func Name(name string) Option {
 	return func(o *options) { o.name = name }
@@ -75,6 +85,12 @@ func Signal(sigs ...os.Signal) Option {
 	return func(o *options) { o.sigs = sigs }
 }
 
+// DrainWait with the time Drain waits for the load balancers and the clients
+// to stop sending new requests after the deregistration.
+func DrainWait(d time.Duration) Option {
+	return func(o *options) { o.drainWait = d }
+}
+
 // Registrar with service registry.
 func Registrar(r registry.Registrar) Option {
 	return func(o *options) { o.registrar = r }
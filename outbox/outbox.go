@@ -0,0 +1,199 @@
+// Package outbox implements the transactional outbox pattern, the events are added to the
+// outbox within the transaction of the business change, and the relay publishes them to
+// the broker in the background with the at-least-once semantics, so the consumers should
+// deduplicate the events by the ID.
+package outbox
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+var _ transport.Server = (*Relay)(nil)
+
+// Event is an event in the outbox.
+type Event struct {
+	ID        string
+	Topic     string
+	Key       string
+	Payload   []byte
+	Headers   map[string]string
+	CreatedAt time.Time
+}
+
+// Store is the outbox store, which is usually a table in the business database.
+type Store interface {
+	// Add adds the events to the outbox, it should join the transaction in ctx
+	// of the business change, so the events are committed or rolled back with it.
+	Add(ctx context.Context, events ...*Event) error
+	// Pending returns up to limit events not acknowledged in the order they are added.
+	Pending(ctx context.Context, limit int) ([]*Event, error)
+	// Ack removes or marks the published events.
+	Ack(ctx context.Context, ids ...string) error
+}
+
+// Publisher publishes the events to the broker.
+type Publisher interface {
+	Publish(ctx context.Context, event *Event) error
+}
+
+// PublisherFunc is a func which implements the Publisher.
+type PublisherFunc func(ctx context.Context, event *Event) error
+
+// Publish calls f(ctx, event).
+func (f PublisherFunc) Publish(ctx context.Context, event *Event) error {
+	return f(ctx, event)
+}
+
+// Option is relay option.
+type Option func(*Relay)
+
+// WithInterval with the interval of polling the pending events, the default is 1 second.
+func WithInterval(d time.Duration) Option {
+	return func(r *Relay) {
+		r.interval = d
+	}
+}
+
+// WithBatchSize with the max events relayed in a batch, the default is 100.
+func WithBatchSize(n int) Option {
+	return func(r *Relay) {
+		r.batchSize = n
+	}
+}
+
+// WithLogger with relay logger.
+func WithLogger(logger log.Logger) Option {
+	return func(r *Relay) {
+		r.log = log.NewHelper(logger)
+	}
+}
+
+// Relay is a transport server which publishes the pending events of the outbox
+// in the order they are added, the events are acknowledged after they are published,
+// so an event may be published more than once if the relay fails before the ack.
+type Relay struct {
+	store     Store
+	publisher Publisher
+	interval  time.Duration
+	batchSize int
+	log       *log.Helper
+
+	notify  chan struct{}
+	mu      sync.Mutex
+	stopped bool
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// NewRelay new a relay of the outbox store and the publisher.
+func NewRelay(store Store, publisher Publisher, opts ...Option) *Relay {
+	r := &Relay{
+		store:     store,
+		publisher: publisher,
+		interval:  time.Second,
+		batchSize: 100,
+		log:       log.NewHelper(log.DefaultLogger),
+		notify:    make(chan struct{}, 1),
+	}
+	for _, o := range opts {
+		o(r)
+	}
+	return r
+}
+
+// Notify wakes up the relay to publish the events without waiting for the interval,
+// e.g. after the transaction with the events is committed.
+func (r *Relay) Notify() {
+	select {
+	case r.notify <- struct{}{}:
+	default:
+	}
+}
+
+// Start starts the relay, which blocks until the relay is stopped,
+// it returns immediately if the relay is already stopped.
+func (r *Relay) Start(ctx context.Context) error {
+	r.mu.Lock()
+	if r.stopped {
+		r.mu.Unlock()
+		return nil
+	}
+	ctx, r.cancel = context.WithCancel(ctx)
+	// added under the lock, so the Wait of Stop never races with it
+	r.wg.Add(1)
+	r.mu.Unlock()
+	defer r.wg.Done()
+	r.log.Info("[outbox] relay starting")
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		for {
+			n, err := r.Relay(ctx)
+			if err != nil {
+				r.log.Errorf("[outbox] relay failed: %v", err)
+			}
+			// relays the next batch immediately if the batch is full
+			if err != nil || n < r.batchSize {
+				break
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		case <-r.notify:
+		}
+	}
+}
+
+// Stop stops the relay and waits for the relaying batch to finish.
+func (r *Relay) Stop(ctx context.Context) error {
+	r.mu.Lock()
+	r.stopped = true
+	if r.cancel != nil {
+		r.cancel()
+	}
+	r.mu.Unlock()
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	r.log.Info("[outbox] relay stopping")
+	return nil
+}
+
+// Relay publishes a batch of the pending events and returns the number of the published ones,
+// it stops at the first failed event to keep the order, which is retried by the next batch.
+func (r *Relay) Relay(ctx context.Context) (int, error) {
+	events, err := r.store.Pending(ctx, r.batchSize)
+	if err != nil {
+		return 0, err
+	}
+	var (
+		ids        []string
+		publishErr error
+	)
+	for _, e := range events {
+		if publishErr = r.publisher.Publish(ctx, e); publishErr != nil {
+			break
+		}
+		ids = append(ids, e.ID)
+	}
+	if len(ids) > 0 {
+		if err := r.store.Ack(ctx, ids...); err != nil {
+			return 0, err
+		}
+	}
+	return len(ids), publishErr
+}
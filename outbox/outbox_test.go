@@ -0,0 +1,131 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type memoryStore struct {
+	mu     sync.Mutex
+	events []*Event
+}
+
+func (s *memoryStore) Add(ctx context.Context, events ...*Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, events...)
+	return nil
+}
+
+func (s *memoryStore) Pending(ctx context.Context, limit int) ([]*Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.events) < limit {
+		limit = len(s.events)
+	}
+	return append([]*Event(nil), s.events[:limit]...), nil
+}
+
+func (s *memoryStore) Ack(ctx context.Context, ids ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	acked := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		acked[id] = true
+	}
+	var events []*Event
+	for _, e := range s.events {
+		if !acked[e.ID] {
+			events = append(events, e)
+		}
+	}
+	s.events = events
+	return nil
+}
+
+func TestRelay(t *testing.T) {
+	store := &memoryStore{}
+	_ = store.Add(context.Background(), &Event{ID: "1"}, &Event{ID: "2"}, &Event{ID: "3"})
+	var (
+		published []string
+		failed    bool
+	)
+	r := NewRelay(store, PublisherFunc(func(ctx context.Context, e *Event) error {
+		if e.ID == "2" && !failed {
+			failed = true
+			return errors.New("broker unavailable")
+		}
+		published = append(published, e.ID)
+		return nil
+	}), WithBatchSize(2))
+	if n, err := r.Relay(context.Background()); n != 1 || err == nil {
+		t.Fatalf("expected 1 published and the publish error got %d %v", n, err)
+	}
+	if n, err := r.Relay(context.Background()); n != 2 || err != nil {
+		t.Fatalf("expected 2 published got %d %v", n, err)
+	}
+	if n, err := r.Relay(context.Background()); n != 0 || err != nil {
+		t.Fatalf("expected nothing pending got %d %v", n, err)
+	}
+	if len(published) != 3 || published[0] != "1" || published[1] != "2" || published[2] != "3" {
+		t.Fatalf("expected the events published in order got %v", published)
+	}
+}
+
+func TestRelayServer(t *testing.T) {
+	store := &memoryStore{}
+	published := make(chan string, 1)
+	r := NewRelay(store, PublisherFunc(func(ctx context.Context, e *Event) error {
+		published <- e.ID
+		return nil
+	}), WithInterval(time.Hour))
+	done := make(chan error, 1)
+	go func() {
+		done <- r.Start(context.Background())
+	}()
+	_ = store.Add(context.Background(), &Event{ID: "1"})
+	r.Notify()
+	select {
+	case id := <-published:
+		if id != "1" {
+			t.Fatalf("unexpected event %s", id)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the event published after notify")
+	}
+	if err := r.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRelayStopBeforeStart(t *testing.T) {
+	r := NewRelay(&memoryStore{}, PublisherFunc(func(ctx context.Context, e *Event) error {
+		return nil
+	}), WithInterval(time.Hour))
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- r.Stop(context.Background())
+	}()
+	done := make(chan error, 1)
+	go func() {
+		done <- r.Start(context.Background())
+	}()
+	if err := <-stopped; err != nil {
+		t.Fatal(err)
+	}
+	// the relay is stopped whether Start or Stop runs first
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the relay stopped")
+	}
+}
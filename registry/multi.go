@@ -0,0 +1,138 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrWatcherStopped is returned by Next of the stopped watcher of MultiDiscovery.
+var ErrWatcherStopped = errors.New("registry: watcher stopped")
+
+type multiDiscovery struct {
+	ds []Discovery
+}
+
+// MultiDiscovery returns a Discovery which merges the service instances of the discoveries,
+// e.g. of both the old and the new registries during a migration, the instances of the
+// same ID are de-duplicated in favor of the former discovery. GetService fails only if
+// all the discoveries fail, and the watcher watches all of them.
+func MultiDiscovery(ds ...Discovery) Discovery {
+	return &multiDiscovery{ds: ds}
+}
+
+func (m *multiDiscovery) GetService(ctx context.Context, serviceName string) ([]*ServiceInstance, error) {
+	var (
+		lists  = make([][]*ServiceInstance, 0, len(m.ds))
+		errOne error
+	)
+	for _, d := range m.ds {
+		ins, err := d.GetService(ctx, serviceName)
+		if err != nil {
+			if errOne == nil {
+				errOne = err
+			}
+			continue
+		}
+		lists = append(lists, ins)
+	}
+	if len(lists) == 0 && errOne != nil {
+		return nil, errOne
+	}
+	return merge(lists), nil
+}
+
+func (m *multiDiscovery) Watch(ctx context.Context, serviceName string) (Watcher, error) {
+	ws := make([]Watcher, 0, len(m.ds))
+	for _, d := range m.ds {
+		w, err := d.Watch(ctx, serviceName)
+		if err != nil {
+			for _, w := range ws {
+				w.Stop()
+			}
+			return nil, err
+		}
+		ws = append(ws, w)
+	}
+	mw := &multiWatcher{
+		ws:     ws,
+		latest: make([][]*ServiceInstance, len(ws)),
+		events: make(chan event),
+		done:   make(chan struct{}),
+	}
+	for i, w := range ws {
+		go mw.pump(i, w)
+	}
+	return mw, nil
+}
+
+type event struct {
+	index int
+	ins   []*ServiceInstance
+	err   error
+}
+
+// multiWatcher merges the latest instances of the watchers on any change of them.
+type multiWatcher struct {
+	ws     []Watcher
+	latest [][]*ServiceInstance
+	events chan event
+	done   chan struct{}
+	once   sync.Once
+}
+
+// pump sends the changes of the watcher, it blocks until they are taken by Next,
+// so a failing watcher is retried at the pace of the caller.
+func (w *multiWatcher) pump(i int, watcher Watcher) {
+	for {
+		ins, err := watcher.Next()
+		select {
+		case w.events <- event{index: i, ins: ins, err: err}:
+		case <-w.done:
+			return
+		}
+	}
+}
+
+func (w *multiWatcher) Next() ([]*ServiceInstance, error) {
+	select {
+	case e := <-w.events:
+		if e.err != nil {
+			return nil, e.err
+		}
+		w.latest[e.index] = e.ins
+		return merge(w.latest), nil
+	case <-w.done:
+		return nil, ErrWatcherStopped
+	}
+}
+
+func (w *multiWatcher) Stop() error {
+	var err error
+	w.once.Do(func() {
+		close(w.done)
+		for _, watcher := range w.ws {
+			if e := watcher.Stop(); e != nil && err == nil {
+				err = e
+			}
+		}
+	})
+	return err
+}
+
+func merge(lists [][]*ServiceInstance) []*ServiceInstance {
+	var (
+		merged []*ServiceInstance
+		seen   = make(map[string]struct{})
+	)
+	for _, ins := range lists {
+		for _, in := range ins {
+			if _, ok := seen[in.ID]; ok {
+				continue
+			}
+			seen[in.ID] = struct{}{}
+			merged = append(merged, in)
+		}
+	}
+	return merged
+}
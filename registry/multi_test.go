@@ -0,0 +1,101 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type testDiscovery struct {
+	ins []*ServiceInstance
+	err error
+	ch  chan []*ServiceInstance
+}
+
+func (d *testDiscovery) GetService(ctx context.Context, serviceName string) ([]*ServiceInstance, error) {
+	return d.ins, d.err
+}
+
+func (d *testDiscovery) Watch(ctx context.Context, serviceName string) (Watcher, error) {
+	return &testWatcher{ch: d.ch, done: make(chan struct{})}, nil
+}
+
+type testWatcher struct {
+	ch   chan []*ServiceInstance
+	done chan struct{}
+}
+
+func (w *testWatcher) Next() ([]*ServiceInstance, error) {
+	select {
+	case ins := <-w.ch:
+		return ins, nil
+	case <-w.done:
+		return nil, context.Canceled
+	}
+}
+
+func (w *testWatcher) Stop() error {
+	close(w.done)
+	return nil
+}
+
+func ids(ins []*ServiceInstance) map[string]bool {
+	m := make(map[string]bool)
+	for _, in := range ins {
+		m[in.ID] = true
+	}
+	return m
+}
+
+func TestMultiDiscoveryGetService(t *testing.T) {
+	consul := &testDiscovery{ins: []*ServiceInstance{{ID: "1"}, {ID: "2"}}}
+	etcd := &testDiscovery{ins: []*ServiceInstance{{ID: "2"}, {ID: "3"}}}
+	ins, err := MultiDiscovery(consul, etcd).GetService(context.Background(), "hello")
+	if err != nil || len(ins) != 3 {
+		t.Fatalf("expected 3 instances got %v %v", ins, err)
+	}
+	etcd.err = errors.New("etcd is down")
+	if ins, err = MultiDiscovery(consul, etcd).GetService(context.Background(), "hello"); err != nil || len(ins) != 2 {
+		t.Fatalf("expected the instances of consul got %v %v", ins, err)
+	}
+	consul.err = errors.New("consul is down")
+	if _, err = MultiDiscovery(consul, etcd).GetService(context.Background(), "hello"); err == nil {
+		t.Fatal("expected the error if all the discoveries fail")
+	}
+}
+
+func TestMultiDiscoveryWatch(t *testing.T) {
+	consul := &testDiscovery{ch: make(chan []*ServiceInstance, 1)}
+	etcd := &testDiscovery{ch: make(chan []*ServiceInstance, 1)}
+	w, err := MultiDiscovery(consul, etcd).Watch(context.Background(), "hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	consul.ch <- []*ServiceInstance{{ID: "1"}, {ID: "2"}}
+	if ins, _ := w.Next(); len(ins) != 2 {
+		t.Fatalf("expected the instances of consul got %v", ins)
+	}
+	etcd.ch <- []*ServiceInstance{{ID: "2"}, {ID: "3"}}
+	if got := ids(mustNext(t, w)); len(got) != 3 || !got["1"] || !got["3"] {
+		t.Fatalf("expected the merged instances got %v", got)
+	}
+	// the instances are moved to etcd
+	consul.ch <- nil
+	if got := ids(mustNext(t, w)); len(got) != 2 || !got["2"] || !got["3"] {
+		t.Fatalf("expected the instances of etcd got %v", got)
+	}
+	if err := w.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Next(); err != ErrWatcherStopped {
+		t.Fatalf("expected ErrWatcherStopped got %v", err)
+	}
+}
+
+func mustNext(t *testing.T, w Watcher) []*ServiceInstance {
+	ins, err := w.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ins
+}
@@ -0,0 +1,116 @@
+package auto
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/go-kratos/kratos/v2/registry"
+	transgrpc "github.com/go-kratos/kratos/v2/transport/grpc"
+	transhttp "github.com/go-kratos/kratos/v2/transport/http"
+	"google.golang.org/grpc"
+)
+
+// Option is auto client option.
+type Option func(*options)
+
+type options struct {
+	insecure    bool
+	grpcOptions []transgrpc.ClientOption
+	httpOptions []transhttp.ClientOption
+}
+
+// WithInsecure with an insecure gRPC connection.
+func WithInsecure() Option {
+	return func(o *options) {
+		o.insecure = true
+	}
+}
+
+// WithGRPCOptions with the options of the gRPC client.
+func WithGRPCOptions(opts ...transgrpc.ClientOption) Option {
+	return func(o *options) {
+		o.grpcOptions = append(o.grpcOptions, opts...)
+	}
+}
+
+// WithHTTPOptions with the options of the HTTP client.
+func WithHTTPOptions(opts ...transhttp.ClientOption) Option {
+	return func(o *options) {
+		o.httpOptions = append(o.httpOptions, opts...)
+	}
+}
+
+// Client is a client of either gRPC or HTTP transport, the generated stubs
+// are built from GRPC with NewXxxClient, or from HTTP with NewXxxHTTPClient.
+type Client struct {
+	conn   *grpc.ClientConn
+	client *transhttp.Client
+}
+
+// NewClient returns a client of the service, which prefers gRPC when any
+// discovered instance advertises a grpc endpoint, and falls back to HTTP.
+func NewClient(ctx context.Context, d registry.Discovery, name string, opts ...Option) (*Client, error) {
+	options := options{}
+	for _, o := range opts {
+		o(&options)
+	}
+	instances, err := d.GetService(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	schemes := make(map[string]bool)
+	for _, in := range instances {
+		for _, e := range in.Endpoints {
+			if u, err := url.Parse(e); err == nil {
+				schemes[u.Scheme] = true
+			}
+		}
+	}
+	endpoint := "discovery:///" + name
+	switch {
+	case schemes["grpc"]:
+		opts := append([]transgrpc.ClientOption{
+			transgrpc.WithEndpoint(endpoint),
+			transgrpc.WithDiscovery(d),
+		}, options.grpcOptions...)
+		dial := transgrpc.Dial
+		if options.insecure {
+			dial = transgrpc.DialInsecure
+		}
+		conn, err := dial(ctx, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return &Client{conn: conn}, nil
+	case schemes["http"] || schemes["https"]:
+		opts := append([]transhttp.ClientOption{
+			transhttp.WithEndpoint(endpoint),
+			transhttp.WithDiscovery(d),
+		}, options.httpOptions...)
+		client, err := transhttp.NewClient(ctx, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return &Client{client: client}, nil
+	}
+	return nil, fmt.Errorf("auto: no grpc or http endpoint found for service: %s", name)
+}
+
+// GRPC returns the gRPC connection if the gRPC transport is selected.
+func (c *Client) GRPC() (*grpc.ClientConn, bool) {
+	return c.conn, c.conn != nil
+}
+
+// HTTP returns the HTTP client if the HTTP transport is selected.
+func (c *Client) HTTP() (*transhttp.Client, bool) {
+	return c.client, c.client != nil
+}
+
+// Close closes the gRPC connection.
+func (c *Client) Close() error {
+	if c.conn != nil {
+		return c.conn.Close()
+	}
+	return nil
+}
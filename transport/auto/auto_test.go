@@ -0,0 +1,45 @@
+package auto
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/registry"
+)
+
+type testDiscovery struct {
+	endpoints []string
+}
+
+func (d *testDiscovery) GetService(ctx context.Context, name string) ([]*registry.ServiceInstance, error) {
+	return []*registry.ServiceInstance{{ID: "1", Name: name, Endpoints: d.endpoints}}, nil
+}
+
+func (d *testDiscovery) Watch(ctx context.Context, name string) (registry.Watcher, error) {
+	return &testWatcher{}, nil
+}
+
+type testWatcher struct{}
+
+func (w *testWatcher) Next() ([]*registry.ServiceInstance, error) {
+	select {}
+}
+
+func (w *testWatcher) Stop() error {
+	return nil
+}
+
+func TestNewClient(t *testing.T) {
+	ctx := context.Background()
+	c, err := NewClient(ctx, &testDiscovery{endpoints: []string{"http://127.0.0.1:8000", "grpc://127.0.0.1:9000"}}, "helloworld", WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.GRPC(); !ok {
+		t.Fatal("expected grpc transport")
+	}
+	c.Close()
+	if _, err = NewClient(ctx, &testDiscovery{}, "helloworld"); err == nil {
+		t.Fatal("expected no endpoint error")
+	}
+}
@@ -0,0 +1,90 @@
+package broker
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// Message is a message of the broker.
+type Message struct {
+	Topic   string
+	Key     []byte
+	Value   []byte
+	Headers map[string]string
+}
+
+// Handler handles a message, the message is acknowledged if it returns nil.
+type Handler func(ctx context.Context, msg *Message) error
+
+// Driver is the broker driver, e.g. Kafka.
+type Driver interface {
+	// Subscribe consumes the messages of the topic until ctx is done,
+	// it acknowledges (commits) a message after the handler returns nil,
+	// and redelivers it otherwise.
+	Subscribe(ctx context.Context, topic string, h Handler) error
+}
+
+// ServerInfo represent broker server information.
+type ServerInfo struct {
+	// Topic is the topic or subject of the message, which is the operation of the middleware.
+	Topic string
+	// Message is the consuming message.
+	Message *Message
+}
+
+type serverKey struct{}
+
+// NewServerContext returns a new Context that carries value, the topic as the operation
+// and the headers of the message as the request header.
+func NewServerContext(ctx context.Context, info ServerInfo) context.Context {
+	ctx = transport.NewOperationContext(ctx, func(context.Context) string {
+		return info.Topic
+	})
+	ctx = transport.NewRequestHeaderContext(ctx, func(context.Context) transport.HeaderCarrier {
+		if info.Message == nil {
+			return headerCarrier(nil)
+		}
+		return headerCarrier(info.Message.Headers)
+	})
+	return context.WithValue(ctx, serverKey{}, info)
+}
+
+// FromServerContext returns the ServerInfo value stored in ctx, if any.
+func FromServerContext(ctx context.Context) (info ServerInfo, ok bool) {
+	info, ok = ctx.Value(serverKey{}).(ServerInfo)
+	return
+}
+
+// headerCarrier is the headers of the message.
+type headerCarrier map[string]string
+
+func (c headerCarrier) Get(key string) string {
+	if v, ok := c[key]; ok {
+		return v
+	}
+	for k, v := range c {
+		if strings.EqualFold(k, key) {
+			return v
+		}
+	}
+	return ""
+}
+
+func (c headerCarrier) Values(key string) []string {
+	for k, v := range c {
+		if strings.EqualFold(k, key) {
+			return []string{v}
+		}
+	}
+	return nil
+}
+
+func (c headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, strings.ToLower(k))
+	}
+	return keys
+}
@@ -0,0 +1,10 @@
+module github.com/go-kratos/kratos/transport/broker/kafka/v2
+
+go 1.15
+
+require (
+	github.com/go-kratos/kratos/v2 v2.0.0-rc1
+	github.com/segmentio/kafka-go v0.4.17
+)
+
+replace github.com/go-kratos/kratos/v2 => ../../../
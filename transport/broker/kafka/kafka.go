@@ -0,0 +1,98 @@
+package kafka
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/transport/broker"
+	"github.com/segmentio/kafka-go"
+)
+
+var _ broker.Driver = (*Driver)(nil)
+
+// Option is kafka driver option.
+type Option func(*Driver)
+
+// Backoff with the backoff of redelivering the failed message, the default is 1 second.
+func Backoff(backoff time.Duration) Option {
+	return func(d *Driver) {
+		d.backoff = backoff
+	}
+}
+
+// ReaderConfig with the func which modifies the reader config of each topic,
+// e.g. the MinBytes, MaxWait and the Dialer of TLS and SASL.
+func ReaderConfig(f func(*kafka.ReaderConfig)) Option {
+	return func(d *Driver) {
+		d.config = f
+	}
+}
+
+// Driver is a Kafka broker driver, which consumes the topics as the consumer group,
+// and commits the offset of a message once it is handled. A failed message is redelivered
+// after the backoff until it is handled, so the order of the partition is kept.
+type Driver struct {
+	brokers []string
+	group   string
+	backoff time.Duration
+	config  func(*kafka.ReaderConfig)
+}
+
+// New new a Kafka driver of the brokers and the consumer group.
+func New(brokers []string, group string, opts ...Option) *Driver {
+	d := &Driver{
+		brokers: brokers,
+		group:   group,
+		backoff: time.Second,
+	}
+	for _, o := range opts {
+		o(d)
+	}
+	return d
+}
+
+// Subscribe consumes the messages of the topic until ctx is done.
+func (d *Driver) Subscribe(ctx context.Context, topic string, h broker.Handler) error {
+	config := kafka.ReaderConfig{
+		Brokers: d.brokers,
+		GroupID: d.group,
+		Topic:   topic,
+	}
+	if d.config != nil {
+		d.config(&config)
+	}
+	r := kafka.NewReader(config)
+	defer r.Close()
+	for {
+		m, err := r.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		msg := &broker.Message{
+			Topic:   m.Topic,
+			Key:     m.Key,
+			Value:   m.Value,
+			Headers: make(map[string]string, len(m.Headers)),
+		}
+		for _, header := range m.Headers {
+			msg.Headers[header.Key] = string(header.Value)
+		}
+		for h(ctx, msg) != nil {
+			select {
+			case <-ctx.Done():
+				// the uncommitted message is redelivered to the group
+				return nil
+			case <-time.After(d.backoff):
+			}
+		}
+		if err := r.CommitMessages(ctx, m); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+	}
+}
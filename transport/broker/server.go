@@ -0,0 +1,153 @@
+package broker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	"golang.org/x/sync/errgroup"
+)
+
+var _ transport.Server = (*Server)(nil)
+
+// ServerOption is broker server option.
+type ServerOption func(o *Server)
+
+// Logger with server logger.
+func Logger(logger log.Logger) ServerOption {
+	return func(s *Server) {
+		s.log = log.NewHelper(logger)
+	}
+}
+
+// Middleware with server middleware.
+func Middleware(m ...middleware.Middleware) ServerOption {
+	return func(s *Server) {
+		s.middleware = middleware.Chain(m...)
+	}
+}
+
+// Retry with the attempts of handling a message including the first one and the backoff between them,
+// the default is 1 attempt. The message is redelivered by the driver once the attempts are exhausted,
+// unless there is a dead letter handler.
+func Retry(attempts int, backoff time.Duration) ServerOption {
+	return func(s *Server) {
+		s.attempts = attempts
+		s.backoff = backoff
+	}
+}
+
+// DeadLetter with the handler of the messages failed after all attempts, e.g. publishes them to
+// a dead letter topic, the message is acknowledged if it returns nil so it does not block the others.
+func DeadLetter(h func(ctx context.Context, msg *Message, err error) error) ServerOption {
+	return func(s *Server) {
+		s.deadLetter = h
+	}
+}
+
+// Server is a broker server which consumes the messages of the topics by the driver,
+// and dispatches them through the middleware to the handlers.
+type Server struct {
+	driver     Driver
+	log        *log.Helper
+	middleware middleware.Middleware
+	attempts   int
+	backoff    time.Duration
+	deadLetter func(ctx context.Context, msg *Message, err error) error
+	handlers   map[string]Handler
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewServer creates a broker server by options.
+func NewServer(driver Driver, opts ...ServerOption) *Server {
+	srv := &Server{
+		driver:   driver,
+		log:      log.NewHelper(log.DefaultLogger),
+		attempts: 1,
+		handlers: make(map[string]Handler),
+	}
+	for _, o := range opts {
+		o(srv)
+	}
+	return srv
+}
+
+// Handle registers the handler of the topic, it must be called before Start.
+func (s *Server) Handle(topic string, h Handler) {
+	s.handlers[topic] = h
+}
+
+// Start starts consuming the topics, which blocks until the server is stopped.
+func (s *Server) Start(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	defer close(done)
+	s.mu.Lock()
+	s.cancel, s.done = cancel, done
+	s.mu.Unlock()
+	s.log.Infof("[broker] server consuming %d topics", len(s.handlers))
+	eg, ctx := errgroup.WithContext(ctx)
+	for topic, h := range s.handlers {
+		topic, h := topic, h
+		eg.Go(func() error {
+			return s.driver.Subscribe(ctx, topic, s.handler(topic, h))
+		})
+	}
+	return eg.Wait()
+}
+
+// Stop stops consuming and waits for the handling messages.
+func (s *Server) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	cancel, done := s.cancel, s.done
+	s.mu.Unlock()
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	s.log.Info("[broker] server stopping")
+	return nil
+}
+
+func (s *Server) handler(topic string, h Handler) Handler {
+	next := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, h(ctx, req.(*Message))
+	}
+	if s.middleware != nil {
+		next = s.middleware(next)
+	}
+	return func(ctx context.Context, msg *Message) error {
+		ctx = transport.NewStartContext(ctx, time.Now())
+		ctx = transport.NewContext(ctx, transport.Transport{Kind: transport.KindBroker, Endpoint: topic})
+		ctx = NewServerContext(ctx, ServerInfo{Topic: topic, Message: msg})
+		var err error
+		for i := 0; i < s.attempts; i++ {
+			if i > 0 {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(s.backoff):
+				}
+			}
+			if _, err = next(ctx, msg); err == nil {
+				return nil
+			}
+		}
+		s.log.Errorf("[broker] handle message of %s: %v", topic, err)
+		if s.deadLetter != nil {
+			return s.deadLetter(ctx, msg, err)
+		}
+		return err
+	}
+}
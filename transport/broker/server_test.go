@@ -0,0 +1,128 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// testDriver delivers the messages to the handler and records the acknowledged ones.
+type testDriver struct {
+	messages []*Message
+	acked    chan *Message
+}
+
+func (d *testDriver) Subscribe(ctx context.Context, topic string, h Handler) error {
+	for _, msg := range d.messages {
+		if msg.Topic != topic {
+			continue
+		}
+		if err := h(ctx, msg); err == nil {
+			d.acked <- msg
+		}
+	}
+	<-ctx.Done()
+	return nil
+}
+
+func TestServer(t *testing.T) {
+	d := &testDriver{
+		messages: []*Message{
+			{Topic: "orders", Value: []byte("1")},
+			{Topic: "orders", Value: []byte("fail")},
+		},
+		acked: make(chan *Message, 2),
+	}
+	var operations []string
+	srv := NewServer(d, Retry(2, 0), Middleware(func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			tr, _ := transport.FromContext(ctx)
+			info, _ := FromServerContext(ctx)
+			if tr.Kind != transport.KindBroker {
+				t.Errorf("expected the broker transport got %s", tr.Kind)
+			}
+			operations = append(operations, info.Topic)
+			return handler(ctx, req)
+		}
+	}))
+	srv.Handle("orders", func(ctx context.Context, msg *Message) error {
+		if string(msg.Value) == "fail" {
+			return errors.New("failed")
+		}
+		return nil
+	})
+	done := make(chan error, 1)
+	go func() {
+		done <- srv.Start(context.Background())
+	}()
+	select {
+	case msg := <-d.acked:
+		if string(msg.Value) != "1" {
+			t.Fatalf("unexpected acknowledged message %s", msg.Value)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the message acknowledged")
+	}
+	if err := srv.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if len(d.acked) != 0 {
+		t.Fatal("expected the failed message not acknowledged")
+	}
+	// one attempt of the first message and two of the failed one
+	if len(operations) != 3 {
+		t.Fatalf("expected 3 attempts got %v", operations)
+	}
+}
+
+func TestServerOperation(t *testing.T) {
+	d := &testDriver{
+		messages: []*Message{{Topic: "orders", Value: []byte("1")}},
+		acked:    make(chan *Message, 1),
+	}
+	operation := make(chan string, 1)
+	srv := NewServer(d)
+	srv.Handle("orders", func(ctx context.Context, msg *Message) error {
+		operation <- transport.Operation(ctx)
+		return nil
+	})
+	done := make(chan error, 1)
+	go func() {
+		done <- srv.Start(context.Background())
+	}()
+	select {
+	case op := <-operation:
+		if op != "orders" {
+			t.Errorf("expected the topic as the operation got %q", op)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the message handled")
+	}
+	if err := srv.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRequestHeader(t *testing.T) {
+	ctx := NewServerContext(context.Background(), ServerInfo{
+		Topic:   "orders",
+		Message: &Message{Topic: "orders", Headers: map[string]string{"X-Tenant": "kratos"}},
+	})
+	if v := transport.RequestHeader(ctx, "x-tenant"); v != "kratos" {
+		t.Errorf("expected the case-insensitive header got %q", v)
+	}
+	h, _ := transport.RequestHeaderCarrier(ctx)
+	if keys := h.Keys(); len(keys) != 1 || keys[0] != "x-tenant" {
+		t.Errorf("expected the lower case keys got %v", keys)
+	}
+}
@@ -0,0 +1,91 @@
+package transport
+
+import (
+	"crypto/tls"
+	"path/filepath"
+	"sync/atomic"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// CertReloader is a reloadable certificate store for the TLS servers, which reloads
+// the key pair when the files change, e.g. rotated by cert-manager.
+// The new certificate applies to the new connections only, the existing ones are kept.
+type CertReloader struct {
+	certPath string
+	keyPath  string
+	cert     atomic.Value
+	fw       *fsnotify.Watcher
+	log      *log.Helper
+}
+
+// NewCertReloader loads the key pair and watches the files for changes.
+func NewCertReloader(certPath, keyPath string) (*CertReloader, error) {
+	r := &CertReloader{
+		certPath: certPath,
+		keyPath:  keyPath,
+		log:      log.NewHelper(log.DefaultLogger),
+	}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	fw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	// watch the directories, since the files are usually replaced by symlink swaps
+	for _, dir := range []string{filepath.Dir(certPath), filepath.Dir(keyPath)} {
+		if err := fw.Add(dir); err != nil {
+			fw.Close()
+			return nil, err
+		}
+	}
+	r.fw = fw
+	go r.watch()
+	return r, nil
+}
+
+// Reload reloads the key pair, the last good certificate is kept if it fails.
+func (r *CertReloader) Reload() error {
+	cert, err := tls.LoadX509KeyPair(r.certPath, r.keyPath)
+	if err != nil {
+		return err
+	}
+	r.cert.Store(&cert)
+	return nil
+}
+
+// GetCertificate returns the current certificate, which is used as tls.Config.GetCertificate.
+func (r *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return r.cert.Load().(*tls.Certificate), nil
+}
+
+// TLSConfig returns a TLS config which serves the current certificate.
+func (r *CertReloader) TLSConfig() *tls.Config {
+	return &tls.Config{GetCertificate: r.GetCertificate}
+}
+
+// Close stops watching the files.
+func (r *CertReloader) Close() error {
+	return r.fw.Close()
+}
+
+func (r *CertReloader) watch() {
+	for {
+		select {
+		case _, ok := <-r.fw.Events:
+			if !ok {
+				return
+			}
+			if err := r.Reload(); err != nil {
+				r.log.Errorf("failed to reload certificate %s: %v", r.certPath, err)
+			}
+		case err, ok := <-r.fw.Errors:
+			if !ok {
+				return
+			}
+			r.log.Errorf("failed to watch certificate %s: %v", r.certPath, err)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package transport
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeCert(t *testing.T, dir string, serial int64) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "kratos"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// write the key first, so the key pair is consistent once the cert is written
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+	if err := ioutil.WriteFile(filepath.Join(dir, "tls.key.tmp"), keyPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	if err := ioutil.WriteFile(filepath.Join(dir, "tls.crt.tmp"), certPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(filepath.Join(dir, "tls.key.tmp"), filepath.Join(dir, "tls.key")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(filepath.Join(dir, "tls.crt.tmp"), filepath.Join(dir, "tls.crt")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func serial(t *testing.T, r *CertReloader) int64 {
+	cert, err := r.GetCertificate(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c.SerialNumber.Int64()
+}
+
+func TestCertReloader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kratos-cert")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	writeCert(t, dir, 1)
+
+	r, err := NewCertReloader(filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if s := serial(t, r); s != 1 {
+		t.Fatalf("expected serial 1 got %d", s)
+	}
+
+	writeCert(t, dir, 2)
+	for i := 0; i < 20 && serial(t, r) != 2; i++ {
+		time.Sleep(100 * time.Millisecond)
+	}
+	if s := serial(t, r); s != 2 {
+		t.Fatalf("expected serial 2 got %d", s)
+	}
+
+	// the last good certificate is kept
+	if err := ioutil.WriteFile(filepath.Join(dir, "tls.crt"), []byte("invalid"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(200 * time.Millisecond)
+	if s := serial(t, r); s != 2 {
+		t.Fatalf("expected serial 2 got %d", s)
+	}
+}
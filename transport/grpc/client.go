@@ -2,8 +2,12 @@ package grpc
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"time"
 
+	ir "github.com/go-kratos/kratos/v2/internal/reply"
+	"github.com/go-kratos/kratos/v2/log"
 	"github.com/go-kratos/kratos/v2/middleware"
 	"github.com/go-kratos/kratos/v2/registry"
 	"github.com/go-kratos/kratos/v2/transport"
@@ -14,6 +18,10 @@ import (
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/balancer/roundrobin"
+	"google.golang.org/grpc/connectivity"
+	// init health check client
+	_ "google.golang.org/grpc/health"
+	"google.golang.org/grpc/peer"
 )
 
 // ClientOption is gRPC client option.
@@ -47,6 +55,17 @@ func WithDiscovery(d registry.Discovery) ClientOption {
 	}
 }
 
+// WithResolver with client discovery for the target scheme, e.g. k8s:///user,
+// which resolves the target by the discovery like discovery:///user.
+func WithResolver(scheme string, d registry.Discovery) ClientOption {
+	return func(o *clientOptions) {
+		if o.resolvers == nil {
+			o.resolvers = make(map[string]registry.Discovery)
+		}
+		o.resolvers[scheme] = d
+	}
+}
+
 // WithUnaryInterceptor returns a DialOption that specifies the interceptor for unary RPCs.
 func WithUnaryInterceptor(in ...grpc.UnaryClientInterceptor) ClientOption {
 	return func(o *clientOptions) {
@@ -54,6 +73,70 @@ func WithUnaryInterceptor(in ...grpc.UnaryClientInterceptor) ClientOption {
 	}
 }
 
+// WithHealthCheck with client health check, the backends which report NOT_SERVING
+// via the gRPC health service are removed from the balancer until they recover.
+func WithHealthCheck(enable bool) ClientOption {
+	return func(o *clientOptions) {
+		o.healthCheck = enable
+	}
+}
+
+// WithWaitForReady with the wait-for-ready semantics of all calls, the calls
+// block until the connection is ready or the call timeout exceeded,
+// instead of failing immediately when the backends are reconnecting.
+func WithWaitForReady(wait bool) ClientOption {
+	return func(o *clientOptions) {
+		o.waitForReady = wait
+	}
+}
+
+// WithCodec with the codec name of all calls, which is sent as the content-subtype,
+// e.g. application/grpc+vtproto. The codec must be registered by the
+// google.golang.org/grpc/encoding.RegisterCodec on both sides, and the server picks
+// the codec of each call by the name, so the other services are not affected.
+// A single call can override it with the grpc.CallContentSubtype call option.
+func WithCodec(name string) ClientOption {
+	return func(o *clientOptions) {
+		o.codec = name
+	}
+}
+
+// WithInitialWindowSize with the initial window size of each stream, which controls
+// the flow of the server streaming, see the InitialWindowSize of the server.
+func WithInitialWindowSize(n int32) ClientOption {
+	return func(o *clientOptions) {
+		o.windowSize = n
+	}
+}
+
+// WithInitialConnWindowSize with the initial window size of each connection,
+// see the InitialConnWindowSize of the server.
+func WithInitialConnWindowSize(n int32) ClientOption {
+	return func(o *clientOptions) {
+		o.connWindowSize = n
+	}
+}
+
+// WithWarmup with the warmup of the connections to the resolved backends in the background
+// after dial, which waits up to the timeout for the connection to be ready, so the first
+// request does not pay the cost of the connection establishment. It never blocks the dial.
+func WithWarmup(timeout time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		o.warmup = timeout
+	}
+}
+
+// WithPin with the backend address to pin all calls to, e.g. 10.0.0.1:9000 from the discovery,
+// which bypasses the balancer to reproduce the bugs of a single instance. A call can pin
+// another address by NewPinContext, which requires this option, the empty address pins
+// the calls of NewPinContext only. The calls fail if the address is not discovered.
+func WithPin(addr string) ClientOption {
+	return func(o *clientOptions) {
+		o.pinning = true
+		o.pin = addr
+	}
+}
+
 // WithOptions with gRPC options.
 func WithOptions(opts ...grpc.DialOption) ClientOption {
 	return func(o *clientOptions) {
@@ -61,14 +144,41 @@ func WithOptions(opts ...grpc.DialOption) ClientOption {
 	}
 }
 
+// serviceConfig returns the default service config of the options, which enables the
+// client side health check of the standard health service, and the pin balancer.
+func serviceConfig(o clientOptions) string {
+	config := make(map[string]interface{})
+	if o.healthCheck {
+		config["healthCheckConfig"] = map[string]string{"serviceName": ""}
+	}
+	if o.pinning {
+		config["loadBalancingConfig"] = []map[string]interface{}{{pinName: struct{}{}}}
+	}
+	if len(config) == 0 {
+		return ""
+	}
+	data, _ := json.Marshal(config)
+	return string(data)
+}
+
 // clientOptions is gRPC Client
 type clientOptions struct {
-	endpoint   string
-	timeout    time.Duration
-	middleware middleware.Middleware
-	discovery  registry.Discovery
-	ints       []grpc.UnaryClientInterceptor
-	grpcOpts   []grpc.DialOption
+	endpoint     string
+	timeout      time.Duration
+	middleware   middleware.Middleware
+	discovery    registry.Discovery
+	resolvers    map[string]registry.Discovery
+	healthCheck  bool
+	waitForReady bool
+	codec        string
+	ints         []grpc.UnaryClientInterceptor
+	grpcOpts     []grpc.DialOption
+
+	windowSize     int32
+	connWindowSize int32
+	warmup         time.Duration
+	pinning        bool
+	pin            string
 }
 
 // Dial returns a GRPC connection.
@@ -95,19 +205,75 @@ func dial(ctx context.Context, insecure bool, opts ...ClientOption) (*grpc.Clien
 		ints = append(ints, options.ints...)
 	}
 	var grpcOpts = []grpc.DialOption{
-		grpc.WithBalancerName(roundrobin.Name),
 		grpc.WithChainUnaryInterceptor(ints...),
+		grpc.WithChainStreamInterceptor(peerStreamInterceptor()),
+	}
+	if !options.pinning {
+		grpcOpts = append(grpcOpts, grpc.WithBalancerName(roundrobin.Name))
+	}
+	if options.pin != "" {
+		grpcOpts = append(grpcOpts,
+			grpc.WithChainUnaryInterceptor(pinUnaryInterceptor(options.pin)),
+			grpc.WithChainStreamInterceptor(pinStreamInterceptor(options.pin)),
+		)
+	}
+	if options.waitForReady {
+		grpcOpts = append(grpcOpts, grpc.WithDefaultCallOptions(grpc.WaitForReady(true)))
+	}
+	if options.codec != "" {
+		grpcOpts = append(grpcOpts, grpc.WithDefaultCallOptions(grpc.CallContentSubtype(options.codec)))
+	}
+	if options.windowSize > 0 {
+		grpcOpts = append(grpcOpts, grpc.WithInitialWindowSize(options.windowSize))
+	}
+	if options.connWindowSize > 0 {
+		grpcOpts = append(grpcOpts, grpc.WithInitialConnWindowSize(options.connWindowSize))
+	}
+	if config := serviceConfig(options); config != "" {
+		grpcOpts = append(grpcOpts, grpc.WithDefaultServiceConfig(config))
 	}
 	if options.discovery != nil {
 		grpcOpts = append(grpcOpts, grpc.WithResolvers(discovery.NewBuilder(options.discovery)))
 	}
+	for scheme, d := range options.resolvers {
+		grpcOpts = append(grpcOpts, grpc.WithResolvers(discovery.NewBuilder(d, discovery.WithScheme(scheme))))
+	}
 	if insecure {
 		grpcOpts = append(grpcOpts, grpc.WithInsecure())
 	}
 	if len(options.grpcOpts) > 0 {
 		grpcOpts = append(grpcOpts, options.grpcOpts...)
 	}
-	return grpc.DialContext(ctx, options.endpoint, grpcOpts...)
+	conn, err := grpc.DialContext(ctx, options.endpoint, grpcOpts...)
+	if err != nil {
+		return nil, err
+	}
+	if options.warmup > 0 {
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), options.warmup)
+			defer cancel()
+			if err := Warmup(ctx, conn); err != nil {
+				log.NewHelper(log.DefaultLogger).Warnf("[gRPC] client warmup of %s: %v", options.endpoint, err)
+			}
+		}()
+	}
+	return conn, nil
+}
+
+// Warmup connects and waits for the connection to be ready until ctx is done.
+func Warmup(ctx context.Context, conn *grpc.ClientConn) error {
+	for {
+		state := conn.GetState()
+		switch state {
+		case connectivity.Ready:
+			return nil
+		case connectivity.Shutdown:
+			return fmt.Errorf("connection is closed")
+		}
+		if !conn.WaitForStateChange(ctx, state) {
+			return ctx.Err()
+		}
+	}
 }
 
 func unaryClientInterceptor(m middleware.Middleware, timeout time.Duration) grpc.UnaryClientInterceptor {
@@ -119,13 +285,42 @@ func unaryClientInterceptor(m middleware.Middleware, timeout time.Duration) grpc
 			ctx, cancel = context.WithTimeout(ctx, timeout)
 			defer cancel()
 		}
+		p, ok := PeerFromContext(ctx)
+		if !ok {
+			ctx, p = NewPeerContext(ctx)
+		}
 		h := func(ctx context.Context, req interface{}) (interface{}, error) {
-			return reply, invoker(ctx, method, req, reply, cc, opts...)
+			var pr peer.Peer
+			err := invoker(ctx, method, req, reply, cc, append(opts, grpc.Peer(&pr))...)
+			if pr.Addr != nil {
+				p.Addr = pr.Addr.String()
+			}
+			return reply, err
 		}
 		if m != nil {
 			h = m(h)
 		}
-		_, err := h(ctx, req)
-		return err
+		out, err := h(ctx, req)
+		if err != nil {
+			return err
+		}
+		return ir.Set(reply, out)
+	}
+}
+
+// peerStreamInterceptor populates the Peer seeded by NewPeerContext with the backend
+// of the stream once it is established.
+func peerStreamInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			return nil, err
+		}
+		if p, ok := PeerFromContext(ctx); ok {
+			if pr, ok := peer.FromContext(cs.Context()); ok && pr.Addr != nil {
+				p.Addr = pr.Addr.String()
+			}
+		}
+		return cs, nil
 	}
 }
@@ -0,0 +1,215 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/middleware"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestWaitForReady(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := lis.Addr().String()
+	lis.Close()
+
+	conn, err := DialInsecure(context.Background(),
+		WithEndpoint(addr),
+		WithTimeout(5*time.Second),
+		WithWaitForReady(true),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	// the backend comes up after the call starts
+	srv := NewServer(Address(addr))
+	time.AfterFunc(500*time.Millisecond, func() {
+		if err := srv.Start(context.Background()); err != nil {
+			t.Error(err)
+		}
+	})
+	defer srv.Stop(context.Background())
+
+	client := grpc_health_v1.NewHealthClient(conn)
+	if _, err := client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWarmup(t *testing.T) {
+	srv := NewServer(Address("127.0.0.1:0"))
+	if _, err := srv.Endpoint(); err != nil {
+		t.Fatal(err)
+	}
+	go srv.Start(context.Background())
+	defer srv.Stop(context.Background())
+
+	conn, err := DialInsecure(context.Background(),
+		WithEndpoint(srv.lis.Addr().String()),
+		WithWarmup(time.Second),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	// the connection is ready before the first call
+	deadline := time.Now().Add(time.Second)
+	for conn.GetState() != connectivity.Ready {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the connection warmed up got %s", conn.GetState())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	client := grpc_health_v1.NewHealthClient(conn)
+	if _, err := client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPeer(t *testing.T) {
+	srv := NewServer(Address("127.0.0.1:0"))
+	srv.RegisterService(&metadataDesc, struct{}{})
+	srv.RegisterService(&pullDesc, struct{}{})
+	if _, err := srv.Endpoint(); err != nil {
+		t.Fatal(err)
+	}
+	go srv.Start(context.Background())
+	defer srv.Stop(context.Background())
+	addr := srv.lis.Addr().String()
+
+	var seen string
+	conn, err := DialInsecure(context.Background(),
+		WithEndpoint(addr),
+		WithMiddleware(func(handler middleware.Handler) middleware.Handler {
+			return func(ctx context.Context, req interface{}) (interface{}, error) {
+				reply, err := handler(ctx, req)
+				if p, ok := PeerFromContext(ctx); ok {
+					seen = p.Addr
+				}
+				return reply, err
+			}
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	tests := []struct {
+		name   string
+		seeded bool
+		stream bool
+	}{
+		{"unary", false, false},
+		{"unary seeded", true, false},
+		{"stream seeded", true, true},
+	}
+	for _, test := range tests {
+		seen = ""
+		ctx := context.Background()
+		var p *Peer
+		if test.seeded {
+			ctx, p = NewPeerContext(ctx)
+		}
+		if test.stream {
+			stream, err := conn.NewStream(ctx, &pullDesc.Streams[0], "/test.Stream/Pull")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := stream.SendMsg(wrapperspb.Int32(0)); err != nil {
+				t.Fatal(err)
+			}
+			stream.CloseSend()
+		} else {
+			if err := conn.Invoke(ctx, "/test.Metadata/Set", wrapperspb.String("in"), new(wrapperspb.StringValue)); err != nil {
+				t.Fatal(err)
+			}
+			if seen != addr {
+				t.Errorf("%s: expected the middleware seen %s got %q", test.name, addr, seen)
+			}
+		}
+		if test.seeded && p.Addr != addr {
+			t.Errorf("%s: expected the peer %s got %q", test.name, addr, p.Addr)
+		}
+	}
+}
+
+func TestHealthCheck(t *testing.T) {
+	for _, enable := range []bool{true, false} {
+		srv := NewServer(Address("127.0.0.1:0"))
+		srv.RegisterService(&metadataDesc, struct{}{})
+		if _, err := srv.Endpoint(); err != nil {
+			t.Fatal(err)
+		}
+		go srv.Start(context.Background())
+
+		conn, err := DialInsecure(context.Background(),
+			WithEndpoint(srv.lis.Addr().String()),
+			WithHealthCheck(enable),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		invoke := func() error {
+			ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+			defer cancel()
+			return conn.Invoke(ctx, "/test.Metadata/Set", wrapperspb.String("in"), new(wrapperspb.StringValue))
+		}
+		// eventually waits for the health check to update the balancer
+		eventually := func(ok bool) bool {
+			for i := 0; i < 20; i++ {
+				if (invoke() == nil) == ok {
+					return true
+				}
+				time.Sleep(100 * time.Millisecond)
+			}
+			return false
+		}
+		if !eventually(true) {
+			t.Fatalf("health check %v: expected the serving backend to be called", enable)
+		}
+		srv.health.SetServingStatus("", grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+		if enable && !eventually(false) {
+			t.Fatal("expected the not serving backend to be removed")
+		}
+		if !enable && invoke() != nil {
+			t.Fatal("expected the not serving backend to be called without the health check")
+		}
+		srv.health.SetServingStatus("", grpc_health_v1.HealthCheckResponse_SERVING)
+		if !eventually(true) {
+			t.Fatalf("health check %v: expected the recovered backend to be called", enable)
+		}
+		conn.Close()
+		srv.Stop(context.Background())
+	}
+}
+
+func TestServiceConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		opts clientOptions
+		want string
+	}{
+		{"default", clientOptions{}, ""},
+		{"health check", clientOptions{healthCheck: true}, `{"healthCheckConfig":{"serviceName":""}}`},
+		{"pin", clientOptions{pinning: true}, `{"loadBalancingConfig":[{"kratos_pin":{}}]}`},
+		{"both", clientOptions{healthCheck: true, pinning: true}, `{"healthCheckConfig":{"serviceName":""},"loadBalancingConfig":[{"kratos_pin":{}}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := serviceConfig(tt.opts); got != tt.want {
+				t.Errorf("expected %s got %s", tt.want, got)
+			}
+		})
+	}
+}
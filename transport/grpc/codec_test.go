@@ -0,0 +1,77 @@
+package grpc
+
+import (
+	"errors"
+	"testing"
+
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// vtMessage is a message with the generated fast path like vtprotobuf.
+type vtMessage struct {
+	*wrapperspb.BytesValue
+}
+
+func (m *vtMessage) MarshalVT() ([]byte, error) {
+	b := make([]byte, 0, 1+protowire.SizeBytes(len(m.Value)))
+	b = protowire.AppendTag(b, 1, protowire.BytesType)
+	return protowire.AppendBytes(b, m.Value), nil
+}
+
+func (m *vtMessage) UnmarshalVT(b []byte) error {
+	num, typ, n := protowire.ConsumeTag(b)
+	if n < 0 || num != 1 || typ != protowire.BytesType {
+		return errors.New("invalid message")
+	}
+	v, n := protowire.ConsumeBytes(b[n:])
+	if n < 0 {
+		return errors.New("invalid message")
+	}
+	m.Value = append(m.Value[:0], v...)
+	return nil
+}
+
+type vtCodec struct{}
+
+func (vtCodec) Marshal(v interface{}) ([]byte, error) {
+	if m, ok := v.(interface{ MarshalVT() ([]byte, error) }); ok {
+		return m.MarshalVT()
+	}
+	return proto.Marshal(v.(proto.Message))
+}
+
+func (vtCodec) Unmarshal(data []byte, v interface{}) error {
+	if m, ok := v.(interface{ UnmarshalVT([]byte) error }); ok {
+		return m.UnmarshalVT(data)
+	}
+	return proto.Unmarshal(data, v.(proto.Message))
+}
+
+func (vtCodec) Name() string {
+	return "vtproto"
+}
+
+// BenchmarkCodec compares the default proto codec with the vtproto codec selected by WithCodec.
+func BenchmarkCodec(b *testing.B) {
+	encoding.RegisterCodec(vtCodec{})
+	for _, name := range []string{"proto", "vtproto"} {
+		codec := encoding.GetCodec(name)
+		b.Run(name, func(b *testing.B) {
+			in := &vtMessage{&wrapperspb.BytesValue{Value: make([]byte, 1024)}}
+			out := &vtMessage{&wrapperspb.BytesValue{}}
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				data, err := codec.Marshal(in)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if err := codec.Unmarshal(data, out); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
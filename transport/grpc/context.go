@@ -2,6 +2,10 @@ package grpc
 
 import (
 	"context"
+
+	"github.com/go-kratos/kratos/v2/transport"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
 )
 
 // ServerInfo represent gRPC server information.
@@ -14,8 +18,16 @@ type ServerInfo struct {
 
 type serverKey struct{}
 
-// NewServerContext returns a new Context that carries value.
+// NewServerContext returns a new Context that carries value, the full method as the operation
+// and the incoming metadata as the request header.
 func NewServerContext(ctx context.Context, info ServerInfo) context.Context {
+	ctx = transport.NewOperationContext(ctx, func(context.Context) string {
+		return info.FullMethod
+	})
+	ctx = transport.NewRequestHeaderContext(ctx, func(ctx context.Context) transport.HeaderCarrier {
+		md, _ := metadata.FromIncomingContext(ctx)
+		return headerCarrier(md)
+	})
 	return context.WithValue(ctx, serverKey{}, info)
 }
 
@@ -25,6 +37,66 @@ func FromServerContext(ctx context.Context) (info ServerInfo, ok bool) {
 	return
 }
 
+type streamKey struct{}
+
+// NewStreamContext returns a new Context that carries the server stream.
+func NewStreamContext(ctx context.Context, ss grpc.ServerStream) context.Context {
+	return context.WithValue(ctx, streamKey{}, ss)
+}
+
+// StreamFromContext returns the server stream stored in ctx, if any.
+// It returns false for the unary and HTTP contexts.
+func StreamFromContext(ctx context.Context) (ss grpc.ServerStream, ok bool) {
+	ss, ok = ctx.Value(streamKey{}).(grpc.ServerStream)
+	return
+}
+
+// wrappedStream is a server stream with the kratos context.
+type wrappedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (w *wrappedStream) Context() context.Context {
+	return w.ctx
+}
+
+// headerCarrier is the incoming metadata of the server request.
+type headerCarrier metadata.MD
+
+func (c headerCarrier) Get(key string) string {
+	if v := metadata.MD(c).Get(key); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+func (c headerCarrier) Values(key string) []string {
+	return metadata.MD(c).Get(key)
+}
+
+func (c headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// header is the response header of the server transport,
+// which is sent as the gRPC header and trailer metadata.
+type header struct {
+	ctx context.Context
+}
+
+func (h header) SetHeader(key, value string) error {
+	return grpc.SetHeader(h.ctx, metadata.Pairs(key, value))
+}
+
+func (h header) SetTrailer(key, value string) error {
+	return grpc.SetTrailer(h.ctx, metadata.Pairs(key, value))
+}
+
 // ClientInfo represent gRPC server information.
 type ClientInfo struct {
 	// FullMethod is the full RPC method string, i.e., /package.service/method.
@@ -43,3 +115,31 @@ func FromClientContext(ctx context.Context) (info ClientInfo, ok bool) {
 	info, ok = ctx.Value(clientKey{}).(ClientInfo)
 	return
 }
+
+// Peer contains the information of the backend selected for a client call.
+type Peer struct {
+	// Addr is the address of the backend which handled the call.
+	Addr string
+}
+
+type peerKey struct{}
+
+// NewPeerContext returns a new Context that carries a Peer, which is populated
+// by the client with the selected backend address after the unary call or once
+// the stream is established. The caller seeds it to read the address, e.g.
+//
+//	ctx, p := grpc.NewPeerContext(ctx)
+//	reply, err := client.SayHello(ctx, req)
+//	log.Infof("served by %s", p.Addr)
+//
+// Without it the client seeds one for the middlewares of the call only.
+func NewPeerContext(ctx context.Context) (context.Context, *Peer) {
+	p := new(Peer)
+	return context.WithValue(ctx, peerKey{}, p), p
+}
+
+// PeerFromContext returns the Peer value stored in ctx, if any.
+func PeerFromContext(ctx context.Context) (p *Peer, ok bool) {
+	p, ok = ctx.Value(peerKey{}).(*Peer)
+	return
+}
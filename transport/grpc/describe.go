@@ -0,0 +1,54 @@
+package grpc
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+var _ transport.Describer = (*Server)(nil)
+
+const recoveryMiddleware = "github.com/go-kratos/kratos/v2/middleware/recovery.Recovery"
+
+// Describe returns the summary of the server, the warnings of the self-check are
+// the missing recovery middleware and the enabled reflection.
+func (s *Server) Describe() transport.Description {
+	d := transport.Description{Kind: transport.KindGRPC}
+	if e, err := s.Endpoint(); err == nil {
+		d.Endpoint = e.String()
+	}
+	for service, info := range s.GetServiceInfo() {
+		if strings.HasPrefix(service, "grpc.") || service == "kratos.api.Metadata" {
+			// the internal services, e.g. the health and the reflection
+			continue
+		}
+		for _, m := range info.Methods {
+			d.Operations = append(d.Operations, "/"+service+"/"+m.Name)
+		}
+	}
+	sort.Strings(d.Operations)
+	d.Middlewares = append(append(d.Middlewares, s.preTimeoutNames...), s.middlewareNames...)
+	recovered := false
+	for _, name := range d.Middlewares {
+		recovered = recovered || name == recoveryMiddleware
+	}
+	if !recovered {
+		d.Warnings = append(d.Warnings, "no recovery middleware, the panics of the handlers crash the server")
+	}
+	if s.reflection {
+		d.Warnings = append(d.Warnings, "the reflection is enabled, which exposes the schema of the services")
+	}
+	return d
+}
+
+func middlewareNames(m []middleware.Middleware) []string {
+	names := make([]string, 0, len(m))
+	for _, mw := range m {
+		if name := middleware.Name(mw); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
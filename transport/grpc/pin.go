@@ -0,0 +1,93 @@
+package grpc
+
+import (
+	"context"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// pinName is the name of the balancer, which is the round robin
+// over the ready backends unless the call is pinned to one of them.
+const pinName = "kratos_pin"
+
+func init() {
+	balancer.Register(base.NewBalancerBuilder(pinName, &pinPickerBuilder{}, base.Config{HealthCheck: true}))
+}
+
+type pinKey struct{}
+
+// NewPinContext returns a new Context that pins the calls to the backend address,
+// e.g. 10.0.0.1:9000 from the discovery, bypassing the balancer to debug a single
+// instance. The client must be dialed with WithPin, or the context is ignored.
+// The calls fail with Unavailable if the address is not discovered or not ready.
+func NewPinContext(ctx context.Context, addr string) context.Context {
+	return context.WithValue(ctx, pinKey{}, addr)
+}
+
+// PinFromContext returns the pinned backend address stored in ctx, if any.
+func PinFromContext(ctx context.Context) (addr string, ok bool) {
+	addr, ok = ctx.Value(pinKey{}).(string)
+	return
+}
+
+// pinUnaryInterceptor pins the unary calls without a pinned address in the context.
+func pinUnaryInterceptor(addr string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if _, ok := PinFromContext(ctx); !ok {
+			ctx = NewPinContext(ctx, addr)
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// pinStreamInterceptor pins the streaming calls without a pinned address in the context.
+func pinStreamInterceptor(addr string) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		if _, ok := PinFromContext(ctx); !ok {
+			ctx = NewPinContext(ctx, addr)
+		}
+		return streamer(ctx, desc, cc, method, opts...)
+	}
+}
+
+type pinPickerBuilder struct{}
+
+func (*pinPickerBuilder) Build(info base.PickerBuildInfo) balancer.Picker {
+	if len(info.ReadySCs) == 0 {
+		return base.NewErrPicker(balancer.ErrNoSubConnAvailable)
+	}
+	p := &pinPicker{addrs: make(map[string]balancer.SubConn, len(info.ReadySCs))}
+	for sc, sci := range info.ReadySCs {
+		p.subConns = append(p.subConns, sc)
+		p.addrs[sci.Address.Addr] = sc
+	}
+	return p
+}
+
+type pinPicker struct {
+	subConns []balancer.SubConn
+	addrs    map[string]balancer.SubConn
+
+	mu   sync.Mutex
+	next int
+}
+
+func (p *pinPicker) Pick(info balancer.PickInfo) (balancer.PickResult, error) {
+	if addr, ok := PinFromContext(info.Ctx); ok {
+		sc, ok := p.addrs[addr]
+		if !ok {
+			return balancer.PickResult{}, status.Errorf(codes.Unavailable, "pinned instance %s is not discovered or not ready", addr)
+		}
+		return balancer.PickResult{SubConn: sc}, nil
+	}
+	p.mu.Lock()
+	sc := p.subConns[p.next]
+	p.next = (p.next + 1) % len(p.subConns)
+	p.mu.Unlock()
+	return balancer.PickResult{SubConn: sc}, nil
+}
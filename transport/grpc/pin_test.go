@@ -0,0 +1,49 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/status"
+)
+
+type testSubConn struct {
+	balancer.SubConn
+	addr string
+}
+
+func TestPinPicker(t *testing.T) {
+	a, b := &testSubConn{addr: "127.0.0.1:9000"}, &testSubConn{addr: "127.0.0.1:9001"}
+	p := (&pinPickerBuilder{}).Build(base.PickerBuildInfo{ReadySCs: map[balancer.SubConn]base.SubConnInfo{
+		a: {Address: resolver.Address{Addr: a.addr}},
+		b: {Address: resolver.Address{Addr: b.addr}},
+	}})
+	picked := make(map[balancer.SubConn]int)
+	for i := 0; i < 4; i++ {
+		res, err := p.Pick(balancer.PickInfo{Ctx: context.Background()})
+		if err != nil {
+			t.Fatal(err)
+		}
+		picked[res.SubConn]++
+	}
+	if picked[a] != 2 || picked[b] != 2 {
+		t.Errorf("expected round robin got %v", picked)
+	}
+	for i := 0; i < 2; i++ {
+		res, err := p.Pick(balancer.PickInfo{Ctx: NewPinContext(context.Background(), b.addr)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.SubConn != b {
+			t.Errorf("expected the pinned %s got %s", b.addr, res.SubConn.(*testSubConn).addr)
+		}
+	}
+	_, err := p.Pick(balancer.PickInfo{Ctx: NewPinContext(context.Background(), "127.0.0.1:9002")})
+	if status.Code(err) != codes.Unavailable {
+		t.Errorf("expected Unavailable got %v", err)
+	}
+}
@@ -0,0 +1,8 @@
+//go:build noreflection
+// +build noreflection
+
+package grpc
+
+func init() {
+	DefaultReflection = false
+}
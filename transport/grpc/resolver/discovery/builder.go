@@ -2,8 +2,12 @@ package discovery
 
 import (
 	"context"
+	"net/url"
+	"strings"
+	"time"
 
 	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/metrics"
 	"github.com/go-kratos/kratos/v2/registry"
 	"google.golang.org/grpc/resolver"
 )
@@ -20,9 +24,69 @@ func WithLogger(logger log.Logger) Option {
 	}
 }
 
+// WithScheme with builder scheme, the default is discovery.
+func WithScheme(scheme string) Option {
+	return func(o *builder) {
+		o.scheme = scheme
+	}
+}
+
+// WithStaleTTL with the max time to keep the last known instances while the discovery
+// is unavailable, the default zero keeps them until the discovery recovers.
+func WithStaleTTL(ttl time.Duration) Option {
+	return func(o *builder) {
+		o.staleTTL = ttl
+	}
+}
+
+// WithBackoff with the initial backoff of watching the service again after the watch fails,
+// which doubles on the consecutive failures up to 30 seconds, the default is 1 second.
+func WithBackoff(backoff time.Duration) Option {
+	return func(o *builder) {
+		o.backoff = backoff
+	}
+}
+
+// WithFailures with the failures counter of the discovery, which counts by service name.
+func WithFailures(c metrics.Counter) Option {
+	return func(o *builder) {
+		o.failures = c
+	}
+}
+
+// WithInstances with the instances gauge, which is the number of the resolved instances by service name.
+func WithInstances(g metrics.Gauge) Option {
+	return func(o *builder) {
+		o.instances = g
+	}
+}
+
+// WithChanges with the membership changes counter, which counts by service name and the
+// event of add or remove, e.g. to alert on all instances gone along with the instances gauge.
+func WithChanges(c metrics.Counter) Option {
+	return func(o *builder) {
+		o.changes = c
+	}
+}
+
+// WithRefreshed with the refreshed gauge, which is the unix time of the last successful refresh
+// by service name, e.g. time() - refreshed in Prometheus alerts on the stale discovery.
+func WithRefreshed(g metrics.Gauge) Option {
+	return func(o *builder) {
+		o.refreshed = g
+	}
+}
+
 type builder struct {
 	discoverer registry.Discovery
 	logger     log.Logger
+	scheme     string
+	staleTTL   time.Duration
+	backoff    time.Duration
+	failures   metrics.Counter
+	instances  metrics.Gauge
+	changes    metrics.Counter
+	refreshed  metrics.Gauge
 }
 
 // NewBuilder creates a builder which is used to factory registry resolvers.
@@ -30,6 +94,7 @@ func NewBuilder(d registry.Discovery, opts ...Option) resolver.Builder {
 	b := &builder{
 		discoverer: d,
 		logger:     log.DefaultLogger,
+		scheme:     name,
 	}
 	for _, o := range opts {
 		o(b)
@@ -37,15 +102,34 @@ func NewBuilder(d registry.Discovery, opts ...Option) resolver.Builder {
 	return b
 }
 
+// Build creates a resolver for the target, the target endpoint is the service name
+// with an optional query to filter the instances by version and metadata.
+// example:
+//   discovery:///user?version=v1&tag=canary
 func (d *builder) Build(target resolver.Target, cc resolver.ClientConn, opts resolver.BuildOptions) (resolver.Resolver, error) {
-	w, err := d.discoverer.Watch(context.Background(), target.Endpoint)
+	name, filter, err := parseTarget(target.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	w, err := d.discoverer.Watch(context.Background(), name)
 	if err != nil {
 		return nil, err
 	}
 	ctx, cancel := context.WithCancel(context.Background())
 	r := &discoveryResolver{
-		w:      w,
-		cc:     cc,
+		discoverer: d.discoverer,
+		w:          w,
+		cc:         cc,
+		name:       name,
+		filter:     filter,
+		staleTTL:   d.staleTTL,
+		backoff:    d.backoff,
+		failures:   d.failures,
+		metrics: resolverMetrics{
+			instances: d.instances,
+			changes:   d.changes,
+			refreshed: d.refreshed,
+		},
 		ctx:    ctx,
 		cancel: cancel,
 		log:    log.NewHelper(d.logger),
@@ -55,5 +139,17 @@ func (d *builder) Build(target resolver.Target, cc resolver.ClientConn, opts res
 }
 
 func (d *builder) Scheme() string {
-	return name
+	return d.scheme
+}
+
+func parseTarget(endpoint string) (string, url.Values, error) {
+	parts := strings.SplitN(endpoint, "?", 2)
+	if len(parts) == 1 {
+		return parts[0], nil, nil
+	}
+	filter, err := url.ParseQuery(parts[1])
+	if err != nil {
+		return "", nil, err
+	}
+	return parts[0], filter, nil
 }
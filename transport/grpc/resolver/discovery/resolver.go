@@ -3,24 +3,50 @@ package discovery
 import (
 	"context"
 	"net/url"
+	"sync"
 	"time"
 
 	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/metrics"
 	"github.com/go-kratos/kratos/v2/registry"
 	"google.golang.org/grpc/attributes"
 	"google.golang.org/grpc/resolver"
 )
 
 type discoveryResolver struct {
-	w   registry.Watcher
-	cc  resolver.ClientConn
-	log *log.Helper
+	discoverer registry.Discovery
+	cc         resolver.ClientConn
+	log        *log.Helper
+	name       string
+	filter     url.Values
+
+	// the last known instances are kept while the discovery is unavailable,
+	// until the staleTTL is exceeded if it is set.
+	staleTTL time.Duration
+	failures metrics.Counter
+	// the initial backoff of reconnecting the failed watch, the default is 1 second.
+	backoff time.Duration
+
+	metrics resolverMetrics
+	// the ids of the resolved instances to count the membership changes
+	ids map[string]struct{}
+
+	mu      sync.Mutex
+	w       registry.Watcher
+	updated time.Time
+	expired bool
+	// timer expires the instances after the staleTTL since the last update.
+	timer *time.Timer
 
 	ctx    context.Context
 	cancel context.CancelFunc
 }
 
+// maxBackoff is the max backoff of reconnecting the failed watch.
+const maxBackoff = 30 * time.Second
+
 func (r *discoveryResolver) watch() {
+	var failures int
 	for {
 		select {
 		case <-r.ctx.Done():
@@ -28,19 +54,119 @@ func (r *discoveryResolver) watch() {
 		default:
 		}
 
-		ins, err := r.w.Next()
-		if err != nil {
-			r.log.Errorf("Failed to watch discovery endpoint: %v", err)
-			time.Sleep(time.Second)
+		ins, err := r.watcher().Next()
+		if err == nil {
+			if failures > 0 {
+				r.log.Infof("Discovery watch of %s recovered after %d attempts", r.name, failures)
+				failures = 0
+			}
+			r.refresh(ins)
 			continue
 		}
-		r.update(ins)
+		// keeps serving the last known instances while watching the service again
+		for err != nil {
+			failures++
+			r.log.Errorf("Failed to watch discovery endpoint: %v", err)
+			if r.failures != nil {
+				r.failures.With(r.name).Inc()
+			}
+			backoff := r.reconnectBackoff(failures)
+			r.log.Warnf("Reconnecting discovery watch of %s in %s, attempt %d", r.name, backoff, failures)
+			select {
+			case <-r.ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			err = r.rewatch()
+		}
+	}
+}
+
+func (r *discoveryResolver) watcher() registry.Watcher {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.w
+}
+
+// rewatch replaces the failed watcher by a new watch of the service from the discovery.
+func (r *discoveryResolver) rewatch() error {
+	w, err := r.discoverer.Watch(r.ctx, r.name)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	if err = r.ctx.Err(); err != nil {
+		r.mu.Unlock()
+		w.Stop()
+		return err
+	}
+	failed := r.w
+	r.w = w
+	r.mu.Unlock()
+	if err := failed.Stop(); err != nil {
+		r.log.Errorf("Failed to stop discovery watcher: %v", err)
+	}
+	return nil
+}
+
+// reconnectBackoff returns the exponential backoff of the consecutive failures.
+func (r *discoveryResolver) reconnectBackoff(failures int) time.Duration {
+	backoff := r.backoff
+	if backoff <= 0 {
+		backoff = time.Second
 	}
+	for i := 1; i < failures && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+	return backoff
+}
+
+// refresh updates the instances of a successful watch and restarts the expiration of them.
+func (r *discoveryResolver) refresh(ins []*registry.ServiceInstance) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.updated = time.Now()
+	r.expired = false
+	r.update(ins)
+	if r.metrics.refreshed != nil {
+		r.metrics.refreshed.With(r.name).Set(float64(r.updated.Unix()))
+	}
+	if r.staleTTL <= 0 {
+		return
+	}
+	if r.timer == nil {
+		r.timer = time.AfterFunc(r.staleTTL, r.expire)
+		return
+	}
+	r.timer.Reset(r.staleTTL)
+}
+
+// expire clears the instances once they are not refreshed within the staleTTL,
+// either the discovery fails or the watch hangs.
+func (r *discoveryResolver) expire() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.staleTTL <= 0 || r.expired || r.updated.IsZero() || time.Since(r.updated) < r.staleTTL {
+		return
+	}
+	r.log.Warnf("Discovery endpoints of %s are stale for %s, clear them", r.name, time.Since(r.updated))
+	r.expired = true
+	r.cc.UpdateState(resolver.State{})
+	r.observe(nil)
 }
 
 func (r *discoveryResolver) update(ins []*registry.ServiceInstance) {
-	var addrs []resolver.Address
+	var (
+		addrs []resolver.Address
+		ids   = make(map[string]struct{}, len(ins))
+	)
 	for _, in := range ins {
+		if !match(in, r.filter) {
+			continue
+		}
 		endpoint, err := parseEndpoint(in.Endpoints)
 		if err != nil {
 			r.log.Errorf("Failed to parse discovery endpoint: %v", err)
@@ -55,13 +181,54 @@ func (r *discoveryResolver) update(ins []*registry.ServiceInstance) {
 			Addr:       endpoint,
 		}
 		addrs = append(addrs, addr)
+		ids[in.ID] = struct{}{}
 	}
 	r.cc.UpdateState(resolver.State{Addresses: addrs})
+	r.observe(ids)
+}
+
+type resolverMetrics struct {
+	instances metrics.Gauge
+	changes   metrics.Counter
+	refreshed metrics.Gauge
+}
+
+// observe records the membership changes from the last resolved instances to ids.
+func (r *discoveryResolver) observe(ids map[string]struct{}) {
+	if r.metrics.changes != nil {
+		var added, removed int
+		for id := range ids {
+			if _, ok := r.ids[id]; !ok {
+				added++
+			}
+		}
+		for id := range r.ids {
+			if _, ok := ids[id]; !ok {
+				removed++
+			}
+		}
+		if added > 0 {
+			r.metrics.changes.With(r.name, "add").Add(float64(added))
+		}
+		if removed > 0 {
+			r.metrics.changes.With(r.name, "remove").Add(float64(removed))
+		}
+	}
+	if r.metrics.instances != nil {
+		r.metrics.instances.With(r.name).Set(float64(len(ids)))
+	}
+	r.ids = ids
 }
 
 func (r *discoveryResolver) Close() {
 	r.cancel()
-	r.w.Stop()
+	r.mu.Lock()
+	if r.timer != nil {
+		r.timer.Stop()
+	}
+	w := r.w
+	r.mu.Unlock()
+	w.Stop()
 }
 
 func (r *discoveryResolver) ResolveNow(options resolver.ResolveNowOptions) {}
@@ -79,6 +246,24 @@ func parseEndpoint(endpoints []string) (string, error) {
 	return "", nil
 }
 
+// match reports whether the instance matches the filter,
+// the version key matches the instance version and others match the metadata.
+func match(in *registry.ServiceInstance, filter url.Values) bool {
+	for k := range filter {
+		v := filter.Get(k)
+		if k == "version" {
+			if in.Version != v {
+				return false
+			}
+			continue
+		}
+		if in.Metadata[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
 func parseAttributes(md map[string]string) *attributes.Attributes {
 	pairs := make([]interface{}, 0, len(md))
 	for k, v := range md {
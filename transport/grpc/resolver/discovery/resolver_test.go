@@ -2,10 +2,14 @@ package discovery
 
 import (
 	"context"
+	"errors"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/metrics"
 	"github.com/go-kratos/kratos/v2/registry"
 	"google.golang.org/grpc/resolver"
 )
@@ -58,3 +62,258 @@ func TestWatch(t *testing.T) {
 
 	t.Log("watch goroutine exited after 2 second")
 }
+
+func TestParseTarget(t *testing.T) {
+	name, filter, err := parseTarget("user?version=v1&tag=canary")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "user" {
+		t.Fatalf("expected user got %s", name)
+	}
+	tests := []struct {
+		in    *registry.ServiceInstance
+		match bool
+	}{
+		{&registry.ServiceInstance{Version: "v1", Metadata: map[string]string{"tag": "canary"}}, true},
+		{&registry.ServiceInstance{Version: "v2", Metadata: map[string]string{"tag": "canary"}}, false},
+		{&registry.ServiceInstance{Version: "v1"}, false},
+	}
+	for _, test := range tests {
+		if match(test.in, filter) != test.match {
+			t.Errorf("expected %v got %v", test.match, !test.match)
+		}
+	}
+}
+
+type stateClientConn struct {
+	resolver.ClientConn
+	states []resolver.State
+}
+
+func (c *stateClientConn) UpdateState(s resolver.State) error {
+	c.states = append(c.states, s)
+	return nil
+}
+
+type failedWatch struct{}
+
+func (w *failedWatch) Next() ([]*registry.ServiceInstance, error) {
+	return nil, errors.New("discovery unavailable")
+}
+
+func (w *failedWatch) Stop() error {
+	return nil
+}
+
+// listWatch lists the instances once, then fails if it is dead, or blocks until stopped.
+type listWatch struct {
+	instances []*registry.ServiceInstance
+	dead      bool
+	listed    bool
+	stop      chan struct{}
+}
+
+func (w *listWatch) Next() ([]*registry.ServiceInstance, error) {
+	if !w.listed {
+		w.listed = true
+		return w.instances, nil
+	}
+	if w.dead {
+		return nil, errors.New("registry connection lost")
+	}
+	<-w.stop
+	return nil, errors.New("watcher stopped")
+}
+
+func (w *listWatch) Stop() error {
+	close(w.stop)
+	return nil
+}
+
+// testDiscovery fails the first watches, then watches the instances.
+type testDiscovery struct {
+	mu        sync.Mutex
+	fails     int
+	watches   int
+	instances []*registry.ServiceInstance
+}
+
+func (d *testDiscovery) GetService(ctx context.Context, name string) ([]*registry.ServiceInstance, error) {
+	return d.instances, nil
+}
+
+func (d *testDiscovery) Watch(ctx context.Context, name string) (registry.Watcher, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.watches++
+	if d.watches <= d.fails {
+		return nil, errors.New("discovery unavailable")
+	}
+	return &listWatch{instances: d.instances, stop: make(chan struct{})}, nil
+}
+
+func TestReconnect(t *testing.T) {
+	var (
+		instances = []*registry.ServiceInstance{{ID: "1", Endpoints: []string{"grpc://127.0.0.1:9000"}}}
+		cc        = &stateClientConn{}
+		d         = &testDiscovery{fails: 2, instances: instances}
+		dead      = &listWatch{instances: instances, dead: true, stop: make(chan struct{})}
+		states    = make(chan int)
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &discoveryResolver{
+		discoverer: d,
+		w:          dead,
+		cc:         &notifyClientConn{stateClientConn: cc, states: states},
+		log:        log.NewHelper(log.DefaultLogger),
+		name:       "user",
+		backoff:    time.Millisecond,
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+	go r.watch()
+	defer r.Close()
+	for i := 0; i < 2; i++ {
+		select {
+		case <-states:
+		case <-time.After(time.Second):
+			t.Fatal("expected the instances resolved")
+		}
+	}
+	// the dead watcher is replaced by watching the service again after the failed watches,
+	// and the last known instances are kept during the gap.
+	d.mu.Lock()
+	watches := d.watches
+	d.mu.Unlock()
+	if watches != 3 {
+		t.Fatalf("expected the service watched 3 times got %d", watches)
+	}
+	select {
+	case <-dead.stop:
+	default:
+		t.Fatal("expected the dead watcher stopped")
+	}
+	if len(cc.states) != 2 {
+		t.Fatalf("expected the instances resolved before and after the gap got %v", cc.states)
+	}
+	for _, s := range cc.states {
+		if len(s.Addresses) != 1 {
+			t.Fatalf("expected the instances kept during the gap got %v", cc.states)
+		}
+	}
+}
+
+func TestStaleTTLHang(t *testing.T) {
+	var (
+		cc     = &stateClientConn{}
+		states = make(chan int)
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &discoveryResolver{
+		w:        &listWatch{instances: []*registry.ServiceInstance{{ID: "1", Endpoints: []string{"grpc://127.0.0.1:9000"}}}, stop: make(chan struct{})},
+		cc:       &notifyClientConn{stateClientConn: cc, states: states},
+		log:      log.NewHelper(log.DefaultLogger),
+		staleTTL: 50 * time.Millisecond,
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+	go r.watch()
+	defer r.Close()
+	for i := 0; i < 2; i++ {
+		select {
+		case <-states:
+		case <-time.After(time.Second):
+			t.Fatal("expected the instances resolved and expired")
+		}
+	}
+	// the hanging watch never fails, the instances expire by the staleTTL anyway.
+	if len(cc.states) != 2 || len(cc.states[0].Addresses) != 1 || len(cc.states[1].Addresses) != 0 {
+		t.Fatalf("expected the stale instances cleared got %v", cc.states)
+	}
+}
+
+// notifyClientConn notifies the updates of the state.
+type notifyClientConn struct {
+	*stateClientConn
+	states chan int
+}
+
+func (c *notifyClientConn) UpdateState(s resolver.State) error {
+	err := c.stateClientConn.UpdateState(s)
+	c.states <- len(c.stateClientConn.states)
+	return err
+}
+
+func TestStaleTTL(t *testing.T) {
+	cc := &stateClientConn{}
+	r := &discoveryResolver{
+		w:        &failedWatch{},
+		cc:       cc,
+		log:      log.NewHelper(log.DefaultLogger),
+		staleTTL: time.Minute,
+		updated:  time.Now(),
+	}
+	r.expire()
+	if len(cc.states) != 0 {
+		t.Fatalf("expected the last known instances kept got %v", cc.states)
+	}
+	r.updated = time.Now().Add(-2 * time.Minute)
+	r.expire()
+	r.expire()
+	if len(cc.states) != 1 || len(cc.states[0].Addresses) != 0 {
+		t.Fatalf("expected the stale instances cleared once got %v", cc.states)
+	}
+}
+
+type testMetric struct {
+	lvs    []string
+	values map[string]float64
+}
+
+func (m *testMetric) With(lvs ...string) *testMetric {
+	return &testMetric{lvs: lvs, values: m.values}
+}
+
+func (m *testMetric) key() string {
+	return strings.Join(m.lvs, ",")
+}
+
+type testCounter struct{ *testMetric }
+
+func (c testCounter) With(lvs ...string) metrics.Counter {
+	return testCounter{c.testMetric.With(lvs...)}
+}
+func (c testCounter) Inc()              { c.values[c.key()]++ }
+func (c testCounter) Add(delta float64) { c.values[c.key()] += delta }
+
+type testGauge struct{ *testMetric }
+
+func (g testGauge) With(lvs ...string) metrics.Gauge { return testGauge{g.testMetric.With(lvs...)} }
+func (g testGauge) Set(value float64)                { g.values[g.key()] = value }
+func (g testGauge) Add(delta float64)                { g.values[g.key()] += delta }
+func (g testGauge) Sub(delta float64)                { g.values[g.key()] -= delta }
+
+func TestMembershipMetrics(t *testing.T) {
+	var (
+		changes   = testCounter{&testMetric{values: make(map[string]float64)}}
+		instances = testGauge{&testMetric{values: make(map[string]float64)}}
+	)
+	r := &discoveryResolver{
+		cc:      &stateClientConn{},
+		log:     log.NewHelper(log.DefaultLogger),
+		name:    "user",
+		metrics: resolverMetrics{instances: instances, changes: changes},
+	}
+	instance := func(id string) *registry.ServiceInstance {
+		return &registry.ServiceInstance{ID: id, Endpoints: []string{"grpc://127.0.0.1:900" + id}}
+	}
+	r.update([]*registry.ServiceInstance{instance("1"), instance("2")})
+	r.update([]*registry.ServiceInstance{instance("2"), instance("3")})
+	if v := instances.values["user"]; v != 2 {
+		t.Fatalf("expected 2 instances got %v", v)
+	}
+	if add, remove := changes.values["user,add"], changes.values["user,remove"]; add != 3 || remove != 1 {
+		t.Fatalf("expected 3 added and 1 removed got %v and %v", add, remove)
+	}
+}
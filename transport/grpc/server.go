@@ -2,25 +2,37 @@ package grpc
 
 import (
 	"context"
+	"crypto/tls"
+	"fmt"
 	"net"
 	"net/url"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-kratos/kratos/v2/api/metadata"
+	"github.com/go-kratos/kratos/v2/errors"
 	ic "github.com/go-kratos/kratos/v2/internal/context"
 	"github.com/go-kratos/kratos/v2/internal/host"
 	"github.com/go-kratos/kratos/v2/log"
 	"github.com/go-kratos/kratos/v2/middleware"
 	"github.com/go-kratos/kratos/v2/transport"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/health"
 	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
 	"google.golang.org/grpc/reflection"
 )
 
 var _ transport.Server = (*Server)(nil)
 var _ transport.Endpointer = (*Server)(nil)
+var _ transport.Drainer = (*Server)(nil)
+
+// DefaultReflection is the default of the Reflection option, it is true for
+// backward compatibility, and false when built with the noreflection tag.
+var DefaultReflection = true
 
 // ServerOption is gRPC server option.
 type ServerOption func(o *Server)
@@ -39,6 +51,16 @@ func Address(addr string) ServerOption {
 	}
 }
 
+// Endpoint with the advertised endpoint to the registry, e.g. grpc://10.0.0.1:9000, instead of
+// the one extracted from the address, which is useful when the server is behind the NAT
+// or in a container and the bind address is not routable.
+func Endpoint(endpoint *url.URL) ServerOption {
+	return func(s *Server) {
+		s.endpoint = endpoint
+		s.advertised = true
+	}
+}
+
 // Timeout with server timeout.
 func Timeout(timeout time.Duration) ServerOption {
 	return func(s *Server) {
@@ -57,6 +79,141 @@ func Logger(logger log.Logger) ServerOption {
 func Middleware(m ...middleware.Middleware) ServerOption {
 	return func(s *Server) {
 		s.middleware = middleware.Chain(m...)
+		s.middlewareNames = middlewareNames(m)
+	}
+}
+
+// PreTimeoutMiddleware with server middleware which runs before the server timeout is applied,
+// it is useful for cheap rejections such as rate limit and auth.
+func PreTimeoutMiddleware(m ...middleware.Middleware) ServerOption {
+	return func(s *Server) {
+		s.preTimeout = middleware.Chain(m...)
+		s.preTimeoutNames = middlewareNames(m)
+	}
+}
+
+// HealthFromLoad with the load signal of the health service, the server reports NOT_SERVING
+// after overloaded returns true for several consecutive checks, and recovers the same way.
+func HealthFromLoad(overloaded func() bool, interval time.Duration) ServerOption {
+	return func(s *Server) {
+		s.overloaded = overloaded
+		s.loadInterval = interval
+	}
+}
+
+// HealthServiceNames with the service names reported as SERVING by the health service
+// besides the empty one, e.g. the names health-checked by the load balancers, which
+// follow the serving status of the server on the start, the overload and the shutdown.
+func HealthServiceNames(names ...string) ServerOption {
+	return func(s *Server) {
+		s.healthNames = names
+	}
+}
+
+// HealthDefaultStatus with the serving status of the empty service name on the start,
+// the default is SERVING, e.g. NOT_SERVING for the load balancers which health-check
+// the named services only, so the default is not mistaken for them.
+func HealthDefaultStatus(status grpc_health_v1.HealthCheckResponse_ServingStatus) ServerOption {
+	return func(s *Server) {
+		s.healthDefault = status
+	}
+}
+
+// MaxConcurrentStreams with the max number of concurrent streams of each client connection,
+// the streams above the limit are queued by the client instead of failing.
+func MaxConcurrentStreams(n uint32) ServerOption {
+	return func(s *Server) {
+		s.maxStreams = n
+	}
+}
+
+// MaxConnectionAge with the max age of a connection, after which the server sends a GOAWAY
+// to make the client reconnect and re-resolve the endpoints, the default zero means infinity.
+func MaxConnectionAge(d time.Duration) ServerOption {
+	return func(s *Server) {
+		s.keepalive.MaxConnectionAge = d
+	}
+}
+
+// MaxConnectionAgeGrace with the grace period for the pending RPCs to complete after the
+// max connection age, before the connection is forcibly closed, the default zero means infinity.
+func MaxConnectionAgeGrace(d time.Duration) ServerOption {
+	return func(s *Server) {
+		s.keepalive.MaxConnectionAgeGrace = d
+	}
+}
+
+// MaxConnectionIdle with the max idle time of a connection without any RPC,
+// after which the connection is closed by a GOAWAY, the default zero means infinity.
+func MaxConnectionIdle(d time.Duration) ServerOption {
+	return func(s *Server) {
+		s.keepalive.MaxConnectionIdle = d
+	}
+}
+
+// InitialWindowSize with the initial window size of each stream, the lower bound is 64KB.
+// Setting it disables the dynamic window based on the BDP estimation, so it should be larger
+// than the bandwidth-delay product, e.g. 1MB for the high volume streams over a long RTT.
+// Each stream may buffer up to the window in memory, so the safe range is 64KB to 16MB.
+// The window of the receiver controls the flow, for the server streaming the client should
+// be configured with the same window via WithInitialWindowSize.
+func InitialWindowSize(n int32) ServerOption {
+	return func(s *Server) {
+		s.windowSize = n
+	}
+}
+
+// InitialConnWindowSize with the initial window size of each connection shared by its streams,
+// which should be no less than the stream window multiplied by the concurrent streams.
+func InitialConnWindowSize(n int32) ServerOption {
+	return func(s *Server) {
+		s.connWindowSize = n
+	}
+}
+
+// LameDuckDuration with the lame duck period on stop before the graceful stop, during which
+// the health service reports NOT_SERVING and the new requests fail with Unavailable, so the
+// clients retry on other instances while the in-flight requests finish.
+// It is more aggressive than the drain, for the load balancers which keep sending requests
+// until the health checks fail, and should be longer than their health check interval.
+func LameDuckDuration(d time.Duration) ServerOption {
+	return func(s *Server) {
+		s.lameDuck = d
+	}
+}
+
+// TLSConfig with the TLS config of the server, use transport.CertReloader
+// to reload the certificate without restart.
+func TLSConfig(c *tls.Config) ServerOption {
+	return func(s *Server) {
+		s.tlsConf = c
+	}
+}
+
+// Reflection with the gRPC server reflection service, the default is DefaultReflection.
+// The reflection exposes the full schema of the services to any client, which helps
+// the tools like grpcurl but also the reconnaissance, so disable it in production
+// unless the port is only reachable internally.
+func Reflection(enabled bool) ServerOption {
+	return func(s *Server) {
+		s.reflection = enabled
+	}
+}
+
+// AllowMethods with the methods allowed to call, the others fail with Unimplemented,
+// e.g. /helloworld.Greeter/SayHello, or /helloworld.Greeter/ for all the methods of the service.
+// The health service is always allowed.
+func AllowMethods(methods ...string) ServerOption {
+	return func(s *Server) {
+		s.allow = append(s.allow, methods...)
+	}
+}
+
+// DenyMethods with the methods denied to call, which fail with PermissionDenied,
+// e.g. the admin methods of a public endpoint, the denylist wins over the allowlist.
+func DenyMethods(methods ...string) ServerOption {
+	return func(s *Server) {
+		s.deny = append(s.deny, methods...)
 	}
 }
 
@@ -67,6 +224,15 @@ func UnaryInterceptor(in ...grpc.UnaryServerInterceptor) ServerOption {
 	}
 }
 
+// StreamInterceptor returns a ServerOption that sets the StreamServerInterceptor for the server,
+// which is chained after the built-in one, so the stream context carries the transport,
+// e.g. the Stream of the recovery middleware.
+func StreamInterceptor(in ...grpc.StreamServerInterceptor) ServerOption {
+	return func(s *Server) {
+		s.streamInts = in
+	}
+}
+
 // Options with grpc options.
 func Options(opts ...grpc.ServerOption) ServerOption {
 	return func(s *Server) {
@@ -84,13 +250,43 @@ type Server struct {
 	network    string
 	address    string
 	endpoint   *url.URL
+	advertised bool
 	timeout    time.Duration
 	log        *log.Helper
 	middleware middleware.Middleware
+	preTimeout middleware.Middleware
 	ints       []grpc.UnaryServerInterceptor
+	streamInts []grpc.StreamServerInterceptor
 	grpcOpts   []grpc.ServerOption
 	health     *health.Server
 	metadata   *metadata.Server
+
+	healthNames   []string
+	healthDefault grpc_health_v1.HealthCheckResponse_ServingStatus
+
+	overloaded   func() bool
+	loadInterval time.Duration
+	maxStreams   uint32
+	keepalive    keepalive.ServerParameters
+
+	windowSize     int32
+	connWindowSize int32
+	tlsConf        *tls.Config
+	reflection     bool
+
+	lameDuck    time.Duration
+	lameDucking int32
+
+	allow []string
+	deny  []string
+
+	middlewareNames []string
+	preTimeoutNames []string
+
+	// mu guards the listener and the endpoint which are replaced by Migrate.
+	mu      sync.RWMutex
+	served  chan error
+	retired map[net.Listener]struct{}
 }
 
 // NewServer creates a gRPC server by options.
@@ -101,6 +297,10 @@ func NewServer(opts ...ServerOption) *Server {
 		timeout: 1 * time.Second,
 		health:  health.NewServer(),
 		log:     log.NewHelper(log.DefaultLogger),
+
+		healthDefault: grpc_health_v1.HealthCheckResponse_SERVING,
+
+		reflection: DefaultReflection,
 	}
 	for _, o := range opts {
 		o(srv)
@@ -111,18 +311,46 @@ func NewServer(opts ...ServerOption) *Server {
 	if len(srv.ints) > 0 {
 		ints = append(ints, srv.ints...)
 	}
+	var streamInts = []grpc.StreamServerInterceptor{
+		srv.streamServerInterceptor(),
+	}
+	if len(srv.streamInts) > 0 {
+		streamInts = append(streamInts, srv.streamInts...)
+	}
 	var grpcOpts = []grpc.ServerOption{
 		grpc.ChainUnaryInterceptor(ints...),
+		grpc.ChainStreamInterceptor(streamInts...),
+	}
+	if srv.tlsConf != nil {
+		grpcOpts = append(grpcOpts, grpc.Creds(credentials.NewTLS(srv.tlsConf)))
+	}
+	if srv.keepalive != (keepalive.ServerParameters{}) {
+		grpcOpts = append(grpcOpts, grpc.KeepaliveParams(srv.keepalive))
+	}
+	if srv.windowSize > 0 {
+		grpcOpts = append(grpcOpts, grpc.InitialWindowSize(srv.windowSize))
+	}
+	if srv.connWindowSize > 0 {
+		grpcOpts = append(grpcOpts, grpc.InitialConnWindowSize(srv.connWindowSize))
+	}
+	if srv.maxStreams > 0 {
+		grpcOpts = append(grpcOpts, grpc.MaxConcurrentStreams(srv.maxStreams))
 	}
 	if len(srv.grpcOpts) > 0 {
 		grpcOpts = append(grpcOpts, srv.grpcOpts...)
 	}
 	srv.Server = grpc.NewServer(grpcOpts...)
 	srv.metadata = metadata.NewServer(srv.Server)
+	for _, name := range srv.healthNames {
+		srv.health.SetServingStatus(name, grpc_health_v1.HealthCheckResponse_SERVING)
+	}
+	srv.health.SetServingStatus("", srv.healthDefault)
 	// internal register
 	grpc_health_v1.RegisterHealthServer(srv.Server, srv.health)
 	metadata.RegisterMetadataServer(srv.Server, srv.metadata)
-	reflection.Register(srv.Server)
+	if srv.reflection {
+		reflection.Register(srv.Server)
+	}
 	return srv
 }
 
@@ -136,7 +364,11 @@ func (s *Server) Endpoint() (*url.URL, error) {
 			s.err = err
 			return
 		}
-		addr, err := host.Extract(s.address, s.lis)
+		if s.endpoint != nil {
+			s.lis = lis
+			return
+		}
+		addr, err := host.Extract(s.address, lis)
 		if err != nil {
 			lis.Close()
 			s.err = err
@@ -148,9 +380,21 @@ func (s *Server) Endpoint() (*url.URL, error) {
 	if s.err != nil {
 		return nil, s.err
 	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	return s.endpoint, nil
 }
 
+func (s *Server) endpointString() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.endpoint == nil {
+		// served before Endpoint or Start, e.g. by the tests
+		return ""
+	}
+	return s.endpoint.String()
+}
+
 // Start start the gRPC server.
 func (s *Server) Start(ctx context.Context) error {
 	if _, err := s.Endpoint(); err != nil {
@@ -159,34 +403,241 @@ func (s *Server) Start(ctx context.Context) error {
 	s.ctx = ctx
 	s.log.Infof("[gRPC] server listening on: %s", s.lis.Addr().String())
 	s.health.Resume()
-	return s.Serve(s.lis)
+	// Resume sets all the services to SERVING
+	s.health.SetServingStatus("", s.healthDefault)
+	if s.overloaded != nil && s.loadInterval > 0 {
+		go s.watchLoad(ctx)
+	}
+	s.mu.Lock()
+	s.served = make(chan error, 1)
+	lis := s.lis
+	s.mu.Unlock()
+	go s.serve(lis)
+	return <-s.served
+}
+
+// serve serves the listener, and reports the error unless the listener is retired by Migrate.
+func (s *Server) serve(lis net.Listener) {
+	err := s.Serve(lis)
+	s.mu.RLock()
+	_, retired := s.retired[lis]
+	s.mu.RUnlock()
+	if !retired {
+		s.served <- err
+	}
+}
+
+// Migrate starts serving on the new address before closing the listener of the old one,
+// so the server moves to the new address without refusing the connections, e.g. driven
+// by a config reload. The connections accepted by the old listener are served until they
+// are closed. The endpoint is updated as well unless it is set by the Endpoint option,
+// use the Reregister of the app to update the registry.
+func (s *Server) Migrate(address string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.served == nil {
+		return fmt.Errorf("[gRPC] server is not started")
+	}
+	lis, err := net.Listen(s.network, address)
+	if err != nil {
+		return err
+	}
+	endpoint := s.endpoint
+	if !s.advertised {
+		addr, err := host.Extract(address, lis)
+		if err != nil {
+			lis.Close()
+			return err
+		}
+		endpoint = &url.URL{Scheme: s.endpoint.Scheme, Host: addr}
+	}
+	old := s.lis
+	if s.retired == nil {
+		s.retired = make(map[net.Listener]struct{})
+	}
+	s.retired[old] = struct{}{}
+	s.lis, s.address, s.endpoint = lis, address, endpoint
+	go s.serve(lis)
+	s.log.Infof("[gRPC] server migrating from %s to %s", old.Addr().String(), lis.Addr().String())
+	return old.Close()
 }
 
 // Stop stop the gRPC server.
 func (s *Server) Stop(ctx context.Context) error {
+	if s.lameDuck > 0 {
+		atomic.StoreInt32(&s.lameDucking, 1)
+		s.health.Shutdown()
+		s.log.Infof("[gRPC] server entering lame duck mode for %s", s.lameDuck)
+		if ctx.Err() != nil {
+			// the app cancels the context to stop the servers
+			ctx = context.Background()
+		}
+		timer := time.NewTimer(s.lameDuck)
+		select {
+		case <-ctx.Done():
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
 	s.GracefulStop()
 	s.health.Shutdown()
 	s.log.Info("[gRPC] server stopping")
 	return nil
 }
 
+// SetServingStatus sets the serving status of the service reported by the health service,
+// the empty service is the status of the whole server.
+func (s *Server) SetServingStatus(service string, status grpc_health_v1.HealthCheckResponse_ServingStatus) {
+	s.health.SetServingStatus(service, status)
+}
+
+// HealthServer returns the health service of the server, e.g. to be mirrored by
+// the HealthCheck of the HTTP server.
+func (s *Server) HealthServer() *health.Server {
+	return s.health
+}
+
+// Drain sets all the services to NOT_SERVING without stopping the server,
+// so the load balancers stop sending new requests while the existing ones finish.
+func (s *Server) Drain(ctx context.Context) error {
+	s.health.Shutdown()
+	s.log.Info("[gRPC] server draining")
+	return nil
+}
+
+// errLameDuck is the error of the new requests in the lame duck mode,
+// the health checks are served to report NOT_SERVING.
+var errLameDuck = errors.ServiceUnavailable("LAME_DUCK", "the server is shutting down")
+
+func (s *Server) isLameDuck(method string) bool {
+	return atomic.LoadInt32(&s.lameDucking) == 1 && !strings.HasPrefix(method, "/grpc.health.v1.Health/")
+}
+
+// checkMethod returns the error if the method is denied or not allowed.
+func (s *Server) checkMethod(method string) error {
+	if matchMethod(s.deny, method) {
+		return errors.Forbidden("METHOD_DENIED", "method "+method+" is denied")
+	}
+	if len(s.allow) > 0 && !matchMethod(s.allow, method) && !strings.HasPrefix(method, "/grpc.health.v1.Health/") {
+		return errors.New(501, "METHOD_NOT_ALLOWED", "method "+method+" is not allowed")
+	}
+	return nil
+}
+
+func matchMethod(methods []string, method string) bool {
+	for _, m := range methods {
+		if m == method || (strings.HasSuffix(m, "/") && strings.HasPrefix(method, m)) {
+			return true
+		}
+	}
+	return false
+}
+
+// loadThreshold is the number of consecutive checks to flip the serving status,
+// which avoids flapping.
+const loadThreshold = 3
+
+func (s *Server) watchLoad(ctx context.Context) {
+	var (
+		serving = true
+		count   int
+	)
+	ticker := time.NewTicker(s.loadInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if s.overloaded() == serving {
+			count++
+		} else {
+			count = 0
+		}
+		if count < loadThreshold {
+			continue
+		}
+		count = 0
+		serving = !serving
+		if serving {
+			s.log.Info("[gRPC] server recovered from overload")
+			s.setHealth(grpc_health_v1.HealthCheckResponse_SERVING)
+		} else {
+			s.log.Warn("[gRPC] server overloaded")
+			s.setHealth(grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+		}
+	}
+}
+
+// setHealth sets the serving status of the empty and the named services.
+func (s *Server) setHealth(status grpc_health_v1.HealthCheckResponse_ServingStatus) {
+	s.health.SetServingStatus("", status)
+	for _, name := range s.healthNames {
+		s.health.SetServingStatus(name, status)
+	}
+}
+
+// baseContext returns the context of the server, or the background context
+// if the server is serving without Start, e.g. by the gRPC-Web handler.
+func (s *Server) baseContext() context.Context {
+	if s.ctx == nil {
+		return context.Background()
+	}
+	return s.ctx
+}
+
 func (s *Server) unaryServerInterceptor() grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
-		ctx, cancel := ic.Merge(ctx, s.ctx)
+		if s.isLameDuck(info.FullMethod) {
+			return nil, errLameDuck
+		}
+		if err := s.checkMethod(info.FullMethod); err != nil {
+			return nil, err
+		}
+		ctx, cancel := ic.Merge(ctx, s.baseContext())
 		defer cancel()
-		ctx = transport.NewContext(ctx, transport.Transport{Kind: transport.KindGRPC, Endpoint: s.endpoint.String()})
+		ctx = transport.NewStartContext(ctx, time.Now())
+		ctx = transport.NewContext(ctx, transport.Transport{Kind: transport.KindGRPC, Endpoint: s.endpointString()})
 		ctx = NewServerContext(ctx, ServerInfo{Server: info.Server, FullMethod: info.FullMethod})
-		if s.timeout > 0 {
-			var cancel context.CancelFunc
-			ctx, cancel = context.WithTimeout(ctx, s.timeout)
-			defer cancel()
-		}
+		ctx = transport.NewHeaderContext(ctx, header{ctx: ctx})
 		h := func(ctx context.Context, req interface{}) (interface{}, error) {
 			return handler(ctx, req)
 		}
 		if s.middleware != nil {
 			h = s.middleware(h)
 		}
+		if s.timeout > 0 {
+			next := h
+			h = func(ctx context.Context, req interface{}) (interface{}, error) {
+				// the handlers can extend the timeout by transport.ExtendDeadline
+				dctx, cancel := ic.WithExtendableDeadline(ctx, time.Now().Add(s.timeout))
+				defer cancel()
+				return next(transport.NewDeadlineContext(dctx, dctx), req)
+			}
+		}
+		if s.preTimeout != nil {
+			h = s.preTimeout(h)
+		}
 		return h(ctx, req)
 	}
 }
+
+func (s *Server) streamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if s.isLameDuck(info.FullMethod) {
+			return errLameDuck
+		}
+		if err := s.checkMethod(info.FullMethod); err != nil {
+			return err
+		}
+		ctx, cancel := ic.Merge(ss.Context(), s.baseContext())
+		defer cancel()
+		ctx = transport.NewStartContext(ctx, time.Now())
+		ctx = transport.NewContext(ctx, transport.Transport{Kind: transport.KindGRPC, Endpoint: s.endpointString()})
+		ctx = NewServerContext(ctx, ServerInfo{Server: srv, FullMethod: info.FullMethod})
+		ctx = NewStreamContext(ctx, ss)
+		ctx = transport.NewHeaderContext(ctx, header{ctx: ctx})
+		return handler(srv, &wrappedStream{ServerStream: ss, ctx: ctx})
+	}
+}
@@ -3,10 +3,20 @@ package grpc
 import (
 	"context"
 	"fmt"
+	"net/url"
 	"testing"
 	"time"
 
+	"github.com/go-kratos/kratos/v2/errors"
 	"github.com/go-kratos/kratos/v2/internal/host"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/protobuf/types/known/wrapperspb"
 )
 
 type testKey struct{}
@@ -30,6 +40,59 @@ func TestServer(t *testing.T) {
 	srv.Stop(ctx)
 }
 
+func TestReflection(t *testing.T) {
+	const name = "grpc.reflection.v1alpha.ServerReflection"
+	if _, ok := NewServer(Reflection(true)).GetServiceInfo()[name]; !ok {
+		t.Fatal("expected the reflection service registered")
+	}
+	if _, ok := NewServer(Reflection(false)).GetServiceInfo()[name]; ok {
+		t.Fatal("expected the reflection service not registered")
+	}
+}
+
+func TestAdvertisedEndpoint(t *testing.T) {
+	advertised := &url.URL{Scheme: "grpc", Host: "10.0.0.1:9000"}
+	srv := NewServer(Address("127.0.0.1:0"), Endpoint(advertised))
+	e, err := srv.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.String() != advertised.String() {
+		t.Fatalf("expected the advertised endpoint %s got %s", advertised, e)
+	}
+	go srv.Start(context.Background())
+	defer srv.Stop(context.Background())
+	time.Sleep(100 * time.Millisecond)
+	if err := srv.Migrate("127.0.0.1:0"); err != nil {
+		t.Fatal(err)
+	}
+	if e, _ := srv.Endpoint(); e.String() != advertised.String() {
+		t.Fatalf("expected the advertised endpoint kept after the migration got %s", e)
+	}
+}
+
+func TestCheckMethod(t *testing.T) {
+	srv := NewServer(
+		AllowMethods("/helloworld.Greeter/", "/admin.Admin/List"),
+		DenyMethods("/helloworld.Greeter/Reset"),
+	)
+	tests := []struct {
+		method string
+		code   int
+	}{
+		{"/helloworld.Greeter/SayHello", 0},
+		{"/helloworld.Greeter/Reset", 403},
+		{"/admin.Admin/List", 0},
+		{"/admin.Admin/Delete", 501},
+		{"/grpc.health.v1.Health/Check", 0},
+	}
+	for _, test := range tests {
+		if code := errors.Code(srv.checkMethod(test.method)); code != test.code {
+			t.Errorf("%s: expected %d got %d", test.method, test.code, code)
+		}
+	}
+}
+
 func testClient(t *testing.T, srv *Server) {
 	port, ok := host.Port(srv.lis)
 	if !ok {
@@ -43,3 +106,420 @@ func testClient(t *testing.T, srv *Server) {
 	}
 	conn.Close()
 }
+
+var pullDesc = grpc.ServiceDesc{
+	ServiceName: "test.Stream",
+	HandlerType: (*interface{})(nil),
+	Streams: []grpc.StreamDesc{{
+		StreamName:    "Pull",
+		ServerStreams: true,
+		Handler: func(srv interface{}, stream grpc.ServerStream) error {
+			in := new(wrapperspb.Int32Value)
+			if err := stream.RecvMsg(in); err != nil {
+				return err
+			}
+			out := &wrapperspb.BytesValue{Value: make([]byte, 64*1024)}
+			for i := int32(0); i < in.Value; i++ {
+				if err := stream.SendMsg(out); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}},
+}
+
+// BenchmarkStreamWindow compares the throughput of a server streaming workload
+// with the default window and the large window.
+func BenchmarkStreamWindow(b *testing.B) {
+	const window = 4 << 20
+	b.Run("default", func(b *testing.B) {
+		benchmarkPull(b, nil, nil)
+	})
+	b.Run("window", func(b *testing.B) {
+		benchmarkPull(b,
+			[]ServerOption{InitialWindowSize(window), InitialConnWindowSize(window)},
+			[]ClientOption{WithInitialWindowSize(window), WithInitialConnWindowSize(window)},
+		)
+	})
+}
+
+func benchmarkPull(b *testing.B, opts []ServerOption, clientOpts []ClientOption) {
+	const messages = 64
+	srv := NewServer(append(opts, Address("127.0.0.1:0"))...)
+	srv.RegisterService(&pullDesc, struct{}{})
+	if _, err := srv.Endpoint(); err != nil {
+		b.Fatal(err)
+	}
+	go srv.Start(context.Background())
+	defer srv.Stop(context.Background())
+
+	conn, err := DialInsecure(context.Background(), append(clientOpts, WithEndpoint(srv.lis.Addr().String()))...)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer conn.Close()
+
+	b.SetBytes(messages * 64 * 1024)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		stream, err := conn.NewStream(context.Background(), &pullDesc.Streams[0], "/test.Stream/Pull")
+		if err != nil {
+			b.Fatal(err)
+		}
+		if err := stream.SendMsg(&wrapperspb.Int32Value{Value: messages}); err != nil {
+			b.Fatal(err)
+		}
+		if err := stream.CloseSend(); err != nil {
+			b.Fatal(err)
+		}
+		out := new(wrapperspb.BytesValue)
+		for j := 0; j < messages; j++ {
+			if err := stream.RecvMsg(out); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+var metadataDesc = grpc.ServiceDesc{
+	ServiceName: "test.Metadata",
+	HandlerType: (*interface{})(nil),
+	Methods: []grpc.MethodDesc{{
+		MethodName: "Set",
+		Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+			in := new(wrapperspb.StringValue)
+			if err := dec(in); err != nil {
+				return nil, err
+			}
+			info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/test.Metadata/Set"}
+			return interceptor(ctx, in, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+				if err := transport.SetHeader(ctx, "x-header", "header"); err != nil {
+					return nil, err
+				}
+				if err := transport.SetTrailer(ctx, "x-trailer", "trailer"); err != nil {
+					return nil, err
+				}
+				if err := transport.SetReplyHeader(ctx, "x-reply", "reply"); err != nil {
+					return nil, err
+				}
+				return req, nil
+			})
+		},
+	}},
+}
+
+func TestReplyMetadata(t *testing.T) {
+	srv := NewServer(Address("127.0.0.1:0"))
+	srv.RegisterService(&metadataDesc, struct{}{})
+	if _, err := srv.Endpoint(); err != nil {
+		t.Fatal(err)
+	}
+	go srv.Start(context.Background())
+	defer srv.Stop(context.Background())
+
+	conn, err := DialInsecure(context.Background(), WithEndpoint(srv.lis.Addr().String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	var header, trailer metadata.MD
+	out := new(wrapperspb.StringValue)
+	if err := conn.Invoke(context.Background(), "/test.Metadata/Set", wrapperspb.String("in"), out, grpc.Header(&header), grpc.Trailer(&trailer)); err != nil {
+		t.Fatal(err)
+	}
+	if v := header.Get("x-header"); len(v) != 1 || v[0] != "header" {
+		t.Errorf("expected the header got %v", header)
+	}
+	if v := trailer.Get("x-trailer"); len(v) != 1 || v[0] != "trailer" {
+		t.Errorf("expected the trailer got %v", trailer)
+	}
+	if v := header.Get("x-reply"); len(v) != 1 || v[0] != "reply" {
+		t.Errorf("expected the reply header got %v", header)
+	}
+}
+
+var streamDesc = grpc.ServiceDesc{
+	ServiceName: "test.Stream",
+	HandlerType: (*interface{})(nil),
+	Streams: []grpc.StreamDesc{{
+		StreamName:    "Echo",
+		ServerStreams: true,
+		ClientStreams: true,
+		Handler: func(srv interface{}, stream grpc.ServerStream) error {
+			ctx := stream.Context()
+			tr, ok := transport.FromContext(ctx)
+			if !ok || tr.Kind != transport.KindGRPC || tr.Endpoint == "" {
+				return fmt.Errorf("unexpected transport: %+v", tr)
+			}
+			if info, ok := FromServerContext(ctx); !ok || info.FullMethod != "/test.Stream/Echo" {
+				return fmt.Errorf("unexpected server info: %+v", info)
+			}
+			if ss, ok := StreamFromContext(ctx); !ok || ss == nil {
+				return fmt.Errorf("expected the server stream")
+			}
+			if err := transport.SetHeader(ctx, "x-header", "header"); err != nil {
+				return err
+			}
+			in := new(wrapperspb.StringValue)
+			if err := stream.RecvMsg(in); err != nil {
+				return err
+			}
+			return stream.SendMsg(in)
+		},
+	}},
+}
+
+func TestStream(t *testing.T) {
+	var intercepted bool
+	srv := NewServer(Address("127.0.0.1:0"), StreamInterceptor(func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		_, intercepted = transport.FromContext(ss.Context())
+		return handler(srv, ss)
+	}))
+	srv.RegisterService(&streamDesc, struct{}{})
+	if _, err := srv.Endpoint(); err != nil {
+		t.Fatal(err)
+	}
+	go srv.Start(context.Background())
+	defer srv.Stop(context.Background())
+
+	conn, err := DialInsecure(context.Background(), WithEndpoint(srv.lis.Addr().String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	stream, err := conn.NewStream(context.Background(), &streamDesc.Streams[0], "/test.Stream/Echo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.SendMsg(wrapperspb.String("in")); err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.CloseSend(); err != nil {
+		t.Fatal(err)
+	}
+	out := new(wrapperspb.StringValue)
+	if err := stream.RecvMsg(out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Value != "in" {
+		t.Errorf("expected in got %s", out.Value)
+	}
+	header, err := stream.Header()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := header.Get("x-header"); len(v) != 1 || v[0] != "header" {
+		t.Errorf("expected the header got %v", header)
+	}
+	if !intercepted {
+		t.Error("expected the stream interceptor with the transport")
+	}
+}
+
+func TestHealthFromLoad(t *testing.T) {
+	tests := []struct {
+		name   string
+		loads  []bool
+		status grpc_health_v1.HealthCheckResponse_ServingStatus
+	}{
+		{"idle", []bool{false, false, false}, grpc_health_v1.HealthCheckResponse_SERVING},
+		{"below threshold", []bool{true, true}, grpc_health_v1.HealthCheckResponse_SERVING},
+		{"overloaded", []bool{true, true, true}, grpc_health_v1.HealthCheckResponse_NOT_SERVING},
+		{"flapping", []bool{true, true, false, true, true}, grpc_health_v1.HealthCheckResponse_SERVING},
+		{"recovering", []bool{true, true, true, false, false}, grpc_health_v1.HealthCheckResponse_NOT_SERVING},
+		{"recovered", []bool{true, true, true, false, false, false}, grpc_health_v1.HealthCheckResponse_SERVING},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			var (
+				srv *Server
+				i   int
+			)
+			overloaded := func() bool {
+				if i == len(test.loads) {
+					// keeps the current status once all the loads are checked
+					res, _ := srv.health.Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+					return res.GetStatus() != grpc_health_v1.HealthCheckResponse_SERVING
+				}
+				i++
+				if i == len(test.loads) {
+					cancel()
+				}
+				return test.loads[i-1]
+			}
+			srv = NewServer(HealthFromLoad(overloaded, time.Millisecond))
+			srv.watchLoad(ctx)
+			res, err := srv.health.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Status != test.status {
+				t.Fatalf("expected %s got %s", test.status, res.Status)
+			}
+		})
+	}
+}
+
+func TestKeepaliveOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		opt      ServerOption
+		expected keepalive.ServerParameters
+	}{
+		{"max connection age", MaxConnectionAge(time.Minute), keepalive.ServerParameters{MaxConnectionAge: time.Minute}},
+		{"max connection age grace", MaxConnectionAgeGrace(time.Second), keepalive.ServerParameters{MaxConnectionAgeGrace: time.Second}},
+		{"max connection idle", MaxConnectionIdle(time.Hour), keepalive.ServerParameters{MaxConnectionIdle: time.Hour}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			srv := NewServer(test.opt)
+			if srv.keepalive != test.expected {
+				t.Fatalf("expected %+v got %+v", test.expected, srv.keepalive)
+			}
+		})
+	}
+}
+
+func TestMaxConnectionAge(t *testing.T) {
+	for _, age := range []time.Duration{0, 200 * time.Millisecond} {
+		var addrs []string
+		srv := NewServer(
+			Address("127.0.0.1:0"),
+			MaxConnectionAge(age),
+			MaxConnectionAgeGrace(100*time.Millisecond),
+			UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+				if p, ok := peer.FromContext(ctx); ok {
+					addrs = append(addrs, p.Addr.String())
+				}
+				return handler(ctx, req)
+			}),
+		)
+		srv.RegisterService(&metadataDesc, struct{}{})
+		if _, err := srv.Endpoint(); err != nil {
+			t.Fatal(err)
+		}
+		go srv.Start(context.Background())
+
+		conn, err := DialInsecure(context.Background(), WithEndpoint(srv.lis.Addr().String()), WithWaitForReady(true))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 2; i++ {
+			if err := conn.Invoke(context.Background(), "/test.Metadata/Set", wrapperspb.String("in"), new(wrapperspb.StringValue)); err != nil {
+				t.Fatal(err)
+			}
+			time.Sleep(500 * time.Millisecond)
+		}
+		conn.Close()
+		srv.Stop(context.Background())
+		if len(addrs) != 2 {
+			t.Fatalf("expected two calls got %v", addrs)
+		}
+		// the client reconnects from another port after the max connection age
+		if reconnected := addrs[0] != addrs[1]; reconnected != (age > 0) {
+			t.Fatalf("max connection age %s: unexpected connections %v", age, addrs)
+		}
+	}
+}
+
+func TestHealthServiceNames(t *testing.T) {
+	srv := NewServer(
+		HealthServiceNames("lb.check"),
+		HealthDefaultStatus(grpc_health_v1.HealthCheckResponse_NOT_SERVING),
+	)
+	tests := map[string]grpc_health_v1.HealthCheckResponse_ServingStatus{
+		"":         grpc_health_v1.HealthCheckResponse_NOT_SERVING,
+		"lb.check": grpc_health_v1.HealthCheckResponse_SERVING,
+	}
+	for name, want := range tests {
+		res, err := srv.health.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: name})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Status != want {
+			t.Errorf("got %s of %q want %s", res.Status, name, want)
+		}
+	}
+	if _, err := srv.health.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: "unknown"}); err == nil {
+		t.Error("expected the unknown service not found")
+	}
+}
+
+func testMiddleware(h middleware.Handler) middleware.Handler {
+	return h
+}
+
+func TestDescribe(t *testing.T) {
+	srv := NewServer(Address("127.0.0.1:0"), Reflection(true), Middleware(testMiddleware))
+	srv.RegisterService(&metadataDesc, struct{}{})
+	d := srv.Describe()
+	defer srv.lis.Close()
+	if d.Kind != transport.KindGRPC || d.Endpoint == "" {
+		t.Fatalf("unexpected description %+v", d)
+	}
+	if len(d.Operations) != 1 || d.Operations[0] != "/test.Metadata/Set" {
+		t.Fatalf("expected the operations of the registered services only, got %v", d.Operations)
+	}
+	if len(d.Middlewares) != 1 || d.Middlewares[0] != "github.com/go-kratos/kratos/v2/transport/grpc.testMiddleware" {
+		t.Fatalf("unexpected middlewares %v", d.Middlewares)
+	}
+	// the missing recovery and the reflection
+	if len(d.Warnings) != 2 {
+		t.Fatalf("expected 2 warnings got %v", d.Warnings)
+	}
+}
+
+func TestPreTimeoutMiddleware(t *testing.T) {
+	var calls []string
+	record := func(name string) middleware.Middleware {
+		return func(handler middleware.Handler) middleware.Handler {
+			return func(ctx context.Context, req interface{}) (interface{}, error) {
+				_, ok := ctx.Deadline()
+				calls = append(calls, fmt.Sprintf("%s:%v", name, ok))
+				return handler(ctx, req)
+			}
+		}
+	}
+	srv := NewServer(
+		Timeout(time.Second),
+		PreTimeoutMiddleware(record("pre")),
+		Middleware(record("middleware")),
+	)
+	srv.ctx = context.Background()
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Test/Test"}
+	_, err := srv.unaryServerInterceptor()(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		_, ok := ctx.Deadline()
+		calls = append(calls, fmt.Sprintf("handler:%v", ok))
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the pre-timeout middleware runs first without the deadline of the server timeout
+	expected := []string{"pre:false", "middleware:true", "handler:true"}
+	if fmt.Sprint(calls) != fmt.Sprint(expected) {
+		t.Fatalf("expected %v got %v", expected, calls)
+	}
+}
+
+func TestRequestHeader(t *testing.T) {
+	ctx := NewServerContext(context.Background(), ServerInfo{FullMethod: "/test.Test/Test"})
+	// the metadata set after the server context is read by the handler context
+	ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("x-tenant", "kratos", "accept-language", "en", "accept-language", "zh"))
+	if v := transport.RequestHeader(ctx, "X-Tenant"); v != "kratos" {
+		t.Errorf("expected the case-insensitive metadata got %q", v)
+	}
+	h, ok := transport.RequestHeaderCarrier(ctx)
+	if !ok {
+		t.Fatal("expected the request header")
+	}
+	if v := h.Values("Accept-Language"); len(v) != 2 {
+		t.Errorf("expected all the values got %v", v)
+	}
+	if keys := h.Keys(); len(keys) != 2 {
+		t.Errorf("expected the keys got %v", keys)
+	}
+}
@@ -0,0 +1,191 @@
+package grpc
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/middleware/cors"
+)
+
+const (
+	webContentType     = "application/grpc-web"
+	webTextContentType = "application/grpc-web-text"
+	// webTrailerFlag is the flag of the trailer frame in the response body.
+	webTrailerFlag = 0x80
+)
+
+// WebOption is gRPC-Web option.
+type WebOption func(*webOptions)
+
+type webOptions struct {
+	origins     []string
+	credentials bool
+}
+
+// WithWebOrigins with the origins allowed by CORS, the default denies the cross-origin requests.
+func WithWebOrigins(origins ...string) WebOption {
+	return func(o *webOptions) {
+		o.origins = origins
+	}
+}
+
+// WithWebCredentials with whether the cross-origin requests with credentials such as cookies are allowed.
+func WithWebCredentials(allow bool) WebOption {
+	return func(o *webOptions) {
+		o.credentials = allow
+	}
+}
+
+// WebHandler returns an HTTP handler which serves the gRPC-Web requests of the browsers
+// by the services of the server, so no proxy such as Envoy is needed, e.g.
+// transport/http.GRPCWeb(srv.WebHandler()) serves it with the HTTP server.
+// Both the binary and the base64 text framing are supported, the server streaming
+// responses are flushed per message, and the client streaming is not supported by gRPC-Web.
+func (s *Server) WebHandler(opts ...WebOption) http.Handler {
+	options := webOptions{}
+	for _, o := range opts {
+		o(&options)
+	}
+	return cors.Server(
+		cors.WithOrigins(options.origins...),
+		cors.WithCredentials(options.credentials),
+		cors.WithMethods(http.MethodPost),
+		cors.WithExposedHeaders("grpc-status", "grpc-message", "grpc-status-details-bin"),
+		cors.WithMaxAge(10*time.Minute),
+	)(&webHandler{srv: s})
+}
+
+type webHandler struct {
+	srv *Server
+}
+
+func (h *webHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	contentType := r.Header.Get("Content-Type")
+	if r.Method != http.MethodPost || !strings.HasPrefix(contentType, webContentType) {
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+		return
+	}
+	text := strings.HasPrefix(contentType, webTextContentType)
+	subtype := strings.TrimPrefix(contentType, webContentType)
+	if text {
+		subtype = strings.TrimPrefix(contentType, webTextContentType)
+	}
+	// translates to a gRPC request over HTTP/2 served by the gRPC server
+	req := r.Clone(r.Context())
+	req.ProtoMajor, req.ProtoMinor, req.Proto = 2, 0, "HTTP/2"
+	req.Header.Set("Content-Type", "application/grpc"+subtype)
+	req.Header.Del("Content-Length")
+	req.ContentLength = -1
+	if text {
+		req.Body = ioutil.NopCloser(base64.NewDecoder(base64.StdEncoding, r.Body))
+	}
+	rw := &webResponseWriter{
+		w:           w,
+		header:      make(http.Header),
+		text:        text,
+		contentType: strings.SplitN(contentType, ";", 2)[0],
+	}
+	h.srv.ServeHTTP(rw, req)
+	rw.finish()
+}
+
+// webResponseWriter writes the trailers of gRPC into the body as gRPC-Web requires.
+type webResponseWriter struct {
+	w           http.ResponseWriter
+	header      http.Header
+	text        bool
+	contentType string
+	wroteHeader bool
+	enc         io.WriteCloser
+}
+
+func (rw *webResponseWriter) Header() http.Header {
+	return rw.header
+}
+
+func (rw *webResponseWriter) WriteHeader(code int) {
+	if rw.wroteHeader {
+		return
+	}
+	rw.wroteHeader = true
+	h := rw.w.Header()
+	for k, v := range rw.header {
+		if k == "Trailer" || strings.HasPrefix(k, http.TrailerPrefix) {
+			continue
+		}
+		h[k] = v
+	}
+	h.Set("Content-Type", rw.contentType)
+	h.Del("Content-Length")
+	rw.w.WriteHeader(code)
+}
+
+func (rw *webResponseWriter) Write(b []byte) (int, error) {
+	rw.WriteHeader(http.StatusOK)
+	if !rw.text {
+		return rw.w.Write(b)
+	}
+	if rw.enc == nil {
+		rw.enc = base64.NewEncoder(base64.StdEncoding, rw.w)
+	}
+	return rw.enc.Write(b)
+}
+
+// Flush flushes the messages, each flush of the text framing is a padded base64 chunk.
+func (rw *webResponseWriter) Flush() {
+	rw.WriteHeader(http.StatusOK)
+	if rw.enc != nil {
+		rw.enc.Close()
+		rw.enc = nil
+	}
+	if f, ok := rw.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (rw *webResponseWriter) finish() {
+	if !rw.wroteHeader {
+		// trailers-only response, the status is in the headers,
+		// though the gRPC server flushes the headers before the status
+		for k, v := range rw.trailers() {
+			rw.header[k] = v
+		}
+		rw.WriteHeader(http.StatusOK)
+		rw.Flush()
+		return
+	}
+	var buf bytes.Buffer
+	for k, v := range rw.trailers() {
+		for _, vv := range v {
+			buf.WriteString(strings.ToLower(k) + ": " + vv + "\r\n")
+		}
+	}
+	frame := make([]byte, 5, 5+buf.Len())
+	frame[0] = webTrailerFlag
+	binary.BigEndian.PutUint32(frame[1:], uint32(buf.Len()))
+	rw.Write(append(frame, buf.Bytes()...))
+	rw.Flush()
+}
+
+// trailers returns the trailers declared by the Trailer header or set with the trailer prefix.
+func (rw *webResponseWriter) trailers() http.Header {
+	trailers := make(http.Header)
+	for _, k := range rw.header["Trailer"] {
+		k = http.CanonicalHeaderKey(k)
+		if v, ok := rw.header[k]; ok {
+			trailers[k] = v
+		}
+	}
+	for k, v := range rw.header {
+		if strings.HasPrefix(k, http.TrailerPrefix) {
+			trailers[http.CanonicalHeaderKey(strings.TrimPrefix(k, http.TrailerPrefix))] = v
+		}
+	}
+	return trailers
+}
@@ -0,0 +1,91 @@
+package grpc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestWebHandler(t *testing.T) {
+	srv := NewServer()
+	srv.RegisterService(&pullDesc, nil)
+	if _, err := srv.Endpoint(); err != nil {
+		t.Fatal(err)
+	}
+	defer srv.lis.Close()
+
+	data, err := proto.Marshal(&wrapperspb.Int32Value{Value: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := make([]byte, 5, 5+len(data))
+	binary.BigEndian.PutUint32(body[1:], uint32(len(data)))
+	req := httptest.NewRequest(http.MethodPost, "/test.Stream/Pull", bytes.NewReader(append(body, data...)))
+	req.Header.Set("Content-Type", "application/grpc-web+proto")
+	req.Header.Set("Origin", "https://example.com")
+	res := httptest.NewRecorder()
+	srv.WebHandler(WithWebOrigins("https://example.com")).ServeHTTP(res, req)
+
+	if ct := res.Header().Get("Content-Type"); ct != "application/grpc-web+proto" {
+		t.Fatalf("expected grpc-web content type got %s", ct)
+	}
+	if origin := res.Header().Get("Access-Control-Allow-Origin"); origin != "https://example.com" {
+		t.Fatalf("expected the allowed origin got %s", origin)
+	}
+	var (
+		messages int
+		trailer  string
+		b        = res.Body.Bytes()
+	)
+	for len(b) >= 5 {
+		n := binary.BigEndian.Uint32(b[1:5])
+		if b[0]&webTrailerFlag != 0 {
+			trailer = string(b[5 : 5+n])
+		} else {
+			messages++
+		}
+		b = b[5+n:]
+	}
+	if messages != 2 {
+		t.Fatalf("expected 2 messages got %d", messages)
+	}
+	if !strings.Contains(trailer, "grpc-status: 0") {
+		t.Fatalf("expected the status in the trailer got %q", trailer)
+	}
+}
+
+func TestWebHandlerCORS(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []WebOption
+		origin      string
+		code        int
+		credentials string
+	}{
+		{"default", nil, "https://example.com", http.StatusForbidden, ""},
+		{"not allowed", []WebOption{WithWebOrigins("https://example.com")}, "https://evil.com", http.StatusForbidden, ""},
+		{"allowed", []WebOption{WithWebOrigins("https://example.com")}, "https://example.com", http.StatusNoContent, ""},
+		{"credentials", []WebOption{WithWebOrigins("https://example.com"), WithWebCredentials(true)}, "https://example.com", http.StatusNoContent, "true"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodOptions, "/test.Stream/Pull", nil)
+			req.Header.Set("Origin", tt.origin)
+			req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+			res := httptest.NewRecorder()
+			NewServer().WebHandler(tt.opts...).ServeHTTP(res, req)
+			if res.Code != tt.code {
+				t.Fatalf("expected %d got %d", tt.code, res.Code)
+			}
+			if v := res.Header().Get("Access-Control-Allow-Credentials"); v != tt.credentials {
+				t.Fatalf("expected the credentials %q got %q", tt.credentials, v)
+			}
+		})
+	}
+}
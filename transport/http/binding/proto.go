@@ -214,9 +214,12 @@ func parseMessage(md protoreflect.MessageDescriptor, value string) (protoreflect
 		if value == "null" {
 			break
 		}
+		// either the Go duration such as 5m or the ISO 8601 duration such as PT5M
 		d, err := time.ParseDuration(value)
 		if err != nil {
-			return protoreflect.Value{}, err
+			if d, err = parseISODuration(value); err != nil {
+				return protoreflect.Value{}, err
+			}
 		}
 		msg = durationpb.New(d)
 	case "google.protobuf.DoubleValue":
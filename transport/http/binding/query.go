@@ -0,0 +1,103 @@
+package binding
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// QueryParser rewrites the values of a query parameter in the custom format
+// to the values understood by the binding, e.g. splits the comma-separated IDs.
+type QueryParser func(values []string) ([]string, error)
+
+// CommaSeparated splits the comma-separated values, e.g. ids=1,2,3 to the repeated field.
+func CommaSeparated(values []string) ([]string, error) {
+	var res []string
+	for _, v := range values {
+		for _, s := range strings.Split(v, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				res = append(res, s)
+			}
+		}
+	}
+	return res, nil
+}
+
+// ParseQuery rewrites the values of the query parameters by the parsers,
+// the key of the parsers is the query parameter, e.g. ids or sub.ids.
+func ParseQuery(values url.Values, parsers map[string]QueryParser) error {
+	for key, parse := range parsers {
+		vs, ok := values[key]
+		if !ok {
+			continue
+		}
+		vs, err := parse(vs)
+		if err != nil {
+			return fmt.Errorf("parsing query %q: %w", key, err)
+		}
+		if len(vs) == 0 {
+			delete(values, key)
+			continue
+		}
+		values[key] = vs
+	}
+	return nil
+}
+
+// parseISODuration parses the ISO 8601 duration in days, hours, minutes and seconds,
+// e.g. P1DT2H or PT1.5S, the years and the months are not supported as they vary in length.
+func parseISODuration(value string) (time.Duration, error) {
+	s := value
+	var neg bool
+	if strings.HasPrefix(s, "-") {
+		neg, s = true, s[1:]
+	}
+	if !strings.HasPrefix(s, "P") || len(s) < 3 {
+		return 0, fmt.Errorf("invalid ISO 8601 duration %q", value)
+	}
+	s = s[1:]
+	var (
+		d      time.Duration
+		inTime bool
+	)
+	for s != "" {
+		if s[0] == 'T' {
+			if inTime || len(s) == 1 {
+				return 0, fmt.Errorf("invalid ISO 8601 duration %q", value)
+			}
+			inTime, s = true, s[1:]
+			continue
+		}
+		i := strings.IndexAny(s, "WDHMS")
+		if i <= 0 {
+			return 0, fmt.Errorf("invalid ISO 8601 duration %q", value)
+		}
+		n, err := strconv.ParseFloat(s[:i], 64)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid ISO 8601 duration %q", value)
+		}
+		var unit time.Duration
+		switch {
+		case s[i] == 'W' && !inTime:
+			unit = 7 * 24 * time.Hour
+		case s[i] == 'D' && !inTime:
+			unit = 24 * time.Hour
+		case s[i] == 'H' && inTime:
+			unit = time.Hour
+		case s[i] == 'M' && inTime:
+			unit = time.Minute
+		case s[i] == 'S' && inTime:
+			unit = time.Second
+		default:
+			return 0, fmt.Errorf("unsupported ISO 8601 duration %q", value)
+		}
+		d += time.Duration(n * float64(unit))
+		s = s[i+1:]
+	}
+	if neg {
+		d = -d
+	}
+	return d, nil
+}
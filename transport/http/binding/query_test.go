@@ -0,0 +1,57 @@
+package binding
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+func TestParseQuery(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/?paths=a,b&paths=c,&name=x,y", nil)
+	if err := req.ParseForm(); err != nil {
+		t.Fatal(err)
+	}
+	if err := ParseQuery(req.Form, map[string]QueryParser{"paths": CommaSeparated}); err != nil {
+		t.Fatal(err)
+	}
+	var fm fieldmaskpb.FieldMask
+	if err := BindForm(req, &fm); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a", "b", "c"}; !reflect.DeepEqual(fm.Paths, want) {
+		t.Fatalf("got paths %v want %v", fm.Paths, want)
+	}
+	if req.Form.Get("name") != "x,y" {
+		t.Fatalf("expected the name not parsed got %s", req.Form.Get("name"))
+	}
+}
+
+func TestISODuration(t *testing.T) {
+	tests := map[string]time.Duration{
+		"5m":         5 * time.Minute,
+		"PT5M":       5 * time.Minute,
+		"P1DT1H":     25 * time.Hour,
+		"PT1.5S":     1500 * time.Millisecond,
+		"P1W":        7 * 24 * time.Hour,
+		"-PT1H30M0S": -90 * time.Minute,
+	}
+	for value, want := range tests {
+		req, _ := http.NewRequest("GET", "/?retry_delay="+value, nil)
+		var info errdetails.RetryInfo
+		if err := BindForm(req, &info); err != nil {
+			t.Fatalf("binding %s: %v", value, err)
+		}
+		if got := info.RetryDelay.AsDuration(); got != want {
+			t.Errorf("got %s of %s want %s", got, value, want)
+		}
+	}
+	for _, value := range []string{"P1Y", "PT", "P1H", "PT1D"} {
+		if _, err := parseISODuration(value); err == nil {
+			t.Errorf("expected the error of %s", value)
+		}
+	}
+}
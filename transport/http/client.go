@@ -7,11 +7,13 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/go-kratos/kratos/v2/encoding"
 	"github.com/go-kratos/kratos/v2/errors"
 	"github.com/go-kratos/kratos/v2/internal/httputil"
+	ir "github.com/go-kratos/kratos/v2/internal/reply"
 	"github.com/go-kratos/kratos/v2/middleware"
 	"github.com/go-kratos/kratos/v2/registry"
 	"github.com/go-kratos/kratos/v2/transport"
@@ -189,7 +191,7 @@ func (client *Client) Invoke(ctx context.Context, path string, args interface{},
 		reqBody = bytes.NewReader(body)
 	}
 	url := fmt.Sprintf("%s://%s%s", client.target.Scheme, client.target.Authority, path)
-	req, err := http.NewRequest(c.method, url, reqBody)
+	req, err := http.NewRequestWithContext(ctx, c.method, url, reqBody)
 	if err != nil {
 		return err
 	}
@@ -199,7 +201,7 @@ func (client *Client) Invoke(ctx context.Context, path string, args interface{},
 	if client.opts.userAgent != "" {
 		req.Header.Set("User-Agent", client.opts.userAgent)
 	}
-	ctx = transport.NewContext(ctx, transport.Transport{Kind: transport.KindHTTP, Endpoint: client.opts.endpoint})
+	ctx = transport.NewContext(ctx, transport.Transport{Kind: transport.KindHTTP, Endpoint: client.opts.endpoint, Method: c.method})
 	ctx = NewClientContext(ctx, ClientInfo{PathPattern: c.pathPattern, Request: req})
 	return client.invoke(ctx, req, args, reply, c)
 }
@@ -223,6 +225,17 @@ func (client *Client) invoke(ctx context.Context, req *http.Request, args interf
 			req = req.Clone(ctx)
 			req.URL.Scheme = scheme
 			req.URL.Host = addr
+		} else {
+			// the cancellation of the middleware context aborts the request
+			req = req.WithContext(ctx)
+		}
+		if req.GetBody != nil {
+			// rewinds the body for the retries
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
 		}
 		res, err := client.do(ctx, req, c)
 		if done != nil {
@@ -240,8 +253,11 @@ func (client *Client) invoke(ctx context.Context, req *http.Request, args interf
 	if client.opts.middleware != nil {
 		h = client.opts.middleware(h)
 	}
-	_, err := h(ctx, args)
-	return err
+	out, err := h(ctx, args)
+	if err != nil {
+		return err
+	}
+	return ir.Set(reply, out)
 }
 
 // Do send an HTTP request and decodes the body of response into target.
@@ -296,10 +312,33 @@ func DefaultErrorDecoder(ctx context.Context, res *http.Response) error {
 	if err == nil {
 		e := new(errors.Error)
 		if err = CodecForResponse(res).Unmarshal(data, e); err == nil {
-			return e
+			return withRetryAfter(e, res.Header)
+		}
+	}
+	return withRetryAfter(errors.New(res.StatusCode, errors.UnknownReason, err.Error()), res.Header)
+}
+
+// withRetryAfter sets the retry delay of the Retry-After header to the error,
+// which is either the seconds or the HTTP date.
+func withRetryAfter(e *errors.Error, header http.Header) *errors.Error {
+	v := header.Get("Retry-After")
+	if v == "" {
+		return e
+	}
+	if _, ok := e.Metadata[errors.RetryAfterKey]; ok {
+		return e
+	}
+	if secs, err := strconv.ParseInt(v, 10, 64); err == nil && secs >= 0 {
+		return e.WithRetryAfter(time.Duration(secs) * time.Second)
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		d := time.Until(t)
+		if d < 0 {
+			d = 0
 		}
+		return e.WithRetryAfter(d)
 	}
-	return errors.Errorf(res.StatusCode, errors.UnknownReason, err.Error())
+	return e
 }
 
 // CodecForResponse get encoding.Codec via http.Response
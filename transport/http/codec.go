@@ -0,0 +1,112 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+
+	"github.com/go-kratos/kratos/v2/encoding"
+	jsoncodec "github.com/go-kratos/kratos/v2/encoding/json"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/internal/httputil"
+	"google.golang.org/protobuf/proto"
+)
+
+// WithDiscardUnknown with the default request decoder to discard the unknown JSON
+// fields, or reject them in the strict mode if false.
+func WithDiscardUnknown(discard bool) HandleOption {
+	return func(o *HandleOptions) {
+		o.jsonCodec().discardUnknown = discard
+	}
+}
+
+// jsonCodec is a json codec configured by the handle options.
+type jsonCodec struct {
+	naming         FieldNaming
+	discardUnknown bool
+}
+
+// jsonCodec returns the json codec of the handle options, which replaces the default
+// request decoder and response encoder, but not the custom ones.
+func (o *HandleOptions) jsonCodec() *jsonCodec {
+	if o.json == nil {
+		o.json = &jsonCodec{discardUnknown: jsoncodec.UnmarshalOptions.DiscardUnknown}
+		o.compose()
+	}
+	return o.json
+}
+
+func (c *jsonCodec) decodeRequest(r *http.Request, v interface{}) error {
+	if codec, ok := CodecForRequest(r, "Content-Type"); !ok || codec.Name() != c.Name() {
+		return DefaultRequestDecoder(r, v)
+	}
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		// e.g. the request entity too large error of the body limit
+		if se := new(errors.Error); errors.As(err, &se) {
+			return se
+		}
+		return errors.BadRequest("CODEC", err.Error())
+	}
+	if err := c.Unmarshal(data, v); err != nil {
+		return errors.BadRequest("CODEC", err.Error())
+	}
+	return nil
+}
+
+func (c *jsonCodec) encodeResponse(w http.ResponseWriter, r *http.Request, v interface{}) error {
+	if codec, _ := CodecForRequest(r, "Accept"); codec.Name() != c.Name() {
+		return DefaultResponseEncoder(w, r, v)
+	}
+	data, err := c.Marshal(v)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", httputil.ContentType(c.Name()))
+	if sc, ok := v.(interface {
+		StatusCode() int
+	}); ok {
+		w.WriteHeader(sc.StatusCode())
+	}
+	_, _ = w.Write(data)
+	return nil
+}
+
+func (c *jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	if m, ok := v.(proto.Message); ok {
+		opts := jsoncodec.MarshalOptions
+		opts.UseProtoNames = c.naming == NamingProto
+		return opts.Marshal(m)
+	}
+	data, err := encoding.GetCodec(jsoncodec.Name).Marshal(v)
+	if err != nil || c.naming != NamingCamelCase {
+		return data, err
+	}
+	return transformKeys(data, reflect.TypeOf(v), true)
+}
+
+func (c *jsonCodec) Unmarshal(data []byte, v interface{}) (err error) {
+	if m, ok := v.(proto.Message); ok {
+		// the proto messages accept both the json names and the proto names.
+		opts := jsoncodec.UnmarshalOptions
+		opts.DiscardUnknown = c.discardUnknown
+		return opts.Unmarshal(data, m)
+	}
+	if c.naming == NamingCamelCase {
+		if data, err = transformKeys(data, reflect.TypeOf(v), false); err != nil {
+			return err
+		}
+	}
+	if c.discardUnknown {
+		return encoding.GetCodec(jsoncodec.Name).Unmarshal(data, v)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
+
+func (c *jsonCodec) Name() string {
+	return jsoncodec.Name
+}
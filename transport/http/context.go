@@ -3,6 +3,10 @@ package http
 import (
 	"context"
 	"net/http"
+	"strings"
+
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/gorilla/mux"
 )
 
 // ServerInfo represent HTTP server information.
@@ -13,8 +17,24 @@ type ServerInfo struct {
 
 type serverKey struct{}
 
-// NewServerContext returns a new Context that carries value.
+// NewServerContext returns a new Context that carries value, the path template
+// of the route matched by the handler as the operation and the request header.
 func NewServerContext(ctx context.Context, info ServerInfo) context.Context {
+	ctx = transport.NewOperationContext(ctx, func(ctx context.Context) string {
+		if route := mux.CurrentRoute(info.Request.WithContext(ctx)); route != nil {
+			// /path/123 -> /path/{id}
+			if path, err := route.GetPathTemplate(); err == nil {
+				return path
+			}
+		}
+		return info.Request.URL.Path
+	})
+	ctx = transport.NewRequestHeaderContext(ctx, func(context.Context) transport.HeaderCarrier {
+		if info.Request == nil {
+			return headerCarrier(nil)
+		}
+		return headerCarrier(info.Request.Header)
+	})
 	return context.WithValue(ctx, serverKey{}, info)
 }
 
@@ -24,6 +44,41 @@ func FromServerContext(ctx context.Context) (info ServerInfo, ok bool) {
 	return
 }
 
+// headerCarrier is the header of the server request.
+type headerCarrier http.Header
+
+func (c headerCarrier) Get(key string) string {
+	return http.Header(c).Get(key)
+}
+
+func (c headerCarrier) Values(key string) []string {
+	return http.Header(c).Values(key)
+}
+
+func (c headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, strings.ToLower(k))
+	}
+	return keys
+}
+
+// header is the response header of the server transport,
+// the trailers are sent by the http.TrailerPrefix.
+type header struct {
+	w http.ResponseWriter
+}
+
+func (h header) SetHeader(key, value string) error {
+	h.w.Header().Set(key, value)
+	return nil
+}
+
+func (h header) SetTrailer(key, value string) error {
+	h.w.Header().Set(http.TrailerPrefix+key, value)
+	return nil
+}
+
 // ClientInfo represent HTTP client information.
 type ClientInfo struct {
 	Request     *http.Request
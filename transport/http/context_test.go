@@ -0,0 +1,83 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+func TestReplyHeader(t *testing.T) {
+	res := httptest.NewRecorder()
+	ctx := transport.NewHeaderContext(context.Background(), header{w: res})
+	if err := transport.SetHeader(ctx, "X-RateLimit-Remaining", "9"); err != nil {
+		t.Fatal(err)
+	}
+	if err := transport.SetTrailer(ctx, "X-Trailer", "trailer"); err != nil {
+		t.Fatal(err)
+	}
+	if err := transport.SetReplyHeader(ctx, "X-Reply", "reply"); err != nil {
+		t.Fatal(err)
+	}
+	if v := res.Header().Get("X-RateLimit-Remaining"); v != "9" {
+		t.Errorf("expected the header got %q", v)
+	}
+	if v := res.Header().Get("Trailer:X-Trailer"); v != "trailer" {
+		t.Errorf("expected the trailer got %q", v)
+	}
+	if v := res.Header().Get("X-Reply"); v != "reply" {
+		t.Errorf("expected the reply header got %q", v)
+	}
+	if err := transport.SetHeader(context.Background(), "X-Header", "header"); err != transport.ErrNoHeader {
+		t.Errorf("expected ErrNoHeader got %v", err)
+	}
+}
+
+func TestOperation(t *testing.T) {
+	srv := NewServer()
+	srv.ctx = context.Background()
+	var operation string
+	srv.HandleFunc("/v1/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		operation = transport.Operation(r.Context())
+	})
+	srv.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/users/1", nil))
+	if operation != "/v1/users/{id}" {
+		t.Fatalf("expected the path template got %q", operation)
+	}
+
+	ctx := NewServerContext(context.Background(), ServerInfo{Request: httptest.NewRequest(http.MethodGet, "/v1/users/1", nil)})
+	if operation := transport.Operation(ctx); operation != "/v1/users/1" {
+		t.Fatalf("expected the path without the route got %q", operation)
+	}
+	if operation := transport.Operation(context.Background()); operation != "" {
+		t.Fatalf("expected no operation got %q", operation)
+	}
+}
+
+func TestRequestHeader(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Add("X-Tenant", "kratos")
+	req.Header.Add("Accept-Language", "en")
+	req.Header.Add("Accept-Language", "zh")
+	ctx := NewServerContext(context.Background(), ServerInfo{Request: req})
+	if v := transport.RequestHeader(ctx, "x-tenant"); v != "kratos" {
+		t.Errorf("expected the case-insensitive header got %q", v)
+	}
+	h, ok := transport.RequestHeaderCarrier(ctx)
+	if !ok {
+		t.Fatal("expected the request header")
+	}
+	if v := h.Values("accept-language"); len(v) != 2 {
+		t.Errorf("expected all the values got %v", v)
+	}
+	for _, k := range h.Keys() {
+		if k != "x-tenant" && k != "accept-language" {
+			t.Errorf("expected the lower case keys got %s", k)
+		}
+	}
+	if v := transport.RequestHeader(context.Background(), "x-tenant"); v != "" {
+		t.Errorf("expected no header without the server request got %q", v)
+	}
+}
@@ -0,0 +1,32 @@
+package http
+
+import (
+	"strings"
+
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/gorilla/mux"
+)
+
+var _ transport.Describer = (*Server)(nil)
+
+// Describe returns the summary of the server, the operations are the routes with
+// the methods, e.g. GET /v1/users/{id}, the middlewares are of the generated
+// handlers so they are not listed.
+func (s *Server) Describe() transport.Description {
+	d := transport.Description{Kind: transport.KindHTTP}
+	if e, err := s.Endpoint(); err == nil {
+		d.Endpoint = e.String()
+	}
+	_ = s.router.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		if methods, err := route.GetMethods(); err == nil {
+			path = strings.Join(methods, ",") + " " + path
+		}
+		d.Operations = append(d.Operations, path)
+		return nil
+	})
+	return d
+}
@@ -0,0 +1,25 @@
+package http
+
+import "net/http"
+
+// FilterFunc is a filter of the HTTP requests before the route matching,
+// e.g. the CORS preflight which no route handles.
+type FilterFunc func(http.Handler) http.Handler
+
+// FilterChain returns a FilterFunc that specifies the chained handler for the filters,
+// the first filter is the outermost one.
+func FilterChain(filters ...FilterFunc) FilterFunc {
+	return func(next http.Handler) http.Handler {
+		for i := len(filters) - 1; i >= 0; i-- {
+			next = filters[i](next)
+		}
+		return next
+	}
+}
+
+// Filter with the HTTP filters, which run with the transport context before the route matching.
+func Filter(filters ...FilterFunc) ServerOption {
+	return func(s *Server) {
+		s.filters = append(s.filters, filters...)
+	}
+}
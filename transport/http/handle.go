@@ -6,6 +6,8 @@ import (
 	"io/ioutil"
 	"net/http"
 	"reflect"
+	"strconv"
+	"time"
 
 	"github.com/go-kratos/kratos/v2/encoding"
 	"github.com/go-kratos/kratos/v2/errors"
@@ -36,6 +38,34 @@ type HandleOptions struct {
 	Encode     EncodeResponseFunc
 	Error      EncodeErrorFunc
 	Middleware middleware.Middleware
+
+	// decode and encode are the custom ones, which win over the json codec.
+	decode       DecodeRequestFunc
+	encode       EncodeResponseFunc
+	json         *jsonCodec
+	queryParsers map[string]binding.QueryParser
+}
+
+// compose sets the Decode and Encode of the options, so the result does not depend
+// on the order of the options.
+func (o *HandleOptions) compose() {
+	dec, enc := o.decode, o.encode
+	if dec == nil {
+		dec = DefaultRequestDecoder
+		if o.json != nil {
+			dec = o.json.decodeRequest
+		}
+	}
+	if enc == nil {
+		enc = DefaultResponseEncoder
+		if o.json != nil {
+			enc = o.json.encodeResponse
+		}
+	}
+	if o.queryParsers != nil {
+		dec = o.parseQuery(dec)
+	}
+	o.Decode, o.Encode = dec, enc
 }
 
 // DefaultHandleOptions returns a default handle options.
@@ -51,14 +81,16 @@ func DefaultHandleOptions() HandleOptions {
 // RequestDecoder with request decoder.
 func RequestDecoder(dec DecodeRequestFunc) HandleOption {
 	return func(o *HandleOptions) {
-		o.Decode = dec
+		o.decode = dec
+		o.compose()
 	}
 }
 
 // ResponseEncoder with response encoder.
 func ResponseEncoder(en EncodeResponseFunc) HandleOption {
 	return func(o *HandleOptions) {
-		o.Encode = en
+		o.encode = en
+		o.compose()
 	}
 }
 
@@ -131,6 +163,24 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	}
 }
 
+// WrapHandler wraps a raw HTTP handler such as a webhook receiver or a file server
+// with the middleware chain, the request passed to the middleware is the *http.Request,
+// and the error returned by the middleware is encoded by the DefaultErrorEncoder.
+func WrapHandler(h http.Handler, m ...middleware.Middleware) http.Handler {
+	chain := middleware.Chain(m...)
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var served bool
+		next := chain(func(ctx context.Context, _ interface{}) (interface{}, error) {
+			served = true
+			h.ServeHTTP(w, req.WithContext(ctx))
+			return nil, nil
+		})
+		if _, err := next(req.Context(), req); err != nil && !served {
+			DefaultErrorEncoder(w, req, err)
+		}
+	})
+}
+
 func validateHandler(handler interface{}) error {
 	typ := reflect.TypeOf(handler)
 	if typ.NumIn() != 2 || typ.NumOut() != 2 {
@@ -154,6 +204,10 @@ func DefaultRequestDecoder(r *http.Request, v interface{}) error {
 	if ok {
 		data, err := ioutil.ReadAll(r.Body)
 		if err != nil {
+			// e.g. the request entity too large error of the body limit
+			if se := new(errors.Error); errors.As(err, &se) {
+				return se
+			}
 			return errors.BadRequest("CODEC", err.Error())
 		}
 		if err := codec.Unmarshal(data, v); err != nil {
@@ -161,6 +215,9 @@ func DefaultRequestDecoder(r *http.Request, v interface{}) error {
 		}
 	} else {
 		if err := binding.BindForm(r, v); err != nil {
+			if se := new(errors.Error); errors.As(err, &se) {
+				return se
+			}
 			return errors.BadRequest("CODEC", err.Error())
 		}
 	}
@@ -193,6 +250,10 @@ func DefaultErrorEncoder(w http.ResponseWriter, r *http.Request, se error) {
 		return
 	}
 	w.Header().Set("Content-Type", httputil.ContentType(codec.Name()))
+	if d, ok := errors.RetryAfter(se); ok {
+		// Retry-After only carries the whole seconds, round up not to retry too early.
+		w.Header().Set("Retry-After", strconv.FormatInt(int64((d+time.Second-1)/time.Second), 10))
+	}
 	if sc, ok := se.(interface {
 		StatusCode() int
 	}); ok {
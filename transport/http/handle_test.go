@@ -2,7 +2,15 @@ package http
 
 import (
 	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
 )
 
 type HelloRequest struct {
@@ -22,3 +30,54 @@ func TestHandler(t *testing.T) {
 	s := &GreeterService{}
 	_ = NewHandler(s.SayHello)
 }
+
+func TestWrapHandler(t *testing.T) {
+	auth := func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			if req.(*http.Request).Header.Get("Authorization") == "" {
+				return nil, errors.Unauthorized("UNAUTHORIZED", "missing token")
+			}
+			return handler(ctx, req)
+		}
+	}
+	h := WrapHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}), auth)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/webhook", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected %d got %d", http.StatusUnauthorized, w.Code)
+	}
+	w = httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/webhook", nil)
+	r.Header.Set("Authorization", "token")
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("expected %d got %d", http.StatusAccepted, w.Code)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	DefaultErrorEncoder(w, req, errors.New(http.StatusTooManyRequests, "RATE_LIMITED", "rate limited").WithRetryAfter(1500*time.Millisecond))
+	res := w.Result()
+	if v := res.Header.Get("Retry-After"); v != "2" {
+		t.Fatalf("got Retry-After %q want 2", v)
+	}
+	err := DefaultErrorDecoder(context.Background(), res)
+	if d, ok := errors.RetryAfter(err); !ok || d != 1500*time.Millisecond || errors.Code(err) != http.StatusTooManyRequests {
+		t.Fatalf("got %v retry after %v %v", err, d, ok)
+	}
+
+	// the header alone is understood as well, e.g. from the proxies
+	res = &http.Response{
+		StatusCode: http.StatusServiceUnavailable,
+		Header:     http.Header{"Retry-After": []string{"3"}},
+		Body:       ioutil.NopCloser(strings.NewReader("unavailable")),
+	}
+	if d, ok := errors.RetryAfter(DefaultErrorDecoder(context.Background(), res)); !ok || d != 3*time.Second {
+		t.Fatalf("got retry after %v %v", d, ok)
+	}
+}
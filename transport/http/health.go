@@ -0,0 +1,77 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// HealthCheck with the health service mirrored by the /healthz and /readyz endpoints,
+// e.g. the HealthServer of the gRPC server, so the HTTP probes of the platforms report
+// the same serving status as the gRPC health checks. The service is checked by the
+// service query parameter, the default is the empty one of the whole server.
+func HealthCheck(h grpc_health_v1.HealthServer) ServerOption {
+	return func(s *Server) {
+		s.health = h
+	}
+}
+
+// ReadinessCheck with the checks of the /readyz endpoint besides the health service,
+// e.g. the Ready of the App, which reports ready once the servers are started and the
+// instance is registered, and not ready once it is stopped or drained.
+func ReadinessCheck(checks ...func(context.Context) error) ServerOption {
+	return func(s *Server) {
+		s.readiness = append(s.readiness, checks...)
+	}
+}
+
+type healthStatus struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// healthz reports 200 if the service is serving, otherwise 503.
+func (s *Server) healthz(w http.ResponseWriter, r *http.Request) {
+	status := s.servingStatus(r)
+	writeHealth(w, healthStatus{Status: status.String()}, status == grpc_health_v1.HealthCheckResponse_SERVING)
+}
+
+// readyz reports 200 if the service is serving and all the readiness checks pass, otherwise 503.
+func (s *Server) readyz(w http.ResponseWriter, r *http.Request) {
+	status := s.servingStatus(r)
+	if status != grpc_health_v1.HealthCheckResponse_SERVING {
+		writeHealth(w, healthStatus{Status: status.String()}, false)
+		return
+	}
+	for _, check := range s.readiness {
+		if err := check(r.Context()); err != nil {
+			writeHealth(w, healthStatus{Status: "NOT_READY", Error: err.Error()}, false)
+			return
+		}
+	}
+	writeHealth(w, healthStatus{Status: status.String()}, true)
+}
+
+func (s *Server) servingStatus(r *http.Request) grpc_health_v1.HealthCheckResponse_ServingStatus {
+	if s.health == nil {
+		// the server is serving as long as it responds
+		return grpc_health_v1.HealthCheckResponse_SERVING
+	}
+	res, err := s.health.Check(r.Context(), &grpc_health_v1.HealthCheckRequest{Service: r.URL.Query().Get("service")})
+	if err != nil {
+		// e.g. the unknown service
+		return grpc_health_v1.HealthCheckResponse_SERVICE_UNKNOWN
+	}
+	return res.Status
+}
+
+func writeHealth(w http.ResponseWriter, status healthStatus, ok bool) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if !ok {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_ = json.NewEncoder(w).Encode(status)
+}
@@ -0,0 +1,47 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestHealthCheck(t *testing.T) {
+	var ready error
+	h := health.NewServer()
+	srv := NewServer(HealthCheck(h), ReadinessCheck(func(context.Context) error { return ready }))
+	probe := func(path string) int {
+		w := httptest.NewRecorder()
+		srv.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w.Code
+	}
+	tests := []struct {
+		path string
+		code int
+	}{
+		{"/healthz", http.StatusOK},
+		{"/readyz", http.StatusOK},
+		{"/healthz?service=unknown", http.StatusServiceUnavailable},
+	}
+	for _, test := range tests {
+		if code := probe(test.path); code != test.code {
+			t.Errorf("got %d of %s want %d", code, test.path, test.code)
+		}
+	}
+
+	// the readiness checks fail the readiness only
+	ready = errors.New("not registered")
+	if probe("/healthz") != http.StatusOK || probe("/readyz") != http.StatusServiceUnavailable {
+		t.Error("expected healthy but not ready")
+	}
+	ready = nil
+	h.SetServingStatus("", grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+	if probe("/healthz") != http.StatusServiceUnavailable || probe("/readyz") != http.StatusServiceUnavailable {
+		t.Error("expected not serving")
+	}
+}
@@ -0,0 +1,192 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// FieldNaming is the naming of the JSON fields.
+type FieldNaming int
+
+const (
+	// NamingJSON uses the lowerCamelCase json names for proto messages,
+	// and the struct tags for others, it is the default naming.
+	NamingJSON FieldNaming = iota
+	// NamingProto uses the original snake_case field names for proto messages.
+	NamingProto
+	// NamingCamelCase uses the lowerCamelCase json names for proto messages,
+	// and transforms the snake_case keys of others to lowerCamelCase.
+	NamingCamelCase
+)
+
+// JSONFieldNaming with the JSON field naming, which applies to both the default
+// request decoder and response encoder, but not the custom ones.
+func JSONFieldNaming(n FieldNaming) HandleOption {
+	return func(o *HandleOptions) {
+		o.jsonCodec().naming = n
+	}
+}
+
+var (
+	marshalerType   = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	fieldCache      sync.Map
+)
+
+// transformKeys renames the keys of the struct fields in the JSON of the value of type t,
+// to lowerCamelCase if camel, or back to the json names otherwise. The keys of the maps
+// are the user data, so they are kept as is.
+func transformKeys(data []byte, t reflect.Type, camel bool) ([]byte, error) {
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return json.Marshal(transform(v, t, camel))
+}
+
+func transform(v interface{}, t reflect.Type, camel bool) interface{} {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || reflect.PtrTo(t).Implements(marshalerType) || reflect.PtrTo(t).Implements(unmarshalerType) {
+		// the custom json or the dynamic type of the interface
+		return v
+	}
+	switch x := v.(type) {
+	case map[string]interface{}:
+		switch t.Kind() {
+		case reflect.Struct:
+			fields := jsonFields(t)
+			m := make(map[string]interface{}, len(x))
+			for k, v := range x {
+				name, ft := fields.lookup(k, camel)
+				m[name] = transform(v, ft, camel)
+			}
+			return m
+		case reflect.Map:
+			for k, v := range x {
+				x[k] = transform(v, t.Elem(), camel)
+			}
+		}
+	case []interface{}:
+		if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+			for i, v := range x {
+				x[i] = transform(v, t.Elem(), camel)
+			}
+		}
+	}
+	return v
+}
+
+// fields is the types of the struct fields by the json names.
+type fields map[string]reflect.Type
+
+// lookup returns the renamed key and the type of the field, the key is kept if it is
+// not a field. The key from the client is matched by the lowerCamelCase of the json
+// name, or by its snake_case, e.g. userID of the json name user_id.
+func (f fields) lookup(key string, camel bool) (string, reflect.Type) {
+	if camel {
+		if t, ok := f[key]; ok {
+			return camelCase(key), t
+		}
+		return key, nil
+	}
+	if t, ok := f[key]; ok {
+		return key, t
+	}
+	for name, t := range f {
+		if camelCase(name) == key {
+			return name, t
+		}
+	}
+	if name := snakeCase(key); f[name] != nil {
+		return name, f[name]
+	}
+	return key, nil
+}
+
+// jsonFields returns the fields of the struct by the json names,
+// including the fields of the embedded structs.
+func jsonFields(t reflect.Type) fields {
+	if f, ok := fieldCache.Load(t); ok {
+		return f.(fields)
+	}
+	f := make(fields)
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if sf.Anonymous && name == "" {
+			ft := sf.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				for k, v := range jsonFields(ft) {
+					if _, ok := f[k]; !ok {
+						f[k] = v
+					}
+				}
+				continue
+			}
+		}
+		if sf.PkgPath != "" {
+			// unexported
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		f[name] = sf.Type
+	}
+	fieldCache.Store(t, f)
+	return f
+}
+
+// camelCase converts the snake_case name to lowerCamelCase.
+func camelCase(s string) string {
+	var (
+		b     strings.Builder
+		upper bool
+	)
+	for _, r := range s {
+		if r == '_' {
+			upper = b.Len() > 0
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// snakeCase converts the lowerCamelCase name to snake_case,
+// the runs of the uppercase letters are single words, e.g. userID is user_id.
+func snakeCase(s string) string {
+	var (
+		b     strings.Builder
+		runes = []rune(s)
+	)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (!unicode.IsUpper(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) && runes[i-1] != '_' {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
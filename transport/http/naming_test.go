@@ -0,0 +1,127 @@
+package http
+
+import (
+	"net/http"
+	"testing"
+)
+
+type testNaming struct {
+	UserName string `json:"user_name"`
+}
+
+func TestJSONCodecCamelCase(t *testing.T) {
+	codec := &jsonCodec{naming: NamingCamelCase, discardUnknown: true}
+	data, err := codec.Marshal(&testNaming{UserName: "kratos"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"userName":"kratos"}` {
+		t.Fatalf("unexpected json: %s", data)
+	}
+	var v testNaming
+	if err := codec.Unmarshal(data, &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.UserName != "kratos" {
+		t.Fatalf("expected kratos got %s", v.UserName)
+	}
+}
+
+func TestJSONCodecDiscardUnknown(t *testing.T) {
+	var v testNaming
+	codec := &jsonCodec{discardUnknown: false}
+	if err := codec.Unmarshal([]byte(`{"user_name":"kratos","unknown":1}`), &v); err == nil {
+		t.Fatal("expected the unknown field to be rejected")
+	}
+	codec.discardUnknown = true
+	if err := codec.Unmarshal([]byte(`{"user_name":"kratos","unknown":1}`), &v); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type testNamingNested struct {
+	UserID string                 `json:"user_id"`
+	Labels map[string]string      `json:"label_set"`
+	Items  []*testNaming          `json:"item_list"`
+	Extra  map[string]interface{} `json:"extra_data"`
+}
+
+func TestJSONCodecCamelCaseFields(t *testing.T) {
+	codec := &jsonCodec{naming: NamingCamelCase, discardUnknown: true}
+	in := &testNamingNested{
+		UserID: "1",
+		Labels: map[string]string{"app_name": "kratos"},
+		Items:  []*testNaming{{UserName: "kratos"}},
+		Extra:  map[string]interface{}{"raw_key": 1},
+	}
+	data, err := codec.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"extraData":{"raw_key":1},"itemList":[{"userName":"kratos"}],"labelSet":{"app_name":"kratos"},"userId":"1"}`
+	if string(data) != expected {
+		t.Fatalf("expected %s got %s", expected, data)
+	}
+	tests := []string{
+		string(data),
+		`{"userID":"1","labelSet":{"app_name":"kratos"},"itemList":[{"userName":"kratos"}],"extraData":{"raw_key":1}}`,
+		`{"user_id":"1","label_set":{"app_name":"kratos"},"item_list":[{"user_name":"kratos"}],"extra_data":{"raw_key":1}}`,
+	}
+	for _, test := range tests {
+		var out testNamingNested
+		if err := codec.Unmarshal([]byte(test), &out); err != nil {
+			t.Fatal(err)
+		}
+		if out.UserID != "1" || out.Labels["app_name"] != "kratos" || len(out.Items) != 1 ||
+			out.Items[0].UserName != "kratos" || out.Extra["raw_key"] == nil {
+			t.Errorf("unexpected %+v of %s", out, test)
+		}
+	}
+}
+
+func TestSnakeCase(t *testing.T) {
+	tests := []struct {
+		in  string
+		out string
+	}{
+		{"userName", "user_name"},
+		{"ID", "id"},
+		{"userID", "user_id"},
+		{"IDCard", "id_card"},
+		{"httpServerURL", "http_server_url"},
+		{"user_name", "user_name"},
+	}
+	for _, test := range tests {
+		if out := snakeCase(test.in); out != test.out {
+			t.Errorf("%s: expected %s got %s", test.in, test.out, out)
+		}
+	}
+}
+
+func TestJSONCodecOptionOrder(t *testing.T) {
+	var decoded string
+	dec := func(r *http.Request, v interface{}) error {
+		decoded = "custom"
+		return nil
+	}
+	tests := [][]HandleOption{
+		{RequestDecoder(dec), JSONFieldNaming(NamingCamelCase), WithDiscardUnknown(false)},
+		{JSONFieldNaming(NamingCamelCase), WithDiscardUnknown(false), RequestDecoder(dec)},
+	}
+	for _, opts := range tests {
+		decoded = ""
+		o := HandleOptions{}
+		for _, opt := range opts {
+			opt(&o)
+		}
+		if o.json == nil || o.json.naming != NamingCamelCase || o.json.discardUnknown {
+			t.Fatalf("unexpected json codec: %+v", o.json)
+		}
+		if err := o.Decode(&http.Request{}, nil); err != nil {
+			t.Fatal(err)
+		}
+		if decoded != "custom" {
+			t.Errorf("expected the custom decoder to win over the json codec")
+		}
+	}
+}
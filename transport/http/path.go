@@ -0,0 +1,73 @@
+package http
+
+import (
+	"net/http"
+	"path"
+	"strings"
+)
+
+// TrailingSlash is the policy of the trailing slash of the path normalization.
+type TrailingSlash int
+
+const (
+	// TrailingSlashKeep keeps the trailing slash as it is.
+	TrailingSlashKeep TrailingSlash = iota
+	// TrailingSlashStrip strips the trailing slash.
+	TrailingSlashStrip
+	// TrailingSlashAdd adds the trailing slash.
+	TrailingSlashAdd
+)
+
+// NormalizePath with the path normalization before the route matching, which collapses
+// the duplicate slashes, resolves the dot segments and applies the trailing slash policy.
+// The GET and HEAD requests are redirected with 301 if redirect is true, so the clients
+// learn the canonical path, the others are always rewritten since the redirect drops the body.
+func NormalizePath(slash TrailingSlash, redirect bool) ServerOption {
+	return func(s *Server) {
+		s.normalize = &pathNormalizer{slash: slash, redirect: redirect}
+	}
+}
+
+type pathNormalizer struct {
+	slash    TrailingSlash
+	redirect bool
+}
+
+// normalize returns whether the request is handled by a redirect.
+func (n *pathNormalizer) normalize(w http.ResponseWriter, r *http.Request) bool {
+	p := normalizePath(r.URL.Path, n.slash)
+	if p == r.URL.Path {
+		return false
+	}
+	if n.redirect && (r.Method == http.MethodGet || r.Method == http.MethodHead) {
+		u := *r.URL
+		u.Path = p
+		u.RawPath = ""
+		http.Redirect(w, r, u.String(), http.StatusMovedPermanently)
+		return true
+	}
+	r.URL.Path = p
+	r.URL.RawPath = ""
+	return false
+}
+
+func normalizePath(p string, slash TrailingSlash) string {
+	if p == "" {
+		return "/"
+	}
+	trailing := strings.HasSuffix(p, "/")
+	p = path.Clean("/" + p)
+	if p == "/" {
+		return p
+	}
+	switch slash {
+	case TrailingSlashAdd:
+		trailing = true
+	case TrailingSlashStrip:
+		trailing = false
+	}
+	if trailing {
+		p += "/"
+	}
+	return p
+}
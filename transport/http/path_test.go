@@ -0,0 +1,23 @@
+package http
+
+import "testing"
+
+func TestNormalizePath(t *testing.T) {
+	tests := []struct {
+		path  string
+		slash TrailingSlash
+		want  string
+	}{
+		{"", TrailingSlashKeep, "/"},
+		{"//users//1", TrailingSlashKeep, "/users/1"},
+		{"/users/1/", TrailingSlashKeep, "/users/1/"},
+		{"/users//1//", TrailingSlashStrip, "/users/1"},
+		{"/users/./1/../2", TrailingSlashAdd, "/users/2/"},
+		{"/", TrailingSlashStrip, "/"},
+	}
+	for _, test := range tests {
+		if got := normalizePath(test.path, test.slash); got != test.want {
+			t.Errorf("%q: expected %q got %q", test.path, test.want, got)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+// Package problem encodes the errors of the HTTP server as the problem details of RFC 7807.
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware/i18n"
+	transhttp "github.com/go-kratos/kratos/v2/transport/http"
+)
+
+// ContentType is the content type of the problem details.
+const ContentType = "application/problem+json"
+
+// Problem is the problem details of RFC 7807, with the reason and the metadata
+// of the error as the extension members.
+type Problem struct {
+	Type     string            `json:"type"`
+	Title    string            `json:"title"`
+	Status   int               `json:"status"`
+	Detail   string            `json:"detail,omitempty"`
+	Instance string            `json:"instance,omitempty"`
+	Reason   string            `json:"reason,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// Option is problem option.
+type Option func(*options)
+
+type options struct {
+	typeBase string
+	catalog  i18n.Catalog
+	locales  []string
+}
+
+// WithTypeBase with the base URI of the problem types, the type is the base followed by
+// the reason in the kebab case, e.g. https://example.com/problems/user-not-found,
+// the default is about:blank.
+func WithTypeBase(base string) Option {
+	return func(o *options) {
+		o.typeBase = base
+	}
+}
+
+// WithCatalog with the message catalog which localizes the detail by the locale of the
+// request context set by the i18n middleware, or the Accept-Language of the request.
+func WithCatalog(c i18n.Catalog) Option {
+	return func(o *options) {
+		o.catalog = c
+		o.locales = make([]string, 0, len(c))
+		for locale := range c {
+			o.locales = append(o.locales, i18n.Normalize(locale))
+		}
+		sort.Strings(o.locales)
+	}
+}
+
+// NewErrorEncoder returns an error encoder of the HTTP server which encodes the errors as
+// application/problem+json, e.g. http.ErrorEncoder(problem.NewErrorEncoder()).
+// The errors which are not kratos errors are encoded as the generic 500 problems,
+// so the internal details are not leaked.
+func NewErrorEncoder(opts ...Option) transhttp.EncodeErrorFunc {
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return func(w http.ResponseWriter, r *http.Request, err error) {
+		p, locale := o.problem(r, err)
+		body, err := json.Marshal(p)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", ContentType)
+		if d, ok := errors.RetryAfter(err); ok {
+			w.Header().Set("Retry-After", strconv.FormatInt(int64((d+time.Second-1)/time.Second), 10))
+		}
+		if locale != "" {
+			w.Header().Set("Content-Language", locale)
+		}
+		w.WriteHeader(p.Status)
+		_, _ = w.Write(body)
+	}
+}
+
+// problem returns the problem of the error, and the locale of the detail if localized.
+func (o *options) problem(r *http.Request, err error) (*Problem, string) {
+	var locale string
+	if o.catalog != nil {
+		ctx := r.Context()
+		locale, _ = i18n.FromContext(ctx)
+		if locale == "" {
+			locale = i18n.Match(i18n.Parse(r.Header.Get("Accept-Language")), o.locales)
+			ctx = i18n.NewContext(ctx, locale)
+		}
+		if _, ok := o.catalog.Message(locale, errors.Reason(err)); ok {
+			err = o.catalog.Localize(ctx, err)
+		} else {
+			locale = ""
+		}
+	}
+	se := errors.FromError(err)
+	p := &Problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(int(se.Code)),
+		Status:   int(se.Code),
+		Detail:   se.Message,
+		Instance: r.URL.Path,
+		Reason:   se.Reason,
+		Metadata: se.Metadata,
+	}
+	if p.Title == "" {
+		// e.g. 499 of the client closed request
+		p.Title = http.StatusText(http.StatusInternalServerError)
+		if p.Status < 500 {
+			p.Title = http.StatusText(http.StatusBadRequest)
+		}
+	}
+	if target := new(errors.Error); !errors.As(err, &target) && p.Status == errors.UnknownCode {
+		p.Detail = ""
+	}
+	if se.Reason != "" && o.typeBase != "" {
+		p.Type = strings.TrimSuffix(o.typeBase, "/") + "/" + strings.ToLower(strings.ReplaceAll(se.Reason, "_", "-"))
+	}
+	return p, locale
+}
@@ -0,0 +1,81 @@
+package problem
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware/i18n"
+)
+
+func TestErrorEncoder(t *testing.T) {
+	enc := NewErrorEncoder(
+		WithTypeBase("https://example.com/problems/"),
+		WithCatalog(i18n.Catalog{"zh-CN": {"USER_NOT_FOUND": "用户 {name} 不存在"}}),
+	)
+	tests := []struct {
+		err      error
+		language string
+		want     Problem
+		locale   string
+	}{
+		{
+			err:      errors.NotFound("USER_NOT_FOUND", "user kratos not found").WithMetadata(map[string]string{"name": "kratos"}),
+			language: "zh-CN,en;q=0.8",
+			want: Problem{
+				Type:     "https://example.com/problems/user-not-found",
+				Title:    "Not Found",
+				Status:   404,
+				Detail:   "用户 kratos 不存在",
+				Instance: "/v1/users/kratos",
+				Reason:   "USER_NOT_FOUND",
+				Metadata: map[string]string{"name": "kratos"},
+			},
+			locale: "zh-CN",
+		},
+		{
+			err:      errors.NotFound("USER_NOT_FOUND", "user kratos not found"),
+			language: "en",
+			want: Problem{
+				Type:     "https://example.com/problems/user-not-found",
+				Title:    "Not Found",
+				Status:   404,
+				Detail:   "user kratos not found",
+				Instance: "/v1/users/kratos",
+				Reason:   "USER_NOT_FOUND",
+			},
+		},
+		{
+			// the internal details are not leaked
+			err: fmt.Errorf("dial tcp 10.0.0.1:3306: connection refused"),
+			want: Problem{
+				Type:     "about:blank",
+				Title:    "Internal Server Error",
+				Status:   500,
+				Instance: "/v1/users/kratos",
+			},
+		},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/v1/users/kratos", nil)
+		req.Header.Set("Accept-Language", test.language)
+		w := httptest.NewRecorder()
+		enc(w, req, test.err)
+		if ct := w.Header().Get("Content-Type"); ct != ContentType {
+			t.Fatalf("got content type %s", ct)
+		}
+		if l := w.Header().Get("Content-Language"); l != test.locale {
+			t.Errorf("got content language %q want %q", l, test.locale)
+		}
+		var got Problem
+		if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+			t.Fatal(err)
+		}
+		if w.Code != test.want.Status || fmt.Sprint(got) != fmt.Sprint(test.want) {
+			t.Errorf("got %d %+v want %+v", w.Code, got, test.want)
+		}
+	}
+}
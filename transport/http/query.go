@@ -0,0 +1,38 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/transport/http/binding"
+)
+
+// WithQueryParser with the parser of the query parameter in the custom format, e.g.
+//
+//	WithQueryParser("ids", binding.CommaSeparated)
+//
+// binds ids=1,2,3 to the repeated field ids. The key is the query parameter,
+// and the parsed values are bound as the values of the query.
+func WithQueryParser(key string, parse binding.QueryParser) HandleOption {
+	return func(o *HandleOptions) {
+		if o.queryParsers == nil {
+			o.queryParsers = make(map[string]binding.QueryParser)
+		}
+		o.queryParsers[key] = parse
+		o.compose()
+	}
+}
+
+// parseQuery returns the request decoder which parses the query by the parsers
+// before the decoding, the form is parsed in advance and bound by the decoder.
+func (o *HandleOptions) parseQuery(dec DecodeRequestFunc) DecodeRequestFunc {
+	return func(r *http.Request, v interface{}) error {
+		if err := r.ParseForm(); err != nil {
+			return errors.BadRequest("CODEC", err.Error())
+		}
+		if err := binding.ParseQuery(r.Form, o.queryParsers); err != nil {
+			return errors.BadRequest("CODEC", err.Error())
+		}
+		return dec(r, v)
+	}
+}
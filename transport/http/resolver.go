@@ -14,6 +14,9 @@ type Target struct {
 	Scheme    string
 	Authority string
 	Endpoint  string
+	// Query filters the instances by version and metadata,
+	// e.g. discovery:///user?version=v1&tag=canary
+	Query url.Values
 }
 
 func parseTarget(endpoint string) (*Target, error) {
@@ -23,7 +26,7 @@ func parseTarget(endpoint string) (*Target, error) {
 			return nil, err
 		}
 	}
-	target := &Target{Scheme: u.Scheme, Authority: u.Host}
+	target := &Target{Scheme: u.Scheme, Authority: u.Host, Query: u.Query()}
 	if len(u.Path) > 1 {
 		target.Endpoint = u.Path[1:]
 	}
@@ -58,6 +61,9 @@ func newResolver(ctx context.Context, discovery registry.Discovery, target *Targ
 			}
 			var nodes []*registry.ServiceInstance
 			for _, in := range services {
+				if !match(in, target.Query) {
+					continue
+				}
 				_, endpoint, err := parseEndpoint(in.Endpoints)
 				if err != nil {
 					r.logger.Errorf("Failed to parse discovery endpoint: %v error %v", in.Endpoints, err)
@@ -85,6 +91,24 @@ func (r *resolver) fetch(ctx context.Context) []*registry.ServiceInstance {
 	return nodes
 }
 
+// match reports whether the instance matches the query,
+// the version key matches the instance version and others match the metadata.
+func match(in *registry.ServiceInstance, query url.Values) bool {
+	for k := range query {
+		v := query.Get(k)
+		if k == "version" {
+			if in.Version != v {
+				return false
+			}
+			continue
+		}
+		if in.Metadata[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
 func parseEndpoint(endpoints []string) (string, string, error) {
 	for _, e := range endpoints {
 		u, err := url.Parse(e)
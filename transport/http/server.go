@@ -2,23 +2,29 @@ package http
 
 import (
 	"context"
-	"errors"
+	"crypto/tls"
+	"fmt"
 	"net"
 	"net/http"
 	"net/url"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/go-kratos/kratos/v2/errors"
 	ic "github.com/go-kratos/kratos/v2/internal/context"
 	"github.com/go-kratos/kratos/v2/internal/host"
 	"github.com/go-kratos/kratos/v2/log"
 	"github.com/go-kratos/kratos/v2/transport"
 
 	"github.com/gorilla/mux"
+	"google.golang.org/grpc/health/grpc_health_v1"
 )
 
 var _ transport.Server = (*Server)(nil)
 var _ transport.Endpointer = (*Server)(nil)
+var _ transport.Drainer = (*Server)(nil)
 
 // ServerOption is an HTTP server option.
 type ServerOption func(*Server)
@@ -37,6 +43,16 @@ func Address(addr string) ServerOption {
 	}
 }
 
+// Endpoint with the advertised endpoint to the registry, e.g. http://10.0.0.1:8000, instead of
+// the one extracted from the address, which is useful when the server is behind the NAT
+// or in a container and the bind address is not routable.
+func Endpoint(endpoint *url.URL) ServerOption {
+	return func(s *Server) {
+		s.endpoint = endpoint
+		s.advertised = true
+	}
+}
+
 // Timeout with server timeout.
 func Timeout(timeout time.Duration) ServerOption {
 	return func(s *Server) {
@@ -51,6 +67,48 @@ func Logger(logger log.Logger) ServerOption {
 	}
 }
 
+// TLSConfig with the TLS config of the server, use transport.CertReloader
+// to reload the certificate without restart.
+func TLSConfig(c *tls.Config) ServerOption {
+	return func(s *Server) {
+		s.tlsConf = c
+	}
+}
+
+// ShutdownTimeout with the max time to wait for the in-flight requests on stop,
+// the default zero waits until they finish or the deadline of the stop context.
+func ShutdownTimeout(timeout time.Duration) ServerOption {
+	return func(s *Server) {
+		s.shutdownTimeout = timeout
+	}
+}
+
+// ForceClose with whether to close the connections of the remaining requests
+// once the shutdown timeout exceeded.
+func ForceClose(force bool) ServerOption {
+	return func(s *Server) {
+		s.forceClose = force
+	}
+}
+
+// LameDuckDuration with the lame duck period on stop before the shutdown, during which the
+// keep-alives are disabled and the new requests fail with 503 Service Unavailable, so the
+// clients retry on other instances while the in-flight requests finish.
+func LameDuckDuration(d time.Duration) ServerOption {
+	return func(s *Server) {
+		s.lameDuck = d
+	}
+}
+
+// GRPCWeb with the handler of the gRPC-Web requests, e.g. the WebHandler of the gRPC server,
+// which serves the requests of the application/grpc-web content types and their CORS
+// preflights before the routes, so the browsers call the gRPC services via the HTTP server.
+func GRPCWeb(h http.Handler) ServerOption {
+	return func(s *Server) {
+		s.grpcWeb = h
+	}
+}
+
 // Server is an HTTP server wrapper.
 type Server struct {
 	*http.Server
@@ -63,7 +121,26 @@ type Server struct {
 	endpoint *url.URL
 	timeout  time.Duration
 	router   *mux.Router
+	handler  http.Handler
+	filters  []FilterFunc
 	log      *log.Helper
+	tlsConf  *tls.Config
+	inflight int32
+
+	advertised      bool
+	shutdownTimeout time.Duration
+	forceClose      bool
+	lameDuck        time.Duration
+	lameDucking     int32
+	normalize       *pathNormalizer
+	grpcWeb         http.Handler
+	health          grpc_health_v1.HealthServer
+	readiness       []func(context.Context) error
+
+	// mu guards the listener and the endpoint which are replaced by Migrate.
+	mu      sync.RWMutex
+	served  chan error
+	retired map[net.Listener]struct{}
 }
 
 // NewServer creates an HTTP server by options.
@@ -78,11 +155,22 @@ func NewServer(opts ...ServerOption) *Server {
 		o(srv)
 	}
 	srv.router = mux.NewRouter()
-	srv.Server = &http.Server{Handler: srv}
+	if srv.normalize != nil {
+		// the normalizer cleans the path instead of the redirect of the router
+		srv.router.SkipClean(true)
+	}
+	if srv.health != nil || len(srv.readiness) > 0 {
+		srv.router.HandleFunc("/healthz", srv.healthz).Methods("GET", "HEAD")
+		srv.router.HandleFunc("/readyz", srv.readyz).Methods("GET", "HEAD")
+	}
+	srv.handler = FilterChain(srv.filters...)(srv.router)
+	srv.Server = &http.Server{Handler: srv, TLSConfig: srv.tlsConf}
 	return srv
 }
 
 // Handle registers a new route with a matcher for the URL path.
+// The raw routes share the transport context and the timeout of the server,
+// but not the middleware of the generated handlers, use WrapHandler to apply them.
 func (s *Server) Handle(path string, h http.Handler) {
 	s.router.Handle(path, h)
 }
@@ -99,15 +187,42 @@ func (s *Server) HandleFunc(path string, h http.HandlerFunc) {
 
 // ServeHTTP should write reply headers and data to the ResponseWriter and then return.
 func (s *Server) ServeHTTP(res http.ResponseWriter, req *http.Request) {
+	if atomic.LoadInt32(&s.lameDucking) == 1 {
+		res.Header().Set("Connection", "close")
+		DefaultErrorEncoder(res, req, errors.ServiceUnavailable("LAME_DUCK", "the server is shutting down"))
+		return
+	}
+	if s.normalize != nil && s.normalize.normalize(res, req) {
+		return
+	}
+	atomic.AddInt32(&s.inflight, 1)
+	defer atomic.AddInt32(&s.inflight, -1)
+	if s.grpcWeb != nil && isGRPCWeb(req) {
+		s.grpcWeb.ServeHTTP(res, req)
+		return
+	}
 	ctx, cancel := ic.Merge(req.Context(), s.ctx)
 	defer cancel()
-	ctx = transport.NewContext(ctx, transport.Transport{Kind: transport.KindHTTP, Endpoint: s.endpoint.String()})
+	ctx = transport.NewStartContext(ctx, time.Now())
+	ctx = transport.NewContext(ctx, transport.Transport{Kind: transport.KindHTTP, Endpoint: s.endpointString(), Method: req.Method})
 	ctx = NewServerContext(ctx, ServerInfo{Request: req, Response: res})
+	ctx = transport.NewHeaderContext(ctx, header{w: res})
 	if s.timeout > 0 {
-		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		// the handlers can extend the timeout by transport.ExtendDeadline
+		dctx, cancel := ic.WithExtendableDeadline(ctx, time.Now().Add(s.timeout))
 		defer cancel()
+		ctx = transport.NewDeadlineContext(dctx, dctx)
 	}
-	s.router.ServeHTTP(res, req.WithContext(ctx))
+	s.handler.ServeHTTP(res, req.WithContext(ctx))
+}
+
+func isGRPCWeb(r *http.Request) bool {
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc-web") {
+		return true
+	}
+	// the CORS preflight of the gRPC-Web clients
+	return r.Method == http.MethodOptions &&
+		strings.Contains(strings.ToLower(r.Header.Get("Access-Control-Request-Headers")), "x-grpc-web")
 }
 
 // Endpoint return a real address to registry endpoint.
@@ -120,7 +235,11 @@ func (s *Server) Endpoint() (*url.URL, error) {
 			s.err = err
 			return
 		}
-		addr, err := host.Extract(s.address, s.lis)
+		if s.endpoint != nil {
+			s.lis = lis
+			return
+		}
+		addr, err := host.Extract(s.address, lis)
 		if err != nil {
 			lis.Close()
 			s.err = err
@@ -132,9 +251,21 @@ func (s *Server) Endpoint() (*url.URL, error) {
 	if s.err != nil {
 		return nil, s.err
 	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	return s.endpoint, nil
 }
 
+func (s *Server) endpointString() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.endpoint == nil {
+		// served before Endpoint or Start, e.g. by the tests
+		return ""
+	}
+	return s.endpoint.String()
+}
+
 // Start start the HTTP server.
 func (s *Server) Start(ctx context.Context) error {
 	if _, err := s.Endpoint(); err != nil {
@@ -142,14 +273,109 @@ func (s *Server) Start(ctx context.Context) error {
 	}
 	s.ctx = ctx
 	s.log.Infof("[HTTP] server listening on: %s", s.lis.Addr().String())
-	if err := s.Serve(s.lis); !errors.Is(err, http.ErrServerClosed) {
+	s.mu.Lock()
+	s.served = make(chan error, 1)
+	lis := s.lis
+	s.mu.Unlock()
+	go s.serve(lis)
+	if err := <-s.served; !errors.Is(err, http.ErrServerClosed) {
 		return err
 	}
 	return nil
 }
 
-// Stop stop the HTTP server.
+// serve serves the listener, and reports the error unless the listener is retired by Migrate.
+func (s *Server) serve(lis net.Listener) {
+	var err error
+	if s.tlsConf != nil {
+		err = s.ServeTLS(lis, "", "")
+	} else {
+		err = s.Serve(lis)
+	}
+	s.mu.RLock()
+	_, retired := s.retired[lis]
+	s.mu.RUnlock()
+	if !retired {
+		s.served <- err
+	}
+}
+
+// Migrate starts serving on the new address before closing the listener of the old one,
+// so the server moves to the new address without refusing the connections, e.g. driven
+// by a config reload. The connections accepted by the old listener are served until they
+// are closed. The endpoint is updated as well unless it is set by the Endpoint option,
+// use the Reregister of the app to update the registry.
+func (s *Server) Migrate(address string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.served == nil {
+		return fmt.Errorf("[HTTP] server is not started")
+	}
+	lis, err := net.Listen(s.network, address)
+	if err != nil {
+		return err
+	}
+	endpoint := s.endpoint
+	if !s.advertised {
+		addr, err := host.Extract(address, lis)
+		if err != nil {
+			lis.Close()
+			return err
+		}
+		endpoint = &url.URL{Scheme: s.endpoint.Scheme, Host: addr}
+	}
+	old := s.lis
+	if s.retired == nil {
+		s.retired = make(map[net.Listener]struct{})
+	}
+	s.retired[old] = struct{}{}
+	s.lis, s.address, s.endpoint = lis, address, endpoint
+	go s.serve(lis)
+	s.log.Infof("[HTTP] server migrating from %s to %s", old.Addr().String(), lis.Addr().String())
+	return old.Close()
+}
+
+// Drain disables the keep-alives without stopping the server,
+// so the clients reconnect to other instances after the existing requests finish.
+func (s *Server) Drain(ctx context.Context) error {
+	s.SetKeepAlivesEnabled(false)
+	s.log.Info("[HTTP] server draining")
+	return nil
+}
+
+// Stop stop the HTTP server, which waits for the in-flight requests to finish.
+// The hijacked connections such as websockets are not waited for, the handlers
+// should return once the request context is done, which is cancelled on stop.
 func (s *Server) Stop(ctx context.Context) error {
-	s.log.Info("[HTTP] server stopping")
-	return s.Shutdown(context.Background())
+	if ctx.Err() != nil {
+		// the app cancels the context to stop the servers
+		ctx = context.Background()
+	}
+	if s.lameDuck > 0 {
+		atomic.StoreInt32(&s.lameDucking, 1)
+		s.SetKeepAlivesEnabled(false)
+		s.log.Infof("[HTTP] server entering lame duck mode for %s", s.lameDuck)
+		timer := time.NewTimer(s.lameDuck)
+		select {
+		case <-ctx.Done():
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+	s.log.Infof("[HTTP] server stopping, in-flight requests: %d", atomic.LoadInt32(&s.inflight))
+	if s.shutdownTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.shutdownTimeout)
+		defer cancel()
+	}
+	err := s.Shutdown(ctx)
+	if err == nil {
+		s.log.Info("[HTTP] server drained")
+		return nil
+	}
+	s.log.Warnf("[HTTP] server shutdown: %v, remaining requests: %d", err, atomic.LoadInt32(&s.inflight))
+	if s.forceClose {
+		return s.Close()
+	}
+	return err
 }
@@ -6,10 +6,13 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"testing"
 	"time"
 
 	"github.com/go-kratos/kratos/v2/internal/host"
+	"github.com/go-kratos/kratos/v2/transport"
 )
 
 type testKey struct{}
@@ -103,3 +106,224 @@ func testClient(t *testing.T, srv *Server) {
 	}
 
 }
+
+func TestServerCancel(t *testing.T) {
+	cancelled := make(chan struct{})
+	srv := NewServer(Timeout(0))
+	srv.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+			close(cancelled)
+		case <-time.After(5 * time.Second):
+		}
+	})
+	if _, err := srv.Endpoint(); err != nil {
+		t.Fatal(err)
+	}
+	go srv.Start(context.Background())
+	defer srv.Stop(context.Background())
+
+	port, ok := host.Port(srv.lis)
+	if !ok {
+		t.Fatalf("extract port error: %v", srv.lis)
+	}
+	client, err := NewClient(context.Background(), WithEndpoint(fmt.Sprintf("127.0.0.1:%d", port)), WithTimeout(5*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := client.Invoke(ctx, "/slow", nil, nil, Method("GET")); err == nil {
+		t.Fatal("expected an error of the cancelled request")
+	}
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("expected the handler observes the cancellation")
+	}
+}
+
+func TestServerShutdown(t *testing.T) {
+	srv := NewServer(Timeout(0), ShutdownTimeout(time.Second))
+	srv.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(300 * time.Millisecond)
+		w.WriteHeader(http.StatusAccepted)
+	})
+	if _, err := srv.Endpoint(); err != nil {
+		t.Fatal(err)
+	}
+	go srv.Start(context.Background())
+	time.Sleep(100 * time.Millisecond)
+
+	port, ok := host.Port(srv.lis)
+	if !ok {
+		t.Fatalf("extract port error: %v", srv.lis)
+	}
+	done := make(chan int, 1)
+	go func() {
+		resp, err := http.Get(fmt.Sprintf("http://127.0.0.1:%d/slow", port))
+		if err != nil {
+			done <- 0
+			return
+		}
+		resp.Body.Close()
+		done <- resp.StatusCode
+	}()
+	time.Sleep(100 * time.Millisecond)
+	if err := srv.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if code := <-done; code != http.StatusAccepted {
+		t.Fatalf("expected the in-flight request finished got %d", code)
+	}
+}
+
+func TestServerLameDuck(t *testing.T) {
+	srv := NewServer(LameDuckDuration(300 * time.Millisecond))
+	srv.HandleFunc("/index", func(w http.ResponseWriter, r *http.Request) {})
+	if _, err := srv.Endpoint(); err != nil {
+		t.Fatal(err)
+	}
+	go srv.Start(context.Background())
+	time.Sleep(100 * time.Millisecond)
+
+	port, ok := host.Port(srv.lis)
+	if !ok {
+		t.Fatalf("extract port error: %v", srv.lis)
+	}
+	go srv.Stop(context.Background())
+	time.Sleep(100 * time.Millisecond)
+	resp, err := http.Get(fmt.Sprintf("http://127.0.0.1:%d/index", port))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 in the lame duck mode got %d", resp.StatusCode)
+	}
+}
+
+func TestServerLameDuckDeadline(t *testing.T) {
+	srv := NewServer(Address("127.0.0.1:0"), LameDuckDuration(time.Minute))
+	if _, err := srv.Endpoint(); err != nil {
+		t.Fatal(err)
+	}
+	go srv.Start(context.Background())
+	time.Sleep(100 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	srv.Stop(ctx)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected the lame duck bounded by the deadline got %s", elapsed)
+	}
+}
+
+func TestServerMigrate(t *testing.T) {
+	srv := NewServer(Address("127.0.0.1:0"))
+	srv.HandleFunc("/index", func(w http.ResponseWriter, r *http.Request) {})
+	if _, err := srv.Endpoint(); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- srv.Start(context.Background())
+	}()
+	time.Sleep(100 * time.Millisecond)
+
+	old := srv.lis.Addr().String()
+	if err := srv.Migrate("127.0.0.1:0"); err != nil {
+		t.Fatal(err)
+	}
+	e, err := srv.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Host == old {
+		t.Fatalf("expected the endpoint migrated from %s", old)
+	}
+	resp, err := http.Get("http://" + e.Host + "/index")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if _, err := http.Get("http://" + old + "/index"); err == nil {
+		t.Fatal("expected the old listener closed")
+	}
+	if err := srv.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("expected the server stopped without error got %v", err)
+	}
+}
+
+func TestServerMigrateAdvertised(t *testing.T) {
+	advertised := &url.URL{Scheme: "http", Host: "10.0.0.1:8000"}
+	srv := NewServer(Address("127.0.0.1:0"), Endpoint(advertised))
+	if _, err := srv.Endpoint(); err != nil {
+		t.Fatal(err)
+	}
+	go srv.Start(context.Background())
+	defer srv.Stop(context.Background())
+	time.Sleep(100 * time.Millisecond)
+	if err := srv.Migrate("127.0.0.1:0"); err != nil {
+		t.Fatal(err)
+	}
+	if e, _ := srv.Endpoint(); e.String() != advertised.String() {
+		t.Fatalf("expected the advertised endpoint kept after the migration got %s", e)
+	}
+}
+
+func TestServerExtendDeadline(t *testing.T) {
+	srv := NewServer(Timeout(20 * time.Millisecond))
+	srv.ctx = context.Background()
+	var errs []error
+	srv.HandleFunc("/poll", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if !transport.ExtendDeadline(ctx, time.Second) {
+			t.Error("expected the server timeout extended")
+		}
+		time.Sleep(50 * time.Millisecond)
+		errs = append(errs, ctx.Err())
+		// removes the server timeout
+		transport.ExtendDeadline(ctx, 0)
+		if _, ok := ctx.Deadline(); ok {
+			t.Error("expected no deadline")
+		}
+	})
+	srv.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/poll", nil))
+	if len(errs) != 1 || errs[0] != nil {
+		t.Fatalf("expected the extended deadline got %v", errs)
+	}
+}
+
+func TestServerDrain(t *testing.T) {
+	srv := NewServer(Address("127.0.0.1:0"))
+	srv.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {})
+	if _, err := srv.Endpoint(); err != nil {
+		t.Fatal(err)
+	}
+	go srv.Start(context.Background())
+	defer srv.Stop(context.Background())
+	time.Sleep(100 * time.Millisecond)
+
+	get := func() *http.Response {
+		res, err := http.Get("http://" + srv.lis.Addr().String() + "/")
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+		return res
+	}
+	if get().Close {
+		t.Fatal("expected the keep-alive before the drain")
+	}
+	if err := srv.Drain(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !get().Close {
+		t.Fatal("expected the connection closed after the drain")
+	}
+}
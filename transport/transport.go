@@ -2,7 +2,9 @@ package transport
 
 import (
 	"context"
+	"errors"
 	"net/url"
+	"time"
 
 	// init encoding
 	_ "github.com/go-kratos/kratos/v2/encoding/json"
@@ -22,10 +24,47 @@ type Endpointer interface {
 	Endpoint() (*url.URL, error)
 }
 
+// Drainer is a server which stops taking new requests and lets the existing ones
+// finish without stopping, e.g. reports NOT_SERVING to the health checks.
+type Drainer interface {
+	Drain(context.Context) error
+}
+
+// Describer is a server which describes itself, e.g. in the startup summary of the app.
+type Describer interface {
+	Describe() Description
+}
+
+// Description is the summary of a server.
+type Description struct {
+	Kind     Kind
+	Endpoint string
+	// Operations are the registered operations, e.g. the full methods of gRPC
+	// or the methods and the path templates of HTTP.
+	Operations []string
+	// Middlewares are the names of the server middlewares, see middleware.Name.
+	Middlewares []string
+	// Warnings are the findings of the self-check, e.g. the reflection is enabled.
+	Warnings []string
+}
+
 // Transport is transport context value.
 type Transport struct {
 	Kind     Kind
 	Endpoint string
+	// Method is the HTTP method of the request, it is empty for gRPC.
+	Method string
+}
+
+// Idempotent reports whether the request is idempotent by the HTTP method,
+// e.g. GET, PUT and DELETE, so the retry middleware can retry it safely.
+// The gRPC requests are treated as not idempotent like the POST requests.
+func (tr Transport) Idempotent() bool {
+	switch tr.Method {
+	case "GET", "HEAD", "OPTIONS", "TRACE", "PUT", "DELETE":
+		return true
+	}
+	return false
 }
 
 // Kind defines the type of Transport
@@ -33,8 +72,9 @@ type Kind string
 
 // Defines a set of transport kind
 const (
-	KindGRPC Kind = "gRPC"
-	KindHTTP Kind = "HTTP"
+	KindGRPC   Kind = "gRPC"
+	KindHTTP   Kind = "HTTP"
+	KindBroker Kind = "broker"
 )
 
 type transportKey struct{}
@@ -49,3 +89,164 @@ func FromContext(ctx context.Context) (tr Transport, ok bool) {
 	tr, ok = ctx.Value(transportKey{}).(Transport)
 	return
 }
+
+type operationKey struct{}
+
+// NewOperationContext returns a new Context that carries the func which resolves the
+// operation of the server request by the context of the handler.
+func NewOperationContext(ctx context.Context, fn func(context.Context) string) context.Context {
+	return context.WithValue(ctx, operationKey{}, fn)
+}
+
+// Operation returns the operation of the server request in ctx, which is the full method
+// of gRPC, the path template of the matched route of HTTP, e.g. /v1/users/{id}, and the
+// path if no route is matched, or the topic of the broker message. It returns empty if
+// there is no server request in ctx.
+func Operation(ctx context.Context) string {
+	if fn, ok := ctx.Value(operationKey{}).(func(context.Context) string); ok {
+		return fn(ctx)
+	}
+	return ""
+}
+
+// HeaderCarrier is the request header of the server transport, which is the header of HTTP,
+// the incoming metadata of gRPC or the headers of the broker message, the keys are case-insensitive.
+type HeaderCarrier interface {
+	// Get returns the first value of the key, or empty if there is no such key.
+	Get(key string) string
+	// Values returns all the values of the key.
+	Values(key string) []string
+	// Keys returns the keys of the header in lower case.
+	Keys() []string
+}
+
+type requestHeaderKey struct{}
+
+// NewRequestHeaderContext returns a new Context that carries the func which resolves the
+// request header of the server request by the context of the handler.
+func NewRequestHeaderContext(ctx context.Context, fn func(context.Context) HeaderCarrier) context.Context {
+	return context.WithValue(ctx, requestHeaderKey{}, fn)
+}
+
+// RequestHeaderCarrier returns the request header of the server request in ctx, if any.
+func RequestHeaderCarrier(ctx context.Context) (HeaderCarrier, bool) {
+	if fn, ok := ctx.Value(requestHeaderKey{}).(func(context.Context) HeaderCarrier); ok {
+		return fn(ctx), true
+	}
+	return nil, false
+}
+
+// RequestHeader returns the first value of the case-insensitive key in the request header
+// of the server request in ctx. It returns empty if there is no server request in ctx.
+func RequestHeader(ctx context.Context, key string) string {
+	if h, ok := RequestHeaderCarrier(ctx); ok {
+		return h.Get(key)
+	}
+	return ""
+}
+
+// ErrNoHeader is returned when there is no response header in the context.
+var ErrNoHeader = errors.New("transport: no response header in context")
+
+// Header is the response header of the server transport,
+// which is translated to the header and trailer of HTTP or the metadata of gRPC.
+type Header interface {
+	SetHeader(key, value string) error
+	SetTrailer(key, value string) error
+}
+
+type headerKey struct{}
+
+// NewHeaderContext returns a new Context that carries the response header.
+func NewHeaderContext(ctx context.Context, h Header) context.Context {
+	return context.WithValue(ctx, headerKey{}, h)
+}
+
+// SetHeader sets the response header of the server transport in ctx, which is the header
+// of HTTP or the header metadata of gRPC, so the handlers set the reply metadata without
+// depending on the transport.
+func SetHeader(ctx context.Context, key, value string) error {
+	if h, ok := ctx.Value(headerKey{}).(Header); ok {
+		return h.SetHeader(key, value)
+	}
+	return ErrNoHeader
+}
+
+// SetTrailer sets the response trailer of the server transport in ctx, which is the
+// trailer of HTTP or the trailer metadata of gRPC.
+func SetTrailer(ctx context.Context, key, value string) error {
+	if h, ok := ctx.Value(headerKey{}).(Header); ok {
+		return h.SetTrailer(key, value)
+	}
+	return ErrNoHeader
+}
+
+// SetReplyHeader sets the reply metadata of the server transport in ctx, which is the
+// response header of HTTP or the header metadata of gRPC, the gRPC values are collected
+// by grpc.SetHeader and sent with the reply. It is the same as SetHeader.
+func SetReplyHeader(ctx context.Context, key, value string) error {
+	return SetHeader(ctx, key, value)
+}
+
+type startKey struct{}
+
+// NewStartContext returns a new Context that carries the start time of the request.
+func NewStartContext(ctx context.Context, start time.Time) context.Context {
+	return context.WithValue(ctx, startKey{}, start)
+}
+
+// StartFromContext returns the start time of the request stored in ctx, if any.
+func StartFromContext(ctx context.Context) (start time.Time, ok bool) {
+	start, ok = ctx.Value(startKey{}).(time.Time)
+	return
+}
+
+// Elapsed returns the time elapsed since the start of the request in ctx,
+// it returns zero if there is no start time in the context.
+func Elapsed(ctx context.Context) time.Duration {
+	if start, ok := StartFromContext(ctx); ok {
+		return time.Since(start)
+	}
+	return 0
+}
+
+// RemainingDeadline returns the time remaining until the deadline of ctx, which is
+// negative if the deadline has passed, it returns false if there is no deadline.
+// The handlers can shed the requests which can not finish in time.
+func RemainingDeadline(ctx context.Context) (time.Duration, bool) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return 0, false
+	}
+	return time.Until(deadline), true
+}
+
+// DeadlineExtender extends the deadline of the server timeout in the context.
+type DeadlineExtender interface {
+	// Extend replaces the deadline, the zero deadline removes it, and reports
+	// false if the context is already done. It never exceeds the deadline of the client.
+	Extend(deadline time.Time) bool
+}
+
+type extenderKey struct{}
+
+// NewDeadlineContext returns a new Context that carries the extender of the server timeout.
+func NewDeadlineContext(ctx context.Context, e DeadlineExtender) context.Context {
+	return context.WithValue(ctx, extenderKey{}, e)
+}
+
+// ExtendDeadline extends the deadline of the server timeout to d from now, e.g. by the
+// long-poll handlers which hold the requests intentionally, or removes the timeout if d
+// is not positive. The deadline of the client is never exceeded, and the effective deadline
+// is the Deadline of ctx. It reports false if there is no server timeout in ctx.
+func ExtendDeadline(ctx context.Context, d time.Duration) bool {
+	e, ok := ctx.Value(extenderKey{}).(DeadlineExtender)
+	if !ok {
+		return false
+	}
+	var deadline time.Time
+	if d > 0 {
+		deadline = time.Now().Add(d)
+	}
+	return e.Extend(deadline)
+}
@@ -0,0 +1,33 @@
+package transport
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestElapsed(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name string
+		ctx  context.Context
+		min  time.Duration
+		max  time.Duration
+	}{
+		{"no start", context.Background(), 0, 0},
+		{"just started", NewStartContext(context.Background(), now), 0, time.Second},
+		{"started before", NewStartContext(context.Background(), now.Add(-time.Minute)), time.Minute, time.Minute + time.Second},
+		{"restarted", NewStartContext(NewStartContext(context.Background(), now.Add(-time.Minute)), now), 0, time.Second},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			start, ok := StartFromContext(test.ctx)
+			if ok == start.IsZero() {
+				t.Fatalf("unexpected start %v %v", start, ok)
+			}
+			if d := Elapsed(test.ctx); d < test.min || d > test.max {
+				t.Fatalf("expected between %s and %s got %s", test.min, test.max, d)
+			}
+		})
+	}
+}